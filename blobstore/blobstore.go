@@ -0,0 +1,52 @@
+// Package blobstore keeps large contract deliverables off the ledger.
+//
+// The chaincode only records the SHA-256 digest, the size and the URI of a
+// blob. The bytes themselves live in a Store, which can be swapped out for
+// another backend without touching the ledger records.
+package blobstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when a URI does not resolve to a stored blob.
+var ErrNotFound = errors.New("blobstore: blob not found")
+
+// Ref is what gets written to the ledger for a stored blob.
+type Ref struct {
+	Digest string // hex encoded SHA-256 of the content
+	Size   int64
+	URI    string
+}
+
+// Store is implemented by every blob backend.
+type Store interface {
+	// Put stores the content of r and returns its reference.
+	Put(r io.Reader) (Ref, error)
+	// Get opens the blob behind uri for reading.
+	Get(uri string) (io.ReadCloser, error)
+	// Delete removes the blob behind uri.
+	Delete(uri string) error
+}
+
+// Digest reads r to the end and returns its hex encoded SHA-256 and size.
+func Digest(r io.Reader) (string, int64, error) {
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// Verify reports whether the content of r matches ref.
+func Verify(r io.Reader, ref Ref) (bool, error) {
+	digest, size, err := Digest(r)
+	if err != nil {
+		return false, err
+	}
+	return digest == ref.Digest && size == ref.Size, nil
+}
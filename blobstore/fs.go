@@ -0,0 +1,97 @@
+package blobstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileScheme prefixes the URIs handed out by FileStore.
+const FileScheme = "fs://sha256/"
+
+// FileStore is a content addressed Store on the local filesystem.
+// Blobs are stored as <Root>/<first two hex digits>/<digest>.
+type FileStore struct {
+	Root string
+}
+
+// NewFileStore creates root if needed and returns a store on top of it.
+func NewFileStore(root string) (*FileStore, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	return &FileStore{Root: root}, nil
+}
+
+// Put streams r into a temporary file while hashing it and then moves the
+// file to its content address. Storing the same content twice is a no-op.
+func (s *FileStore) Put(r io.Reader) (Ref, error) {
+	tmp, err := os.CreateTemp(s.Root, "upload-")
+	if err != nil {
+		return Ref{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return Ref{}, err
+	}
+
+	digest := hex.EncodeToString(h.Sum(nil))
+	path := s.path(digest)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return Ref{}, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return Ref{}, err
+	}
+	return Ref{Digest: digest, Size: n, URI: FileScheme + digest}, nil
+}
+
+// Get opens the blob behind uri.
+func (s *FileStore) Get(uri string) (io.ReadCloser, error) {
+	digest, err := s.digest(uri)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(s.path(digest))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete removes the blob behind uri.
+func (s *FileStore) Delete(uri string) error {
+	digest, err := s.digest(uri)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(s.path(digest))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *FileStore) digest(uri string) (string, error) {
+	digest := strings.TrimPrefix(uri, FileScheme)
+	if digest == uri || len(digest) != sha256.Size*2 {
+		return "", ErrNotFound
+	}
+	if _, err := hex.DecodeString(digest); err != nil {
+		return "", ErrNotFound
+	}
+	return digest, nil
+}
+
+func (s *FileStore) path(digest string) string {
+	return filepath.Join(s.Root, digest[:2], digest)
+}
@@ -0,0 +1,125 @@
+package blobstore
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// sha256 of "hello"
+const helloDigest = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func newStore(t *testing.T) *FileStore {
+	t.Helper()
+	s, err := NewFileStore(t.TempDir() + "/blobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func read(t *testing.T, s Store, uri string) string {
+	t.Helper()
+	rc, err := s.Get(uri)
+	if err != nil {
+		t.Fatalf("Get(%s) error = %v", uri, err)
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestPutGet(t *testing.T) {
+	s := newStore(t)
+
+	ref, err := s.Put(strings.NewReader("hello"))
+	want := Ref{Digest: helloDigest, Size: 5, URI: FileScheme + helloDigest}
+	if err != nil || ref != want {
+		t.Fatalf("Put() = %+v, %v, want %+v", ref, err, want)
+	}
+	if got := read(t, s, ref.URI); got != "hello" {
+		t.Errorf("Get() = %q, want hello", got)
+	}
+
+	// The same content is stored once, at the same address
+	again, err := s.Put(strings.NewReader("hello"))
+	if err != nil || again != ref {
+		t.Errorf("Put() again = %+v, %v, want %+v", again, err, ref)
+	}
+	if _, err := os.Stat(s.Root + "/2c/" + helloDigest); err != nil {
+		t.Errorf("blob not at its content address: %v", err)
+	}
+	// Nothing is left of the uploads
+	if tmp, _ := os.ReadDir(s.Root); len(tmp) != 1 {
+		t.Errorf("root holds %d entries, want 1", len(tmp))
+	}
+
+	if err := s.Delete(ref.URI); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Get(ref.URI); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete error = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(ref.URI); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() again error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGetBadURI(t *testing.T) {
+	s := newStore(t)
+	for _, uri := range []string{
+		"",
+		helloDigest,                     // no scheme
+		"s3://sha256/" + helloDigest,    // another scheme
+		FileScheme + helloDigest[:62],   // short
+		FileScheme + "../../etc/passwd", // not a digest
+		FileScheme + strings.Repeat("zz", 32),
+	} {
+		if _, err := s.Get(uri); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q) error = %v, want ErrNotFound", uri, err)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	s := newStore(t)
+	ref, err := s.Put(strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		ref     Ref
+		want    bool
+	}{
+		{"match", "hello", ref, true},
+		{"other content", "hellO", ref, false},
+		{"other size", "hello", Ref{Digest: ref.Digest, Size: 6}, false},
+		{"other digest", "hello", Ref{Digest: strings.Repeat("0", 64), Size: 5}, false},
+	}
+	for _, tt := range tests {
+		if ok, err := Verify(strings.NewReader(tt.content), tt.ref); ok != tt.want || err != nil {
+			t.Errorf("%s: Verify() = %v, %v, want %v", tt.name, ok, err, tt.want)
+		}
+	}
+
+	// A blob changed on disk no longer matches its reference
+	if err := os.WriteFile(s.Root+"/2c/"+helloDigest, []byte("jello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rc, err := s.Get(ref.URI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	if ok, err := Verify(rc, ref); ok || err != nil {
+		t.Errorf("Verify(tampered) = %v, %v, want false", ok, err)
+	}
+}
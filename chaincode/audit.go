@@ -0,0 +1,226 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/events"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// Contract audit trail
+// ContractAuditTable - keys ContractId, Seq - holds one AuditEntry per change of a contract
+// AuditSeqTable - key ContractId - holds the Seq of the last entry of the trail so an
+// append does not read the trail. Seq is numbered from 1 and padded to 4 digits in the
+// key like the offers of NegotiationTable, AuditEntry.Seq is not padded
+// Entries are appended by EmitEvent from the event of every invoke whose payload names a
+// ContractId: creation, bids and offers, awards, payments, status changes, deliverables,
+// reviews, disputes and bonds. A cycle spawned by closing a recurring contract starts
+// its trail with that ContractClosed event, see NextContractId
+// Caller is the SHA-256 fingerprint of the enrollment certificate that submitted the
+// transaction, empty when the network runs without security
+// GetContractAudit returns the trail of a contract, oldest first, with the optional
+// page size and continuation token of GetPage
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetContractAudit", "Args": ["1000"]}'
+///////////////////////////////////////////////////////////////////////////////////////
+
+type AuditEntry struct {
+	ContractId string
+	RecType    string // AUDIT
+	Seq        string // Position in the trail, from 1
+	Event      string // Name of the event of the change, see package events
+	Function   string // Invoke that made the change
+	Actor      string // UserID of the user who invoked the change
+	Caller     string // Fingerprint of the certificate of the submitter
+	TxID       string
+	Time       string
+	Change     json.RawMessage // Payload of the event
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////
+// Append the event buff to the trails of the contracts it names
+/////////////////////////////////////////////////////////////////////////////////////////////
+
+func AppendAudit(stub shim.ChaincodeStubInterface, buff []byte) error {
+
+	var env events.Envelope
+	err := json.Unmarshal(buff, &env)
+	if err != nil {
+		return errcode.Wrapf(err, "AppendAudit(): Cannot read event : %s", err)
+	}
+
+	var named struct {
+		ContractId     string
+		NextContractId string
+	}
+	// Payloads that are not objects name no contract
+	json.Unmarshal(env.Payload, &named)
+	if named.ContractId == "" {
+		return nil
+	}
+
+	entry := AuditEntry{
+		RecType: "AUDIT",
+		Event:   env.Name,
+		Actor:   env.Actor,
+		Caller:  CallerFingerprint(stub),
+		TxID:    env.TxID,
+		Time:    env.Time,
+		Change:  env.Payload,
+	}
+	entry.Function = TxFunction(stub)
+
+	for _, contractId := range []string{named.ContractId, named.NextContractId} {
+		if contractId == "" {
+			continue
+		}
+
+		seq, err := NextAuditSeq(stub, contractId)
+		if err != nil {
+			return err
+		}
+
+		entry.ContractId = contractId
+		entry.Seq = strconv.Itoa(seq)
+		abuff, err := AuditEntrytoJSON(entry)
+		if err != nil {
+			return errcode.Wrapf(err, "AppendAudit(): Cannot create object buffer for contract %s : %s", contractId, err)
+		}
+
+		err = UpdateLedger(stub, "ContractAuditTable", []string{contractId, fmt.Sprintf("%04d", seq)}, abuff)
+		if err != nil {
+			Log(stub).Error("AppendAudit(): write error while inserting record", "contractId", contractId)
+			return err
+		}
+		Log(stub).Debug("AppendAudit(): Audit entry appended", "contractId", contractId, "seq", entry.Seq, "event", env.Name)
+	}
+	return nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////
+// Take the next Seq of the trail of a contract from its counter in AuditSeqTable
+// A trail written before the counter existed is counted once
+/////////////////////////////////////////////////////////////////////////////////////////////
+
+func NextAuditSeq(stub shim.ChaincodeStubInterface, contractId string) (int, error) {
+
+	keys := []string{contractId}
+	buff, found, err := LookupLedger(stub, "AuditSeqTable", keys)
+	if err != nil {
+		return 0, err
+	}
+
+	last := 0
+	if found {
+		last, err = strconv.Atoi(string(buff))
+		if err != nil {
+			return 0, errcode.Wrapf(err, "NextAuditSeq(): Bad counter of contract %s : %s", contractId, err)
+		}
+	} else {
+		rows, err := GetList(stub, "ContractAuditTable", keys)
+		if err != nil {
+			return 0, errcode.Wrapf(err, "NextAuditSeq() operation failed. Error GetList: %s", err)
+		}
+		last = len(rows)
+	}
+
+	seq := last + 1
+	if found {
+		err = ReplaceLedgerEntry(stub, "AuditSeqTable", keys, []byte(strconv.Itoa(seq)))
+	} else {
+		err = UpdateLedger(stub, "AuditSeqTable", keys, []byte(strconv.Itoa(seq)))
+	}
+	if err != nil {
+		Log(stub).Error("NextAuditSeq(): write error in AuditSeqTable", "contractId", contractId)
+		return 0, err
+	}
+	return seq, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////
+// Get the audit trail of a contract
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetContractAudit", "Args": ["1000", "20", "<nextToken>"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////
+
+func GetContractAudit(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) < 1 {
+		Log(stub).Debug("GetContractAudit(): Incorrect number of arguments. Expecting 1")
+		return nil, errcode.New(errcode.ArgCount, "GetContractAudit(): Incorrect number of arguments. Expecting 1 ")
+	}
+
+	pageSize, token, err := PageArgs(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = GetContractObject(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	rows, next, err := GetPage(stub, "ContractAuditTable", args[:1], pageSize, token)
+	if err != nil {
+		return nil, errcode.Wrapf(err, "GetContractAudit() operation failed. Error GetPage: %s", err)
+	}
+
+	nCol := GetNumberOfKeys("ContractAuditTable")
+
+	tlist := make([]AuditEntry, len(rows))
+	for i := 0; i < len(rows); i++ {
+		entry, err := JSONtoAuditEntry(rows[i].Columns[nCol].GetBytes())
+		if err != nil {
+			Log(stub).Error("GetContractAudit(): Unmarshal error", "error", err)
+			return nil, errcode.Wrapf(err, "GetContractAudit() operation failed. %s", err)
+		}
+		tlist[i] = entry
+	}
+
+	return PagetoJSON(tlist, next)
+}
+
+//////////////////////////////////////////////////////////
+// SHA-256 of the certificate of the submitter, empty
+// without security
+//////////////////////////////////////////////////////////
+func CallerFingerprint(stub shim.ChaincodeStubInterface) string {
+
+	cert, err := stub.GetCallerCertificate()
+	if err != nil || len(cert) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(cert)
+	return hex.EncodeToString(sum[:])
+}
+
+//////////////////////////////////////////////////////////
+// Converts an AuditEntry to a JSON String
+//////////////////////////////////////////////////////////
+func AuditEntrytoJSON(entry AuditEntry) ([]byte, error) {
+
+	ajson, err := json.Marshal(entry)
+	if err != nil {
+		logger.Error("AuditEntrytoJSON(): error", "error", err)
+		return nil, err
+	}
+	return ajson, nil
+}
+
+//////////////////////////////////////////////////////////
+// Converts a JSON String to an AuditEntry
+//////////////////////////////////////////////////////////
+func JSONtoAuditEntry(aentry []byte) (AuditEntry, error) {
+
+	entry := AuditEntry{}
+	err := json.Unmarshal(aentry, &entry)
+	if err != nil {
+		logger.Error("JSONtoAuditEntry(): error", "error", err)
+		return entry, err
+	}
+	return entry, err
+}
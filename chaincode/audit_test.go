@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/AkshayKulkarni03/hackathon/events"
+)
+
+func TestAppendAudit(t *testing.T) {
+	stub := newMemStub()
+	stub.initLedger(t)
+	contract := ContractObject{ContractId: "1000", RecType: "CREATECONTR", Type: "IT", Amount: "5000", CreationDate: "2016-10-18 10:00:00",
+		UserID: "100", Status: "OPEN"}
+	if _, err := InsertRecord(stub, "CONTRACT", contract); err != nil {
+		t.Fatal(err)
+	}
+
+	// A trail written before AuditSeqTable existed, without a counter
+	for _, seq := range []string{"1", "2"} {
+		buff, _ := AuditEntrytoJSON(AuditEntry{ContractId: "1000", RecType: "AUDIT", Seq: seq, Event: events.ContractPosted})
+		if err := UpdateLedger(stub, "ContractAuditTable", []string{"1000", seq}, buff); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := EmitEvent(stub, events.ContractUpdated, "100", events.ContractEvent{ContractId: "1000"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, key := range []string{"0003", "0004"} {
+		if _, found, err := LookupLedger(stub, "ContractAuditTable", []string{"1000", key}); err != nil || !found {
+			t.Errorf("entry %s = %v, %v", key, found, err)
+		}
+	}
+	if buff, _, _ := LookupLedger(stub, "AuditSeqTable", []string{"1000"}); string(buff) != "4" {
+		t.Errorf("counter = %q, want 4", buff)
+	}
+
+	buff, err := GetContractAudit(stub, "GetContractAudit", []string{"1000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var page struct{ Items []AuditEntry }
+	if err := json.Unmarshal(buff, &page); err != nil {
+		t.Fatal(err)
+	}
+	var seqs []string
+	for _, e := range page.Items {
+		seqs = append(seqs, e.Seq)
+	}
+	if len(seqs) != 4 || seqs[2] != "3" || seqs[3] != "4" {
+		t.Errorf("trail Seq = %v, want [1 2 3 4]", seqs)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/events"
+	"github.com/AkshayKulkarni03/hackathon/model"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// Batch
+// Runs a list of invokes in one transaction, all or nothing: if an operation fails the
+// Batch returns its error, qualified with the operation, and the transaction applies
+// none of them. The result is the list of the results of the operations, in order
+// Limits are model.MaxBatchOps operations and model.MaxBatchBytes of JSON
+// Operations cannot be batches and carry no request ID, set one on the Batch itself
+// The events of the operations are set as one BatchApplied event, see event.go
+//./peer chaincode invoke -l golang -n mycc -c '{"Function": "Batch", "Args":["BATCH", "[{\"function\":\"PostUser\",\"args\":[\"100\",\"USER\",\"Ashley Hart\",\"TR\",\"One Market Street\",\"9161234567\",\"ahart@example.com\",\"Bank of America\",\"0001234\"]},{\"function\":\"PostRequest\",\"args\":[\"1000\",\"5000\",\"30\",\"Fixed price\",\"IT\",\"Responsive web site\",\"Build a web shop\",\"Net 30\",\"2016-10-18 10:00:00\",\"100\",\"CREATECONTR\"]}]"]}'
+///////////////////////////////////////////////////////////////////////////////////////
+
+func Batch(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	ops, err := model.CreateBatch(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check every operation before running any
+	for i, op := range ops {
+		if InvokeFunction(op.Function) == nil {
+			return nil, errcode.New(errcode.UnknownFunction, "Batch(): Invalid function call in operation " + strconv.Itoa(i) + " : " + op.Function).WithField("operations[" + strconv.Itoa(i) + "].function")
+		}
+		if ChkReqType(op.Args) == false {
+			return nil, errcode.New(errcode.InvalidRecType, "Batch(): Invalid recType in operation " + strconv.Itoa(i)).WithField("operations[" + strconv.Itoa(i) + "].args")
+		}
+	}
+
+	txID := stub.GetTxID()
+	pending := &[]events.Envelope{}
+	SetBatchEvents(txID, pending)
+	defer SetBatchEvents(txID, nil)
+
+	results := make([]model.BatchResult, len(ops))
+	for i, op := range ops {
+		Log(stub).Debug("Batch(): Running operation", "op", i, "opFunction", op.Function)
+
+		buff, err := InvokeFunction(op.Function)(stub, op.Function, op.Args)
+		if err != nil {
+			Log(stub).Debug("Batch(): Operation failed", "op", i, "opFunction", op.Function, "error", err)
+			return nil, model.BatchError(i, op.Function, err)
+		}
+
+		results[i] = model.BatchResult{Function: op.Function, Result: BatchResultJSON(buff)}
+	}
+
+	// Set the collected events as the one event of the transaction
+	SetBatchEvents(txID, nil)
+	err = EmitEvent(stub, events.BatchApplied, "", events.BatchEvent{Events: *pending})
+	if err != nil {
+		return nil, err
+	}
+
+	Log(stub).Info("Batch(): Batch applied", "operations", len(ops), "events", len(*pending))
+	return json.Marshal(results)
+}
+
+//////////////////////////////////////////////////////////
+// The result of an operation as JSON, a JSON string if
+// the function did not return JSON
+//////////////////////////////////////////////////////////
+func BatchResultJSON(buff []byte) json.RawMessage {
+
+	if len(buff) == 0 {
+		return json.RawMessage("null")
+	}
+	// json.Valid is not in the Go of the chaincode container
+	if json.Unmarshal(buff, new(json.RawMessage)) == nil {
+		return json.RawMessage(buff)
+	}
+	ajson, _ := json.Marshal(string(buff))
+	return ajson
+}
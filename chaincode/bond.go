@@ -0,0 +1,405 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/events"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// A Bond is a performance bond offered by an Insurance user (UserType IN) on a contract
+// The owner or the worker accepts one offer and becomes the insured party
+// If a dispute is ruled against the insured party the insurer pays out a claim
+// to the other party, scaled by how much of the dispute was lost
+//
+// Status : OFFERED -> ACTIVE -> CLAIMED or RELEASED
+///////////////////////////////////////////////////////////////////////////////////////
+
+type Bond struct {
+	ContractId  string
+	RecType     string // BOND
+	InsurerID   string
+	InsuredID   string // Owner or worker who accepted the bond
+	Premium     string // Paid by the insured to the insurer on acceptance
+	Coverage    string // Most the insurer pays out on a claim
+	Conditions  string
+	Status      string
+	OfferDate   string
+	AcceptDate  string
+	ClaimAmount string
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// An insurer offers a Bond on an OPEN or IN_PROGRESS contract
+// Example
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "OfferBond", "Args":["1000", "BOND", "400", "50", "1000", "Void if the scope changes"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func OfferBond(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	bond, err := CreateBond(args[0:])
+	if err != nil {
+		return nil, err
+	}
+
+	userBytes, err := ValidateMember(stub, bond.InsurerID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := JSONtoUser(userBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.UserType != "IN" {
+		Log(stub).Debug("OfferBond(): Bonds can only be offered by Insurance users", "userId", bond.InsurerID)
+		return nil, errcode.New(errcode.NotAllowed, "OfferBond(): Bonds can only be offered by Insurance users : " + bond.InsurerID)
+	}
+
+	contract, err := GetContractObject(stub, bond.ContractId)
+	if err != nil {
+		return nil, err
+	}
+
+	if contract.Status != "OPEN" && contract.Status != "IN_PROGRESS" {
+		Log(stub).Debug("OfferBond(): Contract is not OPEN or IN_PROGRESS", "contractId", bond.ContractId)
+		return nil, errcode.New(errcode.InvalidState, "OfferBond(): Contract is not OPEN or IN_PROGRESS : " + bond.ContractId)
+	}
+
+	if bond.InsurerID == contract.UserID || bond.InsurerID == contract.WorkerID {
+		return nil, errcode.New(errcode.NotAllowed, "OfferBond(): Insurer cannot be a party of the contract : " + bond.InsurerID)
+	}
+
+	buff, err := BondtoJSON(bond)
+	if err != nil {
+		Log(stub).Error("OfferBond(): Failed Cannot create object buffer for write", "contractId", bond.ContractId)
+		return nil, errcode.New(errcode.Internal, "OfferBond(): Failed Cannot create object buffer for write : " + bond.ContractId)
+	}
+
+	err = UpdateLedger(stub, "BondTable", []string{bond.ContractId, bond.InsurerID}, buff)
+	if err != nil {
+		Log(stub).Error("OfferBond(): write error while inserting record")
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.BondOffered, bond.InsurerID, BondEventOf(bond))
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+func CreateBond(args []string) (Bond, error) {
+
+	var bond Bond
+
+	// Check there are 6 Arguments - the rest is computed
+	if len(args) != 6 {
+		logger.Debug("CreateBond(): Incorrect number of arguments. Expecting 6")
+		return bond, errcode.New(errcode.ArgCount, "CreateBond(): Incorrect number of arguments. Expecting 6 ")
+	}
+
+	_, err := strconv.Atoi(args[0])
+	if err != nil {
+		return bond, errcode.New(errcode.InvalidArgument, "CreateBond(): Contract ID should be an integer").WithField("ContractId")
+	}
+
+	if args[1] != "BOND" {
+		return bond, errcode.New(errcode.InvalidRecType, "CreateBond(): RecType should be BOND : " + args[1]).WithField("RecType")
+	}
+
+	premium, err := strconv.Atoi(args[3])
+	if err != nil || premium < 0 {
+		return bond, errcode.New(errcode.InvalidArgument, "CreateBond(): Premium should be a positive integer").WithField("Premium")
+	}
+
+	coverage, err := strconv.Atoi(args[4])
+	if err != nil || coverage <= 0 {
+		return bond, errcode.New(errcode.InvalidArgument, "CreateBond(): Coverage should be a positive integer").WithField("Coverage")
+	}
+
+	bond = Bond{ContractId: args[0], RecType: args[1], InsurerID: args[2], Premium: args[3], Coverage: args[4],
+		Conditions: args[5], Status: "OFFERED", OfferDate: time.Now().Format("2006-01-02 15:04:05")}
+	logger.Debug("CreateBond(): Bond Object", "record", bond)
+
+	return bond, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// The owner or the worker accepts a Bond offer and pays the premium
+// Only one Bond can be active on a contract, which must still be OPEN or IN_PROGRESS
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "AcceptBond", "Args":["1000", "BOND", "400", "200"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func AcceptBond(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 4 {
+		Log(stub).Debug("AcceptBond(): Incorrect number of arguments. Expecting 4")
+		return nil, errcode.New(errcode.ArgCount, "AcceptBond(): Incorrect number of arguments. Expecting 4 ")
+	}
+
+	if args[2] == "" {
+		return nil, errcode.New(errcode.InvalidArgument, "AcceptBond(): Insurer ID is required").WithField("InsurerID")
+	}
+	if args[3] == "" {
+		return nil, errcode.New(errcode.InvalidArgument, "AcceptBond(): User ID is required").WithField("InsuredID")
+	}
+
+	_, err := ValidateMember(stub, args[3])
+	if err != nil {
+		Log(stub).Debug("AcceptBond(): Failed User not registered on the block-chain", "userId", args[3])
+		return nil, err
+	}
+
+	contract, err := GetContractObject(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if contract.Status != "OPEN" && contract.Status != "IN_PROGRESS" {
+		Log(stub).Debug("AcceptBond(): Contract is not OPEN or IN_PROGRESS", "contractId", args[0])
+		return nil, errcode.New(errcode.InvalidState, "AcceptBond(): Contract is not OPEN or IN_PROGRESS : " + args[0])
+	}
+
+	if args[3] != contract.UserID && args[3] != contract.WorkerID {
+		Log(stub).Debug("AcceptBond(): User is not a party of the contract", "userId", args[3])
+		return nil, errcode.New(errcode.NotAllowed, "AcceptBond(): User is not a party of the contract : " + args[3])
+	}
+
+	active, err := GetActiveBond(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		Log(stub).Debug("AcceptBond(): Contract already has an active Bond from", "userId", active.InsurerID)
+		return nil, errcode.New(errcode.InvalidState, "AcceptBond(): Contract already has an active Bond from : " + active.InsurerID)
+	}
+
+	bond, err := GetBondObject(stub, args[0], args[2])
+	if err != nil {
+		return nil, err
+	}
+
+	if bond.Status != "OFFERED" {
+		return nil, errcode.New(errcode.InvalidState, "AcceptBond(): Bond is not on offer : " + bond.Status)
+	}
+
+	bond.InsuredID = args[3]
+	bond.Status = "ACTIVE"
+	bond.AcceptDate = time.Now().Format("2006-01-02 15:04:05")
+
+	premium, _ := strconv.Atoi(bond.Premium)
+	if premium > 0 {
+		_, err = PostSettlement(stub, contract, "PREMIUM", bond.InsurerID, premium)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	buff, err := ReplaceBond(stub, bond)
+	if err != nil {
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.BondAccepted, bond.InsuredID, BondEventOf(bond))
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Settle the active Bond of a contract after its dispute has been ruled
+// workerShare is the percentage of the price the worker was awarded
+// A claim is paid out by the insurer when the ruling went against the insured
+// otherwise the bond is released
+////////////////////////////////////////////////////////////////////////////
+func SettleBond(stub shim.ChaincodeStubInterface, contract ContractObject, workerShare int) error {
+
+	bond, err := GetActiveBond(stub, contract.ContractId)
+	if err != nil || bond == nil {
+		return err
+	}
+
+	// Share of the dispute lost by the insured party and who gets compensated
+	lost, beneficiary := 100-workerShare, contract.UserID
+	if bond.InsuredID == contract.UserID {
+		lost, beneficiary = workerShare, contract.WorkerID
+	}
+
+	coverage, _ := strconv.Atoi(bond.Coverage)
+	claim := coverage * lost / 100
+
+	if claim > 0 {
+		_, err = PostSettlement(stub, contract, "CLAIM", beneficiary, claim)
+		if err != nil {
+			return err
+		}
+		bond.Status = "CLAIMED"
+		bond.ClaimAmount = strconv.Itoa(claim)
+	} else {
+		bond.Status = "RELEASED"
+	}
+
+	_, err = ReplaceBond(stub, *bond)
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Release the active Bond of a contract that closed without a dispute
+////////////////////////////////////////////////////////////////////////////
+func ReleaseBond(stub shim.ChaincodeStubInterface, contractId string) error {
+
+	bond, err := GetActiveBond(stub, contractId)
+	if err != nil || bond == nil {
+		return err
+	}
+
+	bond.Status = "RELEASED"
+	_, err = ReplaceBond(stub, *bond)
+	return err
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////
+// Get all Bonds offered on a Contract
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetBonds", "Args": ["1000"]}'
+// Optional page size and continuation token follow, see GetPage
+/////////////////////////////////////////////////////////////////////////////////////////
+func GetBonds(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) < 1 {
+		Log(stub).Debug("GetBonds(): Incorrect number of arguments. Expecting 1")
+		return nil, errcode.New(errcode.ArgCount, "GetBonds(): Incorrect number of arguments. Expecting 1 ")
+	}
+
+	pageSize, token, err := PageArgs(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, next, err := GetPage(stub, "BondTable", args[:1], pageSize, token)
+	if err != nil {
+		return nil, errcode.Wrapf(err, "GetBonds() operation failed. Error GetPage: %s", err)
+	}
+
+	bonds, err := RowstoBonds(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return PagetoJSON(bonds, next)
+}
+
+func GetBondList(stub shim.ChaincodeStubInterface, contractId string) ([]Bond, error) {
+
+	rows, err := GetList(stub, "BondTable", []string{contractId})
+	if err != nil {
+		return nil, errcode.Wrapf(err, "GetBondList() operation failed. Error GetList: %s", err)
+	}
+
+	return RowstoBonds(rows)
+}
+
+func RowstoBonds(rows []shim.Row) ([]Bond, error) {
+
+	nCol := GetNumberOfKeys("BondTable")
+
+	tlist := make([]Bond, len(rows))
+	for i := 0; i < len(rows); i++ {
+		ts := rows[i].Columns[nCol].GetBytes()
+		bond, err := JSONtoBond(ts)
+		if err != nil {
+			logger.Error("RowstoBonds(): Unmarshal error", "error", err)
+			return nil, errcode.Wrapf(err, "RowstoBonds() operation failed. %s", err)
+		}
+		tlist[i] = bond
+	}
+
+	return tlist, nil
+}
+
+//////////////////////////////////////////////////////////
+// Returns the Bond that was accepted on a contract
+// ACTIVE, CLAIMED or RELEASED - nil if there is none
+//////////////////////////////////////////////////////////
+func GetAcceptedBond(stub shim.ChaincodeStubInterface, contractId string) (*Bond, error) {
+
+	bonds, err := GetBondList(stub, contractId)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range bonds {
+		if bonds[i].Status != "OFFERED" {
+			return &bonds[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func GetActiveBond(stub shim.ChaincodeStubInterface, contractId string) (*Bond, error) {
+
+	bond, err := GetAcceptedBond(stub, contractId)
+	if err != nil || bond == nil || bond.Status != "ACTIVE" {
+		return nil, err
+	}
+	return bond, nil
+}
+
+func GetBondObject(stub shim.ChaincodeStubInterface, contractId string, insurerId string) (Bond, error) {
+
+	Avalbytes, err := QueryLedger(stub, "BondTable", []string{contractId, insurerId})
+	if err != nil {
+		Log(stub).Debug("GetBondObject(): Cannot find Bond record", "contractId", contractId, "userId", insurerId)
+		return Bond{}, errcode.New(errcode.NotFound, "GetBondObject(): Cannot find Bond record : " + contractId + " " + insurerId).WithField("InsurerID")
+	}
+
+	return JSONtoBond(Avalbytes)
+}
+
+func ReplaceBond(stub shim.ChaincodeStubInterface, bond Bond) ([]byte, error) {
+
+	buff, err := BondtoJSON(bond)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ReplaceLedgerEntry(stub, "BondTable", []string{bond.ContractId, bond.InsurerID}, buff)
+	if err != nil {
+		Log(stub).Error("ReplaceBond(): write error while replacing record")
+		return nil, err
+	}
+	return buff, nil
+}
+
+//////////////////////////////////////////////////////////
+// Converts a Bond to a JSON String
+//////////////////////////////////////////////////////////
+func BondtoJSON(bond Bond) ([]byte, error) {
+
+	ajson, err := json.Marshal(bond)
+	if err != nil {
+		logger.Error("BondtoJSON(): error", "error", err)
+		return nil, err
+	}
+	return ajson, nil
+}
+
+//////////////////////////////////////////////////////////
+// Converts a JSON String to a Bond
+//////////////////////////////////////////////////////////
+func JSONtoBond(areq []byte) (Bond, error) {
+
+	bond := Bond{}
+	err := json.Unmarshal(areq, &bond)
+	if err != nil {
+		logger.Error("JSONtoBond(): error", "error", err)
+		return bond, err
+	}
+	return bond, err
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+)
+
+func TestCreateBond(t *testing.T) {
+	if _, err := CreateBond([]string{"1000", "BOND", "400", "50", "1000", ""}); err != nil {
+		t.Errorf("CreateBond() error = %v", err)
+	}
+	_, err := CreateBond([]string{"1000", "BID", "400", "50", "1000", ""})
+	if e := errcode.From(err); err == nil || e.Code != errcode.InvalidRecType {
+		t.Errorf("CreateBond() with RecType BID error = %v, want %s", err, errcode.InvalidRecType)
+	}
+}
+
+func TestAcceptBond(t *testing.T) {
+	for _, tt := range []struct {
+		status string
+		want   errcode.Code
+	}{
+		{"IN_PROGRESS", ""},
+		{"CLOSED", errcode.InvalidState},
+		{"RESOLVED", errcode.InvalidState},
+	} {
+		stub := newMemStub()
+		stub.initLedger(t)
+		for _, u := range []UserObject{{UserID: "100", RecType: "USER", UserType: "TR"}, {UserID: "400", RecType: "USER", UserType: "IN"}} {
+			if _, err := InsertRecord(stub, "USER", u); err != nil {
+				t.Fatal(err)
+			}
+		}
+		contract := ContractObject{ContractId: "1000", RecType: "CREATECONTR", Type: "IT", Amount: "5000", CreationDate: "2016-10-18 10:00:00",
+			UserID: "100", WorkerID: "200", BidPrice: "4000", Status: "IN_PROGRESS"}
+		if _, err := InsertRecord(stub, "CONTRACT", contract); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := OfferBond(stub, "OfferBond", []string{"1000", "BOND", "400", "50", "1000", ""}); err != nil {
+			t.Fatal(err)
+		}
+
+		// The contract moves on before the offer is accepted
+		contract.Status = tt.status
+		if _, err := UpdateContractStatus(stub, contract); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := AcceptBond(stub, "AcceptBond", []string{"1000", "BOND", "400", "100"})
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("%s: AcceptBond() error = %v", tt.status, err)
+			}
+			continue
+		}
+		if e := errcode.From(err); err == nil || e.Code != tt.want {
+			t.Errorf("%s: AcceptBond() error = %v, want %s", tt.status, err, tt.want)
+		}
+		if bond, _ := GetActiveBond(stub, "1000"); bond != nil {
+			t.Errorf("%s: bond of %s is active", tt.status, bond.InsurerID)
+		}
+	}
+}
@@ -29,32 +29,28 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/events"
+	"github.com/AkshayKulkarni03/hackathon/logging"
+	"github.com/AkshayKulkarni03/hackathon/model"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
-	//"github.com/op/go-logging"
-	"image"
-	"image/gif"
-	"image/jpeg"
-	"image/png"
 	"io"
-	"net/http"
 	"os"
 	"os/exec"
-	"runtime"
 	"strconv"
 	"strings"
 	"time"
 	// "github.com/errorpkg"
 )
 
-var recType = []string{"USER", "CREATECONTR", "BID", "POSTTRAN", "CLOSECONTRACT", "CANCELCONTRACT"}
+var recType = []string{"USER", "CREATECONTR", "BID", "POSTTRAN", "CLOSECONTRACT", "CANCELCONTRACT", "DELIVERABLE", "REVIEW", "DISPUTE", "BOND", "TEMPLATE", "OFFER", "SKILL", "BATCH", "IMPORT"}
 
 //////////////////////////////////////////////////////////////////////////////////////////////////
 // The following array holds the list of tables that should be created
 // The deploy/init deletes the tables and recreates them every time a deploy is invoked
 //////////////////////////////////////////////////////////////////////////////////////////////////
-var aucTables = []string{"UserTable", "UserCatTable", "ContractTable", "ContractCatTable", "ContractOpenTable", "BidTable", "BidCatTable",  "BidHistoryTable", "TransTable"}
+var aucTables = []string{"UserTable", "UserCatTable", "ContractTable", "ContractCatTable", "ContractOpenTable", "BidTable", "BidCatTable",  "BidHistoryTable", "TransTable", "DeliverableTable", "ReviewTable", "DisputeTable", "BondTable", "TemplateTable", "SeriesTable", "NegotiationTable", "SubcontractTable", "SkillTable", "UserSkillTable", "SkillUserTable", "ContractStatusTable", "ContractOwnerTable", "ContractPeriodTable", "StatsTable", "RequestTable", "ContractAuditTable", "AuditSeqTable"}
 
 ///////////////////////////////////////////////////////////////////////////////////////
 // This creates a record of the Asset (Inventory)
@@ -64,19 +60,16 @@ var aucTables = []string{"UserTable", "UserCatTable", "ContractTable", "Contract
 // Item { 113869, "Flower Urn on a Patio", "Liz Jardine", "10102007", "Original", "Floral", "Acrylic", "15 x 15 in", "sample_9.png","$600", "My Gallery }
 ///////////////////////////////////////////////////////////////////////////////////////
 
-type ContractObject struct {
-	ContractId             string
-	Amount                 string
-	Duration               string
-	BusinessRule           string
-	Type                   string
-	RequirementDescription string
-	Description            string
-	Terms                  string
-	CreationDate           string
-	UserID				   string
-	Status                 string //This will have three values OPEN/CLOSED/IN_PROGRESS
-	RecType                string
+type ContractObject model.ContractObject
+
+/////////////////////////////////////////////////////////////
+// ContractView is what GetContract returns - the contract
+// together with the Bond covering it
+/////////////////////////////////////////////////////////////
+
+type ContractView struct {
+	ContractObject
+	Bond *Bond `json:",omitempty"`
 }
 
 /////////////////////////////////////////////////////////////
@@ -88,31 +81,13 @@ type ContractObject struct {
 // BK (bank)
 // SH (Shipper)
 /////////////////////////////////////////////////////////////
-type UserObject struct {
-	UserID    string
-	RecType   string // Type = USER
-	Name      string
-	UserType  string // Auction House (AH), Bank (BK), Buyer or Seller (TR), Shipper (SH), Appraiser (AP)
-	Address   string
-	Phone     string
-	Email     string
-	Bank      string
-	AccountNo string
-	Rating    string
-}
+type UserObject model.UserObject
 
 ////////////////////////////////////////////////////////////////
 //  This is a Bid. Bids are accepted only if an auction is OPEN
 ////////////////////////////////////////////////////////////////
 
-type Bid struct {
-	ContractId string
-	RecType    string // BID
-	BidNo      string
-	UserID     string // ID Of Buyer - to be verified against the Item CurrentOwnerId
-	BidPrice   string // BidPrice
-	BidTime    string // Time the bid was received
-}
+type Bid model.Bid
 
 /////////////////////////////////////////////////////////////
 // POST the transaction after the Auction Completes
@@ -124,28 +99,37 @@ type Bid struct {
 // This transaction is a simple view
 /////////////////////////////////////////////////////////////
 
-type ItemTransaction struct {
-	ConractId         string
-	RecType           string // POSTTRAN
-	TransactionId     string
-	TransType         string // Sale, Buy, Commission
-	UserId            string // Buyer or Seller ID
-	TransDate         string // Date of Settlement (Buyer or Seller)
-	TransactionAmount string // Time of hammer strike - SOLD
-	BidNo      		  string
-}
+type ItemTransaction model.ItemTransaction
 
 func GetNumberOfKeys(tname string) int {
 	TableMap := map[string]int{
 		"UserTable":        1,
 		"ContractTable":    1,
 		"UserCatTable":     2,
-		"ContractCatTable": 2,
+		"ContractCatTable": 3,
 		"ContractOpenTable":2,
-		"BidTable":     	1,
+		"BidTable":     	2,
 		"BidCatTable":     	2,
 		"BidHistoryTable":  3,
 		"TransTable":       2,
+		"DeliverableTable": 2,
+		"ReviewTable":      3,
+		"DisputeTable":     1,
+		"BondTable":        2,
+		"TemplateTable":    2,
+		"SeriesTable":      2,
+		"NegotiationTable": 3,
+		"SubcontractTable": 2,
+		"SkillTable":       1,
+		"UserSkillTable":   2,
+		"SkillUserTable":   2,
+		"ContractStatusTable": 2,
+		"ContractOwnerTable":  2,
+		"ContractPeriodTable": 3,
+		"StatsTable":          2,
+		"RequestTable":        1,
+		"ContractAuditTable":  2,
+		"AuditSeqTable":       1,
 	}
 	return TableMap[tname]
 }
@@ -161,27 +145,33 @@ var ccPath string
 // SimpleChaincode - Init Chaincode implementation - The following sequence of transactions can be used to test the Chaincode
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
-func (t *SimpleChaincode) Init(stub *shim.ChaincodeStub, function string, args []string) ([]byte, error) {
+func (t *SimpleChaincode) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 
 	// TODO - Include all initialization to be complete before Invoke and Query
 	// Uses aucTables to delete tables if they exist and re-create them
 
-	//myLogger.Info("[Trade and Auction Application] Init")
-	fmt.Println("[Create Contract and R] Init")
+	defer BeginTx(stub, "Init")()
+	Log(stub).Info("Init(): Creating tables")
 	var err error
 
+	// Deploy with logLevel=DEBUG to change the level, see log.go
+	err = SetLogLevel(args)
+	if err != nil {
+		return nil, errcode.Encode(err)
+	}
+
 	for _, val := range aucTables {
 		err = stub.DeleteTable(val)
 		if err != nil {
-			return nil, fmt.Errorf("Init(): DeleteTable of %s  Failed ", val)
+			return nil, errcode.Encode(errcode.Errorf(errcode.Internal, "Init(): DeleteTable of %s  Failed ", val))
 		}
 		err = InitLedger(stub, val)
 		if err != nil {
-			return nil, fmt.Errorf("Init(): InitLedger of %s  Failed ", val)
+			return nil, errcode.Encode(errcode.Errorf(errcode.Internal, "Init(): InitLedger of %s  Failed ", val))
 		}
 	}
 
-	fmt.Println("Init() Initialization Complete  : ", args)
+	Log(stub).Info("Init(): Initialization Complete", "level", logger.Level())
 	return []byte("Init(): Initialization Complete"), nil
 }
 
@@ -191,33 +181,75 @@ func (t *SimpleChaincode) Init(stub *shim.ChaincodeStub, function string, args [
 // during an invoke
 //
 //////////////////////////////////////////////////////////////
-func InvokeFunction(fname string) func(stub *shim.ChaincodeStub, function string, args []string) ([]byte, error) {
-	InvokeFunc := map[string]func(stub *shim.ChaincodeStub, function string, args []string) ([]byte, error){
-		"PostRequest":     PostRequest,
-		"SelectBidder":    SelectBidder,
-		"PostTransaction": PostTransaction,
-		"PostBid":         PostBid,
-		"CloseConract":    CloseContract,
-	}
-	return InvokeFunction[fname]
+func InvokeFunction(fname string) func(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	InvokeFunc := map[string]func(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error){
+		"PostUser":                PostUser,
+		"PostRequest":             PostRequest,
+		"SelectBidder":            SelectBidder,
+		"PostTransaction":         PostTransaction,
+		"PostBid":                 PostBid,
+		"CloseContract":           CloseContract,
+		"PostDeliverable":         PostDeliverable,
+		"PostReview":              PostReview,
+		"OpenDispute":             OpenDispute,
+		"AddDisputeEvidence":      AddDisputeEvidence,
+		"AssignArbitrator":        AssignArbitrator,
+		"RuleDispute":             RuleDispute,
+		"OfferBond":               OfferBond,
+		"AcceptBond":              AcceptBond,
+		"PostTemplate":            PostTemplate,
+		"PostRequestFromTemplate": PostRequestFromTemplate,
+		"SetRecurrence":           SetRecurrence,
+		"ReopenCycle":             ReopenCycle,
+		"CounterOffer":            CounterOffer,
+		"AcceptOffer":             AcceptOffer,
+		"RejectOffer":             RejectOffer,
+		"CoSignBid":               CoSignBid,
+		"PostSubcontract":         PostSubcontract,
+		"CancelContract":          CancelContract,
+		"PostSkill":               PostSkill,
+		"DeclareSkill":            DeclareSkill,
+		"EndorseSkill":            EndorseSkill,
+		"SetContractSkills":       SetContractSkills,
+		"MigrateContractKeys":     MigrateContractKeys,
+		"RebuildStats":            RebuildStats,
+		"Batch":                   Batch,
+		"ImportRows":              ImportRows,
+	}
+	return InvokeFunc[fname]
 }
 
 //////////////////////////////////////////////////////////////
 // Query Functions based on Function name
 //
 //////////////////////////////////////////////////////////////
-func QueryFunction(fname string) func(stub *shim.ChaincodeStub, function string, args []string) ([]byte, error) {
-	QueryFunc := map[string]func(stub *shim.ChaincodeStub, function string, args []string) ([]byte, error){
-		"GetUser":         GetUser,
-		"GetUserContract": GetUserContract,
-		"GetBidders":      GetBidders,
-		"ViewContracts":   ViewContracts,
-		"GetUser":         GetUser,
-		"GetUserBidds":    GetUserBidds,
-		"GetContract":     GetContract,
-		"GetVersion":      GetVersion,
-	}
-	return QueryFunction[fname]
+func QueryFunction(fname string) func(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	QueryFunc := map[string]func(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error){
+		"GetUser":                GetUser,
+		"ViewContracts":          ViewContracts,
+		"GetContract":            GetContract,
+		"GetVersion":             GetVersion,
+		"GetDeliverables":        GetDeliverables,
+		"VerifyDeliverable":      VerifyDeliverable,
+		"GetUserReviews":         GetUserReviews,
+		"GetDispute":             GetDispute,
+		"GetBonds":               GetBonds,
+		"GetTemplate":            GetTemplate,
+		"VerifyContractTemplate": VerifyContractTemplate,
+		"GetContractSeries":      GetContractSeries,
+		"GetNegotiation":         GetNegotiation,
+		"GetContractTree":        GetContractTree,
+		"RecommendContracts":     RecommendContracts,
+		"RecommendBidders":       RecommendBidders,
+		"GetListOfBids":          GetListOfBids,
+		"GetListOfOpenContracts": GetListOfOpenContracts,
+		"GetUserListByCat":       GetUserListByCat,
+		"ListContracts":          ListContracts,
+		"GetMarketStats":         GetMarketStats,
+		"ExportTables":           ExportTables,
+		"GetContractAudit":       GetContractAudit,
+	}
+	return QueryFunc[fname]
 }
 
 ////////////////////////////////////////////////////////////////
@@ -233,10 +265,17 @@ func QueryFunction(fname string) func(stub *shim.ChaincodeStub, function string,
 // - The CloseAuction creates a transaction and invokes PostTransaction
 ////////////////////////////////////////////////////////////////
 
-func (t *SimpleChaincode) Invoke(stub *shim.ChaincodeStub, function string, args []string) ([]byte, error) {
+func (t *SimpleChaincode) Invoke(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 	var err error
 	var buff []byte
 
+	// An optional ?requestId=<id> after the function name makes the invoke safe to retry, see request.go
+	var requestId string
+	requestId, function = model.SplitRequestID(function)
+
+	defer BeginTx(stub, function)()
+	Log(stub).Debug("Invoke()", "nargs", len(args))
+
 	//////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 	// Check Type of Transaction and apply business rules
 	// before adding record to the block chain
@@ -249,15 +288,33 @@ func (t *SimpleChaincode) Invoke(stub *shim.ChaincodeStub, function string, args
 	if ChkReqType(args) == true {
 
 		InvokeRequest := InvokeFunction(function)
-		if InvokeRequest != nil {
-			buff, err = InvokeRequest(stub, function, args)
+		if InvokeRequest == nil {
+			err = errcode.New(errcode.UnknownFunction, "Invoke() : Invalid function call : " + function)
+			LogResult(stub, "Invoke()", logging.Info, err)
+			return nil, errcode.Encode(err)
+		}
+		if requestId != "" {
+			var found bool
+			buff, found, err = ReplayRequest(stub, requestId, function, args)
+			if found || err != nil {
+				LogResult(stub, "Invoke()", logging.Info, err)
+				return buff, errcode.Encode(err)
+			}
+		}
+
+		buff, err = InvokeRequest(stub, function, args)
+		if err == nil && requestId != "" {
+			err = RecordRequest(stub, requestId, function, args, buff)
 		}
 	} else {
-		fmt.Println("Invoke() Invalid recType : ", args, "\n")
-		return nil, errors.New("Invoke() : Invalid recType : " + args[0])
+		err = errcode.New(errcode.InvalidRecType, "Invoke() : Invalid recType : " + args[0])
+		LogResult(stub, "Invoke()", logging.Info, err)
+		return nil, errcode.Encode(err)
 	}
 
-	return buff, err
+	// Errors reach the client as the JSON of errcode.Error
+	LogResult(stub, "Invoke()", logging.Info, err)
+	return buff, errcode.Encode(err)
 }
 
 //////////////////////////////////////////////////////////////////////////////////////////
@@ -268,52 +325,46 @@ func (t *SimpleChaincode) Invoke(stub *shim.ChaincodeStub, function string, args
 // ./peer chaincode query -l golang -n mycc -c '{"Function": "GetItem", "Args": ["2000"]}'
 //////////////////////////////////////////////////////////////////////////////////////////
 
-func (t *SimpleChaincode) Query(stub *shim.ChaincodeStub, function string, args []string) ([]byte, error) {
+func (t *SimpleChaincode) Query(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 	var err error
 	var buff []byte
-	fmt.Println("ID Extracted and Type = ", args[0])
-	fmt.Println("Args supplied : ", args)
 
-	if len(args) < 1 {
-		fmt.Println("Query() : Include at least 1 arguments Key ")
-		return nil, errors.New("Query() : Expecting Transation type and Key value for query")
-	}
+	defer BeginTx(stub, function)()
+	Log(stub).Debug("Query()", "nargs", len(args))
 
+	// Every query checks its own arguments, GetMarketStats and the page
+	// arguments of GetListOfOpenContracts can be left out
 	QueryRequest := QueryFunction(function)
 	if QueryRequest != nil {
 		buff, err = QueryRequest(stub, function, args)
 	} else {
-		fmt.Println("Query() Invalid function call : ", function)
-		return nil, errors.New("Query() : Invalid function call : " + function)
+		err = errcode.New(errcode.UnknownFunction, "Query() : Invalid function call : " + function)
 	}
 
+	LogResult(stub, "Query()", logging.Debug, err)
 	if err != nil {
-		fmt.Println("Query() Object not found : ", args[0])
-		return nil, errors.New("Query() : Object not found : " + args[0])
+		return nil, errcode.Encode(err)
 	}
-	return buff, err
+	return buff, nil
 }
 
 func GetVersion(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 	if len(args) < 1 {
-		fmt.Println("GetVersion() : Requires 1 argument 'version'")
-		return nil, errors.New("GetVersion() : Requires 1 argument 'version'")
+		Log(stub).Debug("GetVersion(): Requires 1 argument 'version'")
+		return nil, errcode.New(errcode.ArgCount, "GetVersion() : Requires 1 argument 'version'")
 	}
 	// Get version from the ledger
-	version, err := stub.Get
-	State(args[0])
+	version, err := stub.GetState(args[0])
 	if err != nil {
-		jsonResp := "{\"Error\":\"Failed to get state for version\"}"
-		return nil, errors.New(jsonResp)
+		return nil, errcode.Wrapf(err, "GetVersion(): Failed to get state for version : %s", err)
 	}
 
 	if version == nil {
-		jsonResp := "{\"Error\":\" service application version is invalid\"}"
-		return nil, errors.New(jsonResp)
+		return nil, errcode.New(errcode.NotFound, "GetVersion(): Service application version is invalid : " + args[0])
 	}
 
 	jsonResp := "{\"version\":\"" + string(version) + "\"}"
-	fmt.Printf("Query Response:%s\n", jsonResp)
+	Log(stub).Debug("GetVersion(): Query Response", "version", jsonResp)
 	return version, nil
 }
 
@@ -325,23 +376,26 @@ func GetVersion(stub shim.ChaincodeStubInterface, function string, args []string
 //////////////////////////////////////////////////////////////////////////////////////////
 func GetUser(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 
+	if len(args) < 1 {
+		Log(stub).Debug("GetUser(): Incorrect number of arguments. Expecting 1")
+		return nil, errcode.New(errcode.ArgCount, "GetUser(): Incorrect number of arguments. Expecting 1 ")
+	}
+
 	var err error
 
 	// Get the Object and Display it
 	Avalbytes, err := QueryLedger(stub, "UserTable", args)
 	if err != nil {
-		fmt.Println("GetUser() : Failed to Query Object ")
-		jsonResp := "{\"Error\":\"Failed to get  Object Data for " + args[0] + "\"}"
-		return nil, errors.New(jsonResp)
+		Log(stub).Debug("GetUser(): Failed to Query Object")
+		return nil, errcode.Wrapf(err, "GetUser(): Cannot find User record : %s", args[0]).WithField("UserID")
 	}
 
 	if Avalbytes == nil {
-		fmt.Println("GetUser() : Incomplete Query Object ")
-		jsonResp := "{\"Error\":\"Incomplete information about the key for " + args[0] + "\"}"
-		return nil, errors.New(jsonResp)
+		Log(stub).Debug("GetUser(): Incomplete Query Object")
+		return nil, errcode.New(errcode.NotFound, "GetUser(): Incomplete information about the key for " + args[0]).WithField("UserID")
 	}
 
-	fmt.Println("GetUser() : Response : Successfull -")
+	Log(stub).Debug("GetUser(): Response : Successful")
 	return Avalbytes, nil
 }
 
@@ -352,30 +406,39 @@ func GetUser(stub shim.ChaincodeStubInterface, function string, args []string) (
 /////////////////////////////////////////////////////////////////////////////////////////
 func GetContract(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 
+	if len(args) < 1 {
+		Log(stub).Debug("GetContract(): Incorrect number of arguments. Expecting 1")
+		return nil, errcode.New(errcode.ArgCount, "GetContract(): Incorrect number of arguments. Expecting 1 ")
+	}
+
 	var err error
 
 	// Get the Objects and Display it
 	Avalbytes, err := QueryLedger(stub, "ContractTable", args)
 	if err != nil {
-		fmt.Println("GetContract() : Failed to Query Object ")
-		jsonResp := "{\"Error\":\"Failed to get  Object Data for " + args[0] + "\"}"
-		return nil, errors.New(jsonResp)
+		Log(stub).Debug("GetContract(): Failed to Query Object")
+		return nil, errcode.Wrapf(err, "GetContract(): Cannot find Contract record : %s", args[0]).WithField("ContractId")
 	}
 
 	if Avalbytes == nil {
-		fmt.Println("GetContract() : Incomplete Query Object ")
-		jsonResp := "{\"Error\":\"Incomplete information about the key for " + args[0] + "\"}"
-		return nil, errors.New(jsonResp)
+		Log(stub).Debug("GetContract(): Incomplete Query Object")
+		return nil, errcode.New(errcode.NotFound, "GetContract(): Incomplete information about the key for " + args[0]).WithField("ContractId")
 	}
 
-	fmt.Println("GetContract() : Response : Successfull ")
+	Log(stub).Debug("GetContract(): Response : Successful")
 
-	// Masking ItemImage binary data
-	itemObj, _ := JSONtoAR(Avalbytes)
-	itemObj.ItemImage = []byte{}
-	Avalbytes, _ = ARtoJSON(itemObj)
+	// Attach the status of the accepted Bond, if any
+	contract, err := JSONtoAR(Avalbytes)
+	if err != nil {
+		return nil, err
+	}
 
-	return Avalbytes, nil
+	bond, err := GetAcceptedBond(stub, contract.ContractId)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(ContractView{contract, bond})
 }
 
 ///////////////////////////////////////////////////////////////////////////////////////////////////
@@ -391,26 +454,23 @@ func GetBid(stub shim.ChaincodeStubInterface, function string, args []string) ([
 	// Check there are 2 Arguments provided as per the the struct - two are computed
 	// See example
 	if len(args) < 2 {
-		fmt.Println("GetBid(): Incorrect number of arguments. Expecting 2 ")
-		fmt.Println("GetBid(): ./peer chaincode query -l golang -n mycc -c '{\"Function\": \"GetBid\", \"Args\": [\"1111\",\"6\"]}'")
-		return nil, errors.New("GetBid(): Incorrect number of arguments. Expecting 2 ")
+		Log(stub).Debug("GetBid(): Incorrect number of arguments. Expecting 2")
+		return nil, errcode.New(errcode.ArgCount, "GetBid(): Incorrect number of arguments. Expecting 2 ")
 	}
 
 	// Get the Objects and Display it
 	Avalbytes, err := QueryLedger(stub, "BidTable", args)
 	if err != nil {
-		fmt.Println("GetBid() : Failed to Query Object ")
-		jsonResp := "{\"Error\":\"Failed to get  Object Data for " + args[0] + "\"}"
-		return nil, errors.New(jsonResp)
+		Log(stub).Debug("GetBid(): Failed to Query Object")
+		return nil, errcode.Wrapf(err, "GetBid(): Cannot find Bid record : %s", args[0]).WithField("BidNo")
 	}
 
 	if Avalbytes == nil {
-		fmt.Println("GetBid() : Incomplete Query Object ")
-		jsonResp := "{\"Error\":\"Incomplete information about the key for " + args[0] + "\"}"
-		return nil, errors.New(jsonResp)
+		Log(stub).Debug("GetBid(): Incomplete Query Object")
+		return nil, errcode.New(errcode.NotFound, "GetBid(): Incomplete information about the key for " + args[0]).WithField("BidNo")
 	}
 
-	fmt.Println("GetBid() : Response : Successfull -")
+	Log(stub).Debug("GetBid(): Response : Successful")
 	return Avalbytes, nil
 }
 
@@ -427,399 +487,447 @@ func GetTransaction(stub shim.ChaincodeStubInterface, function string, args []st
 	// Get the Objects and Display it
 	Avalbytes, err := QueryLedger(stub, "TransTable", args)
 	if Avalbytes == nil {
-		fmt.Println("GetTransaction() : Incomplete Query Object ")
-		jsonResp := "{\"Error\":\"Incomplete information about the key for " + args[0] + "\"}"
-		return nil, errors.New(jsonResp)
-	}
-
-	if err != nil {
-		fmt.Println("GetTransaction() : Failed to Query Object ")
-		jsonResp := "{\"Error\":\"Failed to get  Object Data for " + args[0] + "\"}"
-		return nil, errors.New(jsonResp)
+		Log(stub).Debug("GetTransaction(): Incomplete Query Object")
+		return nil, errcode.Wrapf(err, "GetTransaction(): Cannot find Transaction record : %s", args[0]).WithField("TransactionId")
 	}
 
-	fmt.Println("GetTransaction() : Response : Successfull")
+	Log(stub).Debug("GetTransaction(): Response : Successful")
 	return Avalbytes, nil
 }
 
 /////////////////////////////////////////////////////////////////////////////////////////////////////////////
-// Create a master Object of the Item
-// Since the Owner Changes hands, a record has to be written for each
-// Transaction with the updated Encryption Key of the new owner
+// Register a user
+// UserType is one of AH, TR, AP, IN, BK or SH
 // Example
-//./peer chaincode invoke -l golang -n mycc -c '{"Function": "PostItem", "Args":["1000", "ARTINV", "Shadows by Asppen", "Asppen Messer", "20140202", "Original", "Landscape" , "Canvas", "15 x 15 in", "sample_7.png","$600", "100"]}'
+//./peer chaincode invoke -l golang -n mycc -c '{"Function": "PostUser", "Args":["100", "USER", "Ashley Hart", "TR", "One Market Street", "9161234567", "ahart@example.com", "Bank of America", "0001234"]}'
 /////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
-func PostRequest(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+func PostUser(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 
-	contractObject, err := CreateContract(args[0:])
+	obj, err := model.CreateUser(args[0:])
 	if err != nil {
-		fmt.Println("PostRequest(): Cannot create item object \n")
+		Log(stub).Debug("PostUser(): Cannot create user object")
 		return nil, err
 	}
+	user := UserObject(obj)
 
-	// Check if the Owner ID specified is registered and valid
-	ownerInfo, err := ValidateMember(stub, ContractObject.UserID)
-	fmt.Println("Owner information  ", ownerInfo, contractObject.UserID)
+	buff, err := InsertRecord(stub, "USER", user)
 	if err != nil {
-		fmt.Println("PostRequest() : Failed Owner information not found for ", contractObject.UserID)
+		Log(stub).Error("PostUser(): write error while inserting record")
 		return nil, err
 	}
 
-	// Convert Item Object to JSON
-	buff, err := ARtoJSON(contractObject) //
+	err = EmitEvent(stub, events.UserRegistered, user.UserID, events.UserEvent{UserID: user.UserID, Name: user.Name, UserType: user.UserType})
 	if err != nil {
-		fmt.Println("PostRequest() : Failed Cannot create object buffer for write : ", args[1])
-		return nil, errors.New("PostRequest(): Failed Cannot create object buffer for write : " + args[1])
-	} else {
-		// Update the ledger with the Buffer Data
-		// err = stub.PutState(args[0], buff)
-		keys := []string{args[0]}
-		err = UpdateLedger(stub, "ContractTable", keys, buff)
-		if err != nil {
-			fmt.Println("PostRequest() : write error while inserting record\n")
-			return buff, err
-		}
-
-		// Put an entry into the Item History Table
-		_, err = PostItemLog(stub, contractObject, "INITIAL", "DEFAULT")
-		if err != nil {
-			fmt.Println("PostRequestLog() : write error while inserting record\n")
-			return nil, err
-		}
-
-		// Post Entry into ItemCatTable - i.e. Item Category Table
-		// The first key 2016 is a dummy (band aid) key to extract all values
-		keys = []string{"2016", args[4], args[0]}
-		err = UpdateLedger(stub, "ContractCatTable", keys, buff)
-		if err != nil {
-			fmt.Println("PostRequest() : Write error while inserting record into ContractCatTable \n")
-			return buff, err
-		}
-
+		return nil, err
 	}
-
-	secret_key, _ := json.Marshal(contractObject.ContractId)
-	fmt.Println(string(secret_key))
-	return secret_key, nil
+	return buff, nil
 }
 
-func CreateContract(args []string) (ContractObject, error) {
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Post a new contract, OPEN for bids
+// Example
+//./peer chaincode invoke -l golang -n mycc -c '{"Function": "PostRequest", "Args":["1000", "5000", "30", "Fixed price", "IT", "Responsive web site", "Build a web shop", "Net 30", "2016-10-18 10:00:00", "100", "CREATECONTR"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
-	var err error
-	var myItem ContractObject
+func PostRequest(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 
-	// Check there are 11 Arguments provided as per the the struct - two are computed
-	if len(args) != 11 {
-		fmt.Println("CreateContract(): Incorrect number of arguments. Expecting 11 ")
-		return myItem, errors.New("CreateContract(): Incorrect number of arguments. Expecting 11 ")
+	obj, err := model.CreateContract(args[0:])
+	if err != nil {
+		Log(stub).Debug("PostRequest(): Cannot create item object")
+		return nil, err
 	}
+	contractObject := ContractObject(obj)
 
-	// Validate ItemID is an integer
-
-	_, err = strconv.Atoi(args[0])
+	buff, err := PostContract(stub, contractObject)
 	if err != nil {
-		fmt.Println("CreateContract(): ART ID should be an integer create failed! ")
-		return myItem, errors.New("CreateContract(): contract ID should be an integer create failed!")
+		return nil, err
 	}
 
-	AES_key, _ := GenAESKey()
-
-	// Append the AES Key, The Encrypted Image Byte Array and the file type
-	myItem = ContractObject{AES_key, args[0], args[1], args[2], args[3], args[4], args[5], args[6], args[7], args[8], args[9], "OPEN", args[10]}
-
-	fmt.Println("CreateContract(): Item Object created: ID# ", myItem.ContractId, "\n AES Key: ", myItem.AES_Key)
-
-	// Code to Validate the Item Object)
-	// If User presents Crypto Key then key is used to validate the picture that is stored as part of the title
-	// TODO
-
-	return myItem, nil
-}
-
-///////////////////////////////////////////////////////////////////////////////////
-// Since the Owner Changes hands, a record has to be written for each
-// Transaction with the updated Encryption Key of the new owner
-// This function is internally invoked by PostTransaction and is not a Public API
-///////////////////////////////////////////////////////////////////////////////////
-
-func UpdateItemObject(stub shim.ChaincodeStubInterface, ar []byte, hammerPrice string, buyer string) ([]byte, error) {
-
-	var err error
-	myItem, err := JSONtoAR(ar)
+	err = EmitEvent(stub, events.ContractPosted, contractObject.UserID, ContractEventOf(contractObject))
 	if err != nil {
-		fmt.Println("U() : UpdateItemObject() : Failed to create Art Record Object from JSON ")
 		return nil, err
 	}
+	return buff, nil
+}
 
-	// Insert logic to  re-encrypt image by first fetching the current Key
-	CurrentAES_Key := myItem.AES_Key
-	// Decrypt Image and Save Image in a file
-	image := Decrypt(CurrentAES_Key, myItem.ItemImage)
-
-	// Get a New Key & Encrypt Image with New Key
-	myItem.AES_Key, _ = GenAESKey()
-	myItem.ItemImage = Encrypt(myItem.AES_Key, image)
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Write a newly created Contract to the ledger
+// Shared by PostRequest and PostRequestFromTemplate
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
-	// Update the owner to the Buyer and update price to auction hammer price
-	myItem.ItemBasePrice = hammerPrice
-	myItem.CurrentOwnerID = buyer
+func PostContract(stub shim.ChaincodeStubInterface, contractObject ContractObject) ([]byte, error) {
 
-	ar, err = ARtoJSON(myItem)
-	keys := []string{myItem.ItemID, myItem.CurrentOwnerID}
-	err = ReplaceLedgerEntry(stub, "ItemTable", keys, ar)
+	// Check if the Owner ID specified is registered and valid
+	_, err := ValidateMember(stub, contractObject.UserID)
 	if err != nil {
-		fmt.Println("UpdateItemObject() : Failed ReplaceLedgerEntry in ItemTable into Blockchain ")
+		Log(stub).Debug("PostContract(): Failed Owner information not found for", "userId", contractObject.UserID)
 		return nil, err
 	}
-	fmt.Println("UpdateItemObject() : ReplaceLedgerEntry in ItemTable successful ")
 
-	// Update entry in Item Category Table as it holds the Item object as wekk
-	keys = []string{"2016", myItem.ItemSubject, myItem.ItemID}
-	err = ReplaceLedgerEntry(stub, "ItemCatTable", keys, ar)
+	// Write ContractTable and its index tables, see Records
+	_, err = InsertRecord(stub, "CONTRACT", contractObject)
 	if err != nil {
-		fmt.Println("UpdateItemObject() : Failed ReplaceLedgerEntry in ItemCategoryTable into Blockchain ")
+		Log(stub).Error("PostContract(): write error while inserting record")
 		return nil, err
 	}
 
-	fmt.Println("UpdateItemObject() : ReplaceLedgerEntry in ItemCategoryTable successful ")
-	return myItem.AES_Key, nil
+	secret_key, _ := json.Marshal(contractObject.ContractId)
+	Log(stub).Info("PostContract(): Contract posted", "contractId", contractObject.ContractId, "userId", contractObject.UserID)
+	return secret_key, nil
 }
 
 //////////////////////////////////////////////////////////
 // Create an Item Transaction record to process Request
-// This is invoked by the CloseAuctionRequest
-//
+// Settles the selected bid of a contract
+// A team bid is paid out as one transaction per member according to the shares
+// Rows are keyed by TransactionId as the same TransType can be posted more than once
+//./peer chaincode invoke -l golang -n mycc -c '{"Function": "PostTransaction", "Args":["1000", "POSTTRAN", "5000", "PAYMENT", "200", "2016-10-01", "900", "1"]}'
 ////////////////////////////////////////////////////////////
 func PostTransaction(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 
 	if function != "PostTransaction" {
-		return nil, errors.New("PostTransaction(): Invalid function name. Expecting \"PostTransaction\"")
+		return nil, errcode.New(errcode.InvalidArgument, "PostTransaction(): Invalid function name. Expecting \"PostTransaction\"").WithField("function")
 	}
 
-	ar, err := CreateTransactionRequest(args[0:]) //
+	obj, err := model.CreateTransactionRequest(args[0:]) //
 	if err != nil {
 		return nil, err
 	}
+	ar := ItemTransaction(obj)
 
 	// Validate buyer's ID
-	buyer, err := ValidateMember(stub, ar.UserId)
+	_, err = ValidateMember(stub, ar.UserId)
 	if err != nil {
-		fmt.Println("PostTransaction() : Failed Buyer not Registered in Blockchain ", ar.UserId)
+		Log(stub).Debug("PostTransaction(): Failed Buyer not Registered in Blockchain", "userId", ar.UserId)
 		return nil, err
 	}
 
-	fmt.Println("PostTransaction(): Validated Buyer information successfully ", buyer, ar.UserId)
+	Log(stub).Debug("PostTransaction(): Validated Buyer information successfully", "userId", ar.UserId)
 
-	// Validate Item record
-	lastUpdatedItemOBCObject, err := ValidateItemSubmission(stub, ar.ItemID)
+	// Only the selected bid of a contract can be settled
+	contract, err := GetContractObject(stub, ar.ConractId)
 	if err != nil {
-		fmt.Println("PostTransaction() : Failed Could not Validate Item Object in Blockchain ", ar.ItemID)
-		return lastUpdatedItemOBCObject, err
-	}
-	fmt.Println("PostTransaction() : Validated Item Object in Blockchain successfully", ar.ItemID)
-
-	// Update Item Object with new Owner Key
-	newKey, err := UpdateItemObject(stub, lastUpdatedItemOBCObject, ar.HammerPrice, ar.UserId)
-	if err != nil {
-		fmt.Println("PostTransaction() : Failed to update Item Master Object in Blockchain ", ar.ItemID)
 		return nil, err
-	} else {
-		// Write New Key to file
-		fmt.Println("PostTransaction() : New encryption Key is  ", newKey)
 	}
-	fmt.Println("PostTransaction() : Updated Item Master Object in Blockchain successfully", ar.ItemID)
 
-	// Post an Item Log
-	itemObject, err := JSONtoAR(lastUpdatedItemOBCObject)
-	if err != nil {
-		fmt.Println("PostTransaction() : Conversion error JSON to ItemRecord\n")
-		return lastUpdatedItemOBCObject, err
+	if contract.Status != "IN_PROGRESS" {
+		Log(stub).Debug("PostTransaction(): Payments are only accepted on an IN_PROGRESS contract", "contractId", ar.ConractId)
+		return nil, errcode.New(errcode.InvalidState, "PostTransaction(): Payments are only accepted on an IN_PROGRESS contract : " + ar.ConractId)
 	}
 
-	// A life cycle event is added to say that the Item is no longer on auction
-	itemObject.ItemBasePrice = ar.HammerPrice
-	itemObject.CurrentOwnerID = ar.UserId
-
-	_, err = PostItemLog(stub, itemObject, "NA", "DEFAULT")
-	if err != nil {
-		fmt.Println("PostTransaction() : write error while inserting item log record\n")
-		return lastUpdatedItemOBCObject, err
+	if contract.BidNo == "" || contract.BidNo != ar.BidNo {
+		Log(stub).Debug("PostTransaction(): Bid is not the selected bid of the contract", "bidNo", ar.BidNo)
+		return nil, errcode.New(errcode.InvalidState, "PostTransaction(): Bid is not the selected bid of the contract : " + ar.BidNo)
 	}
 
-	fmt.Println("PostTransaction() : Inserted item log record successfully", ar.ItemID)
-
-	// Convert Transaction Object to JSON
-	buff, err := TrantoJSON(ar) //
+	bid, err := GetBidObject(stub, ar.ConractId, ar.BidNo)
 	if err != nil {
-		fmt.Println("GetObjectBuffer() : Failed to convert Transaction Object to JSON ", args[0])
 		return nil, err
 	}
 
-	// Update the ledger with the Buffer Data
-	keys := []string{args[0], args[3]}
-	err = UpdateLedger(stub, "TransTable", keys, buff)
+	trans, err := SplitTransaction(ar, bid)
 	if err != nil {
-		fmt.Println("PostTransaction() : write error while inserting record\n")
-		return buff, err
+		return nil, err
 	}
 
-	fmt.Println("PostTransaction() : Posted Transaction Record successfully\n")
-
-	// Returns New Key. To get Transaction Details, run GetTransaction
-
-	secret_key, _ := json.Marshal(newKey)
-	fmt.Println(string(secret_key))
-	return secret_key, nil
-
-}
+	for _, at := range trans {
+		// Convert Transaction Object to JSON
+		buff, err := TrantoJSON(at) //
+		if err != nil {
+			Log(stub).Error("PostTransaction(): Failed to convert Transaction Object to JSON", "contractId", args[0])
+			return nil, err
+		}
 
-func CreateTransactionRequest(args []string) (ItemTransaction, error) {
+		// Update the ledger with the Buffer Data
+		_, err = InsertRecord(stub, "TRANSACTION", at)
+		if err != nil {
+			Log(stub).Error("PostTransaction(): write error while inserting record")
+			return buff, err
+		}
+	}
 
-	var at ItemTransaction
+	Log(stub).Info("PostTransaction(): Posted Transaction Record successfully")
 
-	// Check there are 9 Arguments
-	if len(args) != 9 {
-		fmt.Println("CreateTransactionRequest(): Incorrect number of arguments. Expecting 9 ")
-		return at, errors.New("CreateTransactionRequest() : Incorrect number of arguments. Expecting 9 ")
+	err = EmitEvent(stub, events.PaymentPosted, ar.UserId, events.PaymentEvent{ContractId: ar.ConractId, BidNo: ar.BidNo, Payments: PaymentsOf(trans)})
+	if err != nil {
+		return nil, err
 	}
 
-	at = ItemTransaction{args[0], args[1], args[2], args[3], args[4], args[5], args[6], args[7], args[8]}
-	fmt.Println("CreateTransactionRequest() : Transaction Request: ", at)
-
-	return at, nil
+	// To get Transaction Details, run GetTransaction
+	return json.Marshal(trans)
 }
 
 ///////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 // Create a Bid Object
-// Once an Item has been opened for auction, bids can be submitted as long as the auction is "OPEN"
+// Bids can be submitted as long as the contract is "OPEN"
+// A team bid names its members with their percentage share as a JSON array in the last argument
+// The bidder must be one of the members, the other members co-sign with CoSignBid
 //./peer chaincode invoke -l golang -n mycc -c '{"Function": "PostBid", "Args":["1111", "BID", "1", "1000", "300", "1200"]}'
 //./peer chaincode invoke -l golang -n mycc -c '{"Function": "PostBid", "Args":["1111", "BID", "2", "1000", "400", "3000"]}'
+//./peer chaincode invoke -l golang -n mycc -c '{"Function": "PostBid", "Args":["1111", "BID", "3", "1000", "400", "2500", "[{\"UserID\":\"400\",\"Share\":\"60\"},{\"UserID\":\"401\",\"Share\":\"40\"}]"]}'
 //
 /////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 func PostBid(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 
-	bid, err := CreateBidObject(args[0:]) //
+	obj, err := model.CreateBidObject(args[0:]) //
 	if err != nil {
 		return nil, err
 	}
+	bid := Bid(obj)
 
 	// Reject the Bid if the Buyer Information Is not Valid or not registered on the Block Chain
-	buyerInfo, err := ValidateMember(stub, args[4])
-	fmt.Println("Buyer information  ", buyerInfo, "  ", args[4])
+	_, err = ValidateMember(stub, bid.UserID)
 	if err != nil {
-		fmt.Println("PostBid() : Failed Buyer not registered on the block-chain ", args[4])
+		Log(stub).Debug("PostBid(): Failed Buyer not registered on the block-chain", "userId", bid.UserID)
 		return nil, err
 	}
 
+	// Every member of a team bid must be registered as well
+	for _, m := range bid.Members {
+		_, err = ValidateMember(stub, m.UserID)
+		if err != nil {
+			Log(stub).Debug("PostBid(): Failed Team member not registered on the block-chain", "userId", m.UserID)
+			return nil, err
+		}
+	}
+
 	///////////////////////////////////////
-	// Reject Bid if Auction is not "OPEN"
+	// Reject Bid if Contract is not "OPEN"
 	///////////////////////////////////////
-	RBytes, err := GetAuctionRequest(stub, "GetAuctionRequest", []string{args[0]})
+	aucR, err := GetContractObject(stub, bid.ContractId)
 	if err != nil {
-		fmt.Println("PostBid() : Cannot find Auction record ", args[0])
-		return nil, errors.New("PostBid(): Cannot find Auction record : " + args[0])
+		Log(stub).Debug("PostBid(): Cannot find Contract record", "contractId", args[0])
+		return nil, errcode.New(errcode.NotFound, "PostBid(): Cannot find Contract record : " + args[0]).WithField("ContractId")
+	}
+
+	if aucR.Status != "OPEN" {
+		Log(stub).Debug("PostBid(): Cannot accept Bid as Contract is not OPEN", "contractId", args[0])
+		return nil, errcode.New(errcode.InvalidState, "PostBid(): Cannot accept Bid as Contract is not OPEN : " + args[0])
 	}
 
-	aucR, err := JSONtoAucReq(RBytes)
+	////////////////////////////
+	// Post or Accept the Bid
+	////////////////////////////
+	buff, err := BidtoJSON(bid) //
+
 	if err != nil {
-		fmt.Println("PostBid() : Cannot UnMarshall Auction record")
-		return nil, errors.New("PostBid(): Cannot UnMarshall Auction record: " + args[0])
+		Log(stub).Error("PostBid(): Failed Cannot create object buffer for write", "contractId", args[1])
+		return nil, errcode.New(errcode.Internal, "PostBid(): Failed Cannot create object buffer for write : " + args[1])
+	} else {
+		// Update the ledger with the Buffer Data
+		// err = stub.PutState(args[0], buff)
+		_, err = InsertRecord(stub, "BID", bid)
+		if err != nil {
+			Log(stub).Error("PostBid(): write error while inserting record")
+			return buff, err
+		}
 	}
 
-	if aucR.Status != "OPEN" {
-		fmt.Println("PostBid() : Cannot accept Bid as Auction is not OPEN ", args[0])
-		return nil, errors.New("PostBid(): Cannot accept Bid as Auction is not OPEN : " + args[0])
+	err = EmitEvent(stub, events.BidPlaced, bid.UserID, BidEventOf(bid))
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// The owner of an OPEN contract selects the winning bid
+// Only a bid whose negotiation ended with an accepted offer can be selected, see AcceptOffer
+// The bidder becomes the worker on the contract at the accepted price, duration and terms
+// and the contract moves to IN_PROGRESS
+//./peer chaincode invoke -l golang -n mycc -c '{"Function": "SelectBidder", "Args":["1000", "BID", "1", "100"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func SelectBidder(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 4 {
+		Log(stub).Debug("SelectBidder(): Incorrect number of arguments. Expecting 4")
+		return nil, errcode.New(errcode.ArgCount, "SelectBidder(): Incorrect number of arguments. Expecting 4 ")
 	}
 
-	///////////////////////////////////////////////////////////////////
-	// Reject Bid if the time bid was received is > Auction Close Time
-	///////////////////////////////////////////////////////////////////
-	if tCompare(bid.BidTime, aucR.CloseDate) == false {
-		fmt.Println("PostBid() Failed : BidTime past the Auction Close Time")
-		return nil, fmt.Errorf("PostBid() Failed : BidTime past the Auction Close Time %s, %s", bid.BidTime, aucR.CloseDate)
+	contract, err := GetContractObject(stub, args[0])
+	if err != nil {
+		return nil, err
 	}
 
-	//////////////////////////////////////////////////////////////////
-	// Reject Bid if Item ID on Bid does not match Item ID on Auction
-	//////////////////////////////////////////////////////////////////
-	if aucR.ItemID != bid.ItemID {
-		fmt.Println("PostBid() Failed : Item ID mismatch on bid. Bid Rejected")
-		return nil, errors.New("PostBid() : Item ID mismatch on Bid. Bid Rejected")
+	if contract.UserID != args[3] {
+		Log(stub).Debug("SelectBidder(): Only the owner of the contract can select a bidder", "userId", args[3])
+		return nil, errcode.New(errcode.NotAllowed, "SelectBidder(): Only the owner of the contract can select a bidder : " + args[3])
 	}
 
-	//////////////////////////////////////////////////////////////////////
-	// Reject Bid if Bid Price is less than Reserve Price
-	// Convert Bid Price and Reserve Price to Integer (TODO - Float)
-	//////////////////////////////////////////////////////////////////////
-	bp, err := strconv.Atoi(bid.BidPrice)
+	if contract.Status != "OPEN" {
+		Log(stub).Debug("SelectBidder(): Cannot select a bidder as Contract is not OPEN", "contractId", args[0])
+		return nil, errcode.New(errcode.InvalidState, "SelectBidder(): Cannot select a bidder as Contract is not OPEN : " + args[0])
+	}
+
+	bid, err := GetBidObject(stub, args[0], args[2])
 	if err != nil {
-		fmt.Println("PostBid() Failed : Bid price should be an integer")
-		return nil, errors.New("PostBid() : Bid price should be an integer")
+		return nil, err
 	}
 
-	hp, err := strconv.Atoi(aucR.ReservePrice)
+	if !BidFullySigned(bid) {
+		Log(stub).Debug("SelectBidder(): Team bid has not been co-signed by all members", "contractId", args[0], "bidNo", args[2])
+		return nil, errcode.New(errcode.InvalidState, "SelectBidder(): Team bid has not been co-signed by all members : " + args[2])
+	}
+
+	offer, err := GetAcceptedOffer(stub, args[0], args[2])
 	if err != nil {
-		return nil, errors.New("PostItem() : Reserve Price should be an integer")
+		return nil, err
 	}
+	if offer == nil {
+		Log(stub).Debug("SelectBidder(): Bid has no accepted offer", "contractId", args[0], "bidNo", args[2])
+		return nil, errcode.New(errcode.InvalidState, "SelectBidder(): Bid has no accepted offer : " + args[2])
+	}
+
+	contract.WorkerID = bid.UserID
+	contract.BidNo = bid.BidNo
+	contract.BidPrice = offer.Price
+	contract.Duration = offer.Duration
+	contract.Terms = offer.Terms
+	contract.Status = "IN_PROGRESS"
 
-	// Check if Bid Price is > Auction Request Reserve Price
-	if bp < hp {
-		return nil, errors.New("PostItem() : Bid Price must be greater than Reserve Price")
+	buff, err := UpdateContractStatus(stub, contract)
+	if err != nil {
+		return nil, err
 	}
 
-	////////////////////////////
-	// Post or Accept the Bid
-	////////////////////////////
-	buff, err := BidtoJSON(bid) //
+	err = EmitEvent(stub, events.BidderSelected, args[3], ContractEventOf(contract))
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
 
+///////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// The owner closes an IN_PROGRESS contract once the work is done
+//./peer chaincode invoke -l golang -n mycc -c '{"Function": "CloseContract", "Args":["1000", "CLOSECONTRACT", "100"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func CloseContract(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 3 {
+		Log(stub).Debug("CloseContract(): Incorrect number of arguments. Expecting 3")
+		return nil, errcode.New(errcode.ArgCount, "CloseContract(): Incorrect number of arguments. Expecting 3 ")
+	}
+
+	contract, err := GetContractObject(stub, args[0])
 	if err != nil {
-		fmt.Println("PostBid() : Failed Cannot create object buffer for write : ", args[1])
-		return nil, errors.New("PostBid(): Failed Cannot create object buffer for write : " + args[1])
-	} else {
-		// Update the ledger with the Buffer Data
-		// err = stub.PutState(args[0], buff)
-		keys := []string{args[0], args[2]}
-		err = UpdateLedger(stub, "BidTable", keys, buff)
-		if err != nil {
-			fmt.Println("PostBidTable() : write error while inserting record\n")
-			return buff, err
-		}
+		return nil, err
+	}
+
+	if contract.UserID != args[2] {
+		Log(stub).Debug("CloseContract(): Only the owner of the contract can close it", "userId", args[2])
+		return nil, errcode.New(errcode.NotAllowed, "CloseContract(): Only the owner of the contract can close it : " + args[2])
+	}
+
+	if contract.Status != "IN_PROGRESS" {
+		Log(stub).Debug("CloseContract(): Cannot close as Contract is not IN_PROGRESS", "contractId", args[0])
+		return nil, errcode.New(errcode.InvalidState, "CloseContract(): Cannot close as Contract is not IN_PROGRESS : " + args[0])
 	}
 
-	return buff, err
+	// Subcontracted work has to be finished first
+	err = CheckChildrenFinished(stub, contract.ContractId)
+	if err != nil {
+		return nil, err
+	}
+
+	contract.Status = "CLOSED"
+
+	// The work was done - an active bond is no longer needed
+	err = ReleaseBond(stub, contract.ContractId)
+	if err != nil {
+		return nil, err
+	}
+
+	buff, err := UpdateContractStatus(stub, contract)
+	if err != nil {
+		return nil, err
+	}
+
+	// Recurring contracts move on to their next cycle
+	nextId, err := SpawnNextCycle(stub, contract)
+	if err != nil {
+		return nil, err
+	}
+
+	ev := ContractEventOf(contract)
+	ev.NextContractId = nextId
+	err = EmitEvent(stub, events.ContractClosed, args[2], ev)
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
 }
 
-func CreateBidObject(args []string) (Bid, error) {
-	var err error
-	var aBid Bid
+///////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// The owner cancels a contract that is still OPEN, no bid has been selected yet
+//./peer chaincode invoke -l golang -n mycc -c '{"Function": "CancelContract", "Args":["1000", "CANCELCONTRACT", "100"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
-	// Check there are 11 Arguments
-	// See example
-	if len(args) != 6 {
-		fmt.Println("CreateBidObject(): Incorrect number of arguments. Expecting 6 ")
-		return aBid, errors.New("CreateBidObject() : Incorrect number of arguments. Expecting 6 ")
+func CancelContract(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 3 {
+		Log(stub).Debug("CancelContract(): Incorrect number of arguments. Expecting 3")
+		return nil, errcode.New(errcode.ArgCount, "CancelContract(): Incorrect number of arguments. Expecting 3 ")
+	}
+
+	contract, err := GetContractObject(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if contract.UserID != args[2] {
+		Log(stub).Debug("CancelContract(): Only the owner of the contract can cancel it", "userId", args[2])
+		return nil, errcode.New(errcode.NotAllowed, "CancelContract(): Only the owner of the contract can cancel it : " + args[2])
+	}
+
+	if contract.Status != "OPEN" {
+		Log(stub).Debug("CancelContract(): Cannot cancel as Contract is not OPEN", "contractId", args[0])
+		return nil, errcode.New(errcode.InvalidState, "CancelContract(): Cannot cancel as Contract is not OPEN : " + args[0])
 	}
 
-	// Validate Bid is an integer
+	contract.Status = "CANCELLED"
 
-	_, err = strconv.Atoi(args[0])
+	buff, err := UpdateContractStatus(stub, contract)
 	if err != nil {
-		return aBid, errors.New("CreateBidObject() : Bid ID should be an integer")
+		return nil, err
 	}
 
-	_, err = strconv.Atoi(args[2])
+	err = EmitEvent(stub, events.ContractCancelled, args[2], ContractEventOf(contract))
 	if err != nil {
-		return aBid, errors.New("CreateBidObject() : Bid ID should be an integer")
+		return nil, err
 	}
+	return buff, nil
+}
 
-	bidTime := time.Now().Format("2006-01-02 15:04:05")
+//////////////////////////////////////////////////////////
+// Fetch a Bid from the ledger as an Object
+//////////////////////////////////////////////////////////
+func GetBidObject(stub shim.ChaincodeStubInterface, contractId string, bidNo string) (Bid, error) {
 
-	aBid = Bid{args[0], args[1], args[2], args[3], args[4], args[5], bidTime}
-	fmt.Println("CreateBidObject() : Bid Object : ", aBid)
+	Avalbytes, err := QueryLedger(stub, "BidTable", []string{contractId, bidNo})
+	if err != nil {
+		Log(stub).Debug("GetBidObject(): Cannot find Bid record", "contractId", contractId, "bidNo", bidNo)
+		return Bid{}, errcode.New(errcode.NotFound, "GetBidObject(): Cannot find Bid record : " + bidNo).WithField("BidNo")
+	}
 
-	return aBid, nil
+	return JSONtoBid(Avalbytes)
+}
+
+//////////////////////////////////////////////////////////
+// Fetch a Contract from the ledger as an Object
+//////////////////////////////////////////////////////////
+func GetContractObject(stub shim.ChaincodeStubInterface, contractId string) (ContractObject, error) {
+
+	Avalbytes, err := QueryLedger(stub, "ContractTable", []string{contractId})
+	if err != nil {
+		Log(stub).Debug("GetContractObject(): Cannot find Contract record", "contractId", contractId)
+		return ContractObject{}, errcode.New(errcode.NotFound, "GetContractObject(): Cannot find Contract record : " + contractId).WithField("ContractId")
+	}
+
+	return JSONtoAucReq(Avalbytes)
 }
 
 ///////////////////////////////////////////////////////////////////////
@@ -965,13 +1073,13 @@ func tCompare(t1 string, t2 string) bool {
 	layout := "2006-01-02 15:04:05"
 	bidTime, err := time.Parse(layout, t1)
 	if err != nil {
-		fmt.Println("tCompare() Failed : time Conversion error on t1")
+		logger.Error("tCompare(): time Conversion error on t1", "error", err)
 		return false
 	}
 
 	aucCloseTime, err := time.Parse(layout, t2)
 	if err != nil {
-		fmt.Println("tCompare() Failed : time Conversion error on t2")
+		logger.Error("tCompare(): time Conversion error on t2", "error", err)
 		return false
 	}
 
@@ -987,10 +1095,10 @@ func tCompare(t1 string, t2 string) bool {
 //////////////////////////////////////////////////////////
 func JSONtoAR(data []byte) (ContractObject, error) {
 
-	ar := ItemObject{}
+	ar := ContractObject{}
 	err := json.Unmarshal([]byte(data), &ar)
 	if err != nil {
-		fmt.Println("Unmarshal failed : ", err)
+		logger.Error("JSONtoAR(): Unmarshal failed", "error", err)
 	}
 
 	return ar, err
@@ -1003,39 +1111,12 @@ func ARtoJSON(ar ContractObject) ([]byte, error) {
 
 	ajson, err := json.Marshal(ar)
 	if err != nil {
-		fmt.Println(err)
-		return nil, err
-	}
-	return ajson, nil
-}
-
-//////////////////////////////////////////////////////////
-// Converts an BID to a JSON String
-//////////////////////////////////////////////////////////
-func ItemLogtoJSON(item ContractObject) ([]byte, error) {
-
-	ajson, err := json.Marshal(item)
-	if err != nil {
-		fmt.Println(err)
+		logger.Error("ARtoJSON(): Failed", "error", err)
 		return nil, err
 	}
 	return ajson, nil
 }
 
-//////////////////////////////////////////////////////////
-// Converts an User Object to a JSON String
-//////////////////////////////////////////////////////////
-func JSONtoItemLog(ithis []byte) (ContractObject, error) {
-
-	item := ContractObject{}
-	err := json.Unmarshal(ithis, &item)
-	if err != nil {
-		fmt.Println("JSONtoContractObject error: ", err)
-		return item, err
-	}
-	return item, err
-}
-
 //////////////////////////////////////////////////////////
 // Converts an Auction Request to a JSON String
 //////////////////////////////////////////////////////////
@@ -1043,7 +1124,7 @@ func AucReqtoJSON(ar ContractObject) ([]byte, error) {
 
 	ajson, err := json.Marshal(ar)
 	if err != nil {
-		fmt.Println(err)
+		logger.Error("AucReqtoJSON(): Failed", "error", err)
 		return nil, err
 	}
 	return ajson, nil
@@ -1057,7 +1138,7 @@ func JSONtoAucReq(areq []byte) (ContractObject, error) {
 	ar := ContractObject{}
 	err := json.Unmarshal(areq, &ar)
 	if err != nil {
-		fmt.Println("JSONtoAucReq error: ", err)
+		logger.Error("JSONtoAucReq(): error", "error", err)
 		return ar, err
 	}
 	return ar, err
@@ -1070,7 +1151,7 @@ func BidtoJSON(myHand Bid) ([]byte, error) {
 
 	ajson, err := json.Marshal(myHand)
 	if err != nil {
-		fmt.Println("BidtoJSON error: ", err)
+		logger.Error("BidtoJSON(): error", "error", err)
 		return nil, err
 	}
 	return ajson, nil
@@ -1084,7 +1165,7 @@ func JSONtoBid(areq []byte) (Bid, error) {
 	myHand := Bid{}
 	err := json.Unmarshal(areq, &myHand)
 	if err != nil {
-		fmt.Println("JSONtoBid error: ", err)
+		logger.Error("JSONtoBid(): error", "error", err)
 		return myHand, err
 	}
 	return myHand, err
@@ -1097,10 +1178,10 @@ func UsertoJSON(user UserObject) ([]byte, error) {
 
 	ajson, err := json.Marshal(user)
 	if err != nil {
-		fmt.Println("UsertoJSON error: ", err)
+		logger.Error("UsertoJSON(): error", "error", err)
 		return nil, err
 	}
-	fmt.Println("UsertoJSON created: ", ajson)
+	logger.Debug("UsertoJSON(): created", "record", ajson)
 	return ajson, nil
 }
 
@@ -1112,10 +1193,10 @@ func JSONtoUser(user []byte) (UserObject, error) {
 	ur := UserObject{}
 	err := json.Unmarshal(user, &ur)
 	if err != nil {
-		fmt.Println("JSONtoUser error: ", err)
+		logger.Error("JSONtoUser(): error", "error", err)
 		return ur, err
 	}
-	fmt.Println("JSONtoUser created: ", ur)
+	logger.Debug("JSONtoUser(): created", "record", ur)
 	return ur, err
 }
 
@@ -1126,7 +1207,7 @@ func TrantoJSON(at ItemTransaction) ([]byte, error) {
 
 	ajson, err := json.Marshal(at)
 	if err != nil {
-		fmt.Println(err)
+		logger.Error("TrantoJSON(): Failed", "error", err)
 		return nil, err
 	}
 	return ajson, nil
@@ -1140,7 +1221,7 @@ func JSONtoTran(areq []byte) (ItemTransaction, error) {
 	at := ItemTransaction{}
 	err := json.Unmarshal(areq, &at)
 	if err != nil {
-		fmt.Println("JSONtoTran error: ", err)
+		logger.Error("JSONtoTran(): error", "error", err)
 		return at, err
 	}
 	return at, err
@@ -1155,49 +1236,11 @@ func validateID(id string) error {
 
 	_, err := strconv.Atoi(id)
 	if err != nil {
-		return errors.New("validateID(): User ID should be an integer")
+		return errcode.New(errcode.InvalidArgument, "validateID(): User ID should be an integer").WithField("UserID")
 	}
 	return nil
 }
 
-//////////////////////////////////////////////
-// Create an ItemLog from Item
-//////////////////////////////////////////////
-
-func ItemToItemLog(io ItemObject) ItemLog {
-
-	iLog := ItemLog{}
-	iLog.ItemID = io.ItemID
-	iLog.Status = "INITIAL"
-	iLog.AuctionedBy = "DEFAULT"
-	iLog.RecType = "ILOG"
-	iLog.ItemDesc = io.ItemDesc
-	iLog.CurrentOwner = io.CurrentOwnerID
-	iLog.Date = time.Now().Format("2006-01-02 15:04:05")
-
-	return iLog
-}
-
-//////////////////////////////////////////////
-// Convert Bid to Transaction for Posting
-//////////////////////////////////////////////
-
-func BidtoTransaction(bid Bid) ItemTransaction {
-
-	var t ItemTransaction
-	t.AuctionID = bid.AuctionID
-	t.RecType = "POSTTRAN"
-	t.ItemID = bid.ItemID
-	t.TransType = "SALE"
-	t.UserId = bid.BuyerID
-	t.TransDate = time.Now().Format("2006-01-02 15:04:05")
-	t.HammerTime = bid.BidTime
-	t.HammerPrice = bid.BidPrice
-	t.Details = "The Highest Bidder does not always win"
-
-	return t
-}
-
 ////////////////////////////////////////////////////////////////////////////
 // Validate if the User Information Exists
 // in the block-chain
@@ -1210,18 +1253,19 @@ func ValidateMember(stub shim.ChaincodeStubInterface, owner string) ([]byte, err
 	Avalbytes, err := QueryLedger(stub, "UserTable", args)
 
 	if err != nil {
-		fmt.Println("ValidateMember() : Failed - Cannot find valid owner record for ART  ", owner)
-		jsonResp := "{\"Error\":\"Failed to get Owner Object Data for " + owner + "\"}"
-		return nil, errors.New(jsonResp)
+		Log(stub).Debug("ValidateMember(): Failed - Cannot find valid user record for", "userId", owner)
+		if errcode.From(err).Code != errcode.NotFound {
+			return nil, err
+		}
+		return nil, errcode.New(errcode.NotRegistered, "ValidateMember(): User is not registered : " + owner).WithField("UserID")
 	}
 
 	if Avalbytes == nil {
-		fmt.Println("ValidateMember() : Failed - Incomplete owner record for ART  ", owner)
-		jsonResp := "{\"Error\":\"Failed - Incomplete information about the owner for " + owner + "\"}"
-		return nil, errors.New(jsonResp)
+		Log(stub).Debug("ValidateMember(): Failed - Incomplete owner record for ART", "userId", owner)
+		return nil, errcode.New(errcode.NotRegistered, "ValidateMember(): Incomplete information about the user : " + owner).WithField("UserID")
 	}
 
-	fmt.Println("ValidateMember() : Validated Item Owner:\n", owner)
+	Log(stub).Debug("ValidateMember(): Validated Item Owner", "userId", owner)
 	return Avalbytes, nil
 }
 
@@ -1248,9 +1292,9 @@ func InitLedger(stub shim.ChaincodeStubInterface, tableName string) error {
 
 	nKeys := GetNumberOfKeys(tableName)
 	if nKeys < 1 {
-		fmt.Println("Atleast 1 Key must be provided \n")
-		fmt.Println("Auction_Application: Failed creating Table ", tableName)
-		return errors.New("Auction_Application: Failed creating Table " + tableName)
+		Log(stub).Error("InitLedger(): At least 1 Key must be provided")
+		Log(stub).Error("InitLedger(): Failed creating Table", "table", tableName)
+		return errcode.New(errcode.Internal, "InitLedger(): Failed creating Table " + tableName)
 	}
 
 	var columnDefsForTbl []*shim.ColumnDefinition
@@ -1267,8 +1311,8 @@ func InitLedger(stub shim.ChaincodeStubInterface, tableName string) error {
 	err := stub.CreateTable(tableName, columnDefsForTbl)
 
 	if err != nil {
-		fmt.Println("Auction_Application: Failed creating Table ", tableName)
-		return errors.New("Auction_Application: Failed creating Table " + tableName)
+		Log(stub).Error("InitLedger(): Failed creating Table", "table", tableName)
+		return errcode.New(errcode.Internal, "InitLedger(): Failed creating Table " + tableName)
 	}
 
 	return err
@@ -1282,7 +1326,7 @@ func UpdateLedger(stub shim.ChaincodeStubInterface, tableName string, keys []str
 
 	nKeys := GetNumberOfKeys(tableName)
 	if nKeys < 1 {
-		fmt.Println("Atleast 1 Key must be provided \n")
+		Log(stub).Error("UpdateLedger(): At least 1 Key must be provided")
 	}
 
 	var columns []*shim.Column
@@ -1295,16 +1339,16 @@ func UpdateLedger(stub shim.ChaincodeStubInterface, tableName string, keys []str
 	lastCol := shim.Column{Value: &shim.Column_Bytes{Bytes: []byte(args)}}
 	columns = append(columns, &lastCol)
 
-	row := shim.Row{columns}
+	row := shim.Row{Columns: columns}
 	ok, err := stub.InsertRow(tableName, row)
 	if err != nil {
-		return fmt.Errorf("UpdateLedger: InsertRow into "+tableName+" Table operation failed. %s", err)
+		return errcode.Wrapf(err, "UpdateLedger: InsertRow into "+tableName+" Table operation failed. %s", err)
 	}
 	if !ok {
-		return errors.New("UpdateLedger: InsertRow into " + tableName + " Table failed. Row with given key " + keys[0] + " already exists")
+		return errcode.New(errcode.AlreadyExists, "UpdateLedger: InsertRow into " + tableName + " Table failed. Row with given key " + keys[0] + " already exists")
 	}
 
-	fmt.Println("UpdateLedger: InsertRow into ", tableName, " Table operation Successful. ")
+	Log(stub).Debug("UpdateLedger(): InsertRow into", "table", tableName)
 	return nil
 }
 
@@ -1318,8 +1362,8 @@ func DeleteFromLedger(stub shim.ChaincodeStubInterface, tableName string, keys [
 	//nKeys := GetNumberOfKeys(tableName)
 	nCol := len(keys)
 	if nCol < 1 {
-		fmt.Println("Atleast 1 Key must be provided \n")
-		return errors.New("DeleteFromLedger failed. Must include at least key values")
+		Log(stub).Error("DeleteFromLedger(): At least 1 Key must be provided")
+		return errcode.New(errcode.Internal, "DeleteFromLedger failed. Must include at least key values")
 	}
 
 	for i := 0; i < nCol; i++ {
@@ -1329,10 +1373,10 @@ func DeleteFromLedger(stub shim.ChaincodeStubInterface, tableName string, keys [
 
 	err := stub.DeleteRow(tableName, columns)
 	if err != nil {
-		return fmt.Errorf("DeleteFromLedger operation failed. %s", err)
+		return errcode.Wrapf(err, "DeleteFromLedger operation failed. %s", err)
 	}
 
-	fmt.Println("DeleteFromLedger: DeleteRow from ", tableName, " Table operation Successful. ")
+	Log(stub).Debug("DeleteFromLedger(): DeleteRow from", "table", tableName)
 	return nil
 }
 
@@ -1344,7 +1388,7 @@ func ReplaceLedgerEntry(stub shim.ChaincodeStubInterface, tableName string, keys
 
 	nKeys := GetNumberOfKeys(tableName)
 	if nKeys < 1 {
-		fmt.Println("Atleast 1 Key must be provided \n")
+		Log(stub).Error("ReplaceLedgerEntry(): At least 1 Key must be provided")
 	}
 
 	var columns []*shim.Column
@@ -1357,16 +1401,16 @@ func ReplaceLedgerEntry(stub shim.ChaincodeStubInterface, tableName string, keys
 	lastCol := shim.Column{Value: &shim.Column_Bytes{Bytes: []byte(args)}}
 	columns = append(columns, &lastCol)
 
-	row := shim.Row{columns}
+	row := shim.Row{Columns: columns}
 	ok, err := stub.ReplaceRow(tableName, row)
 	if err != nil {
-		return fmt.Errorf("ReplaceLedgerEntry: Replace Row into "+tableName+" Table operation failed. %s", err)
+		return errcode.Wrapf(err, "ReplaceLedgerEntry: Replace Row into "+tableName+" Table operation failed. %s", err)
 	}
 	if !ok {
-		return errors.New("ReplaceLedgerEntry: Replace Row into " + tableName + " Table failed. Row with given key " + keys[0] + " already exists")
+		return errcode.New(errcode.NotFound, "ReplaceLedgerEntry: Replace Row into " + tableName + " Table failed. Row with given key " + keys[0] + " does not exist")
 	}
 
-	fmt.Println("ReplaceLedgerEntry: Replace Row in ", tableName, " Table operation Successful. ")
+	Log(stub).Debug("ReplaceLedgerEntry(): Replace Row in", "table", tableName)
 	return nil
 }
 
@@ -1383,26 +1427,26 @@ func QueryLedger(stub shim.ChaincodeStubInterface, tableName string, args []stri
 	}
 
 	row, err := stub.GetRow(tableName, columns)
-	fmt.Println("Length or number of rows retrieved ", len(row.Columns))
+	Log(stub).Debug("QueryLedger(): Length or number of rows retrieved", "count", len(row.Columns))
 
 	if len(row.Columns) == 0 {
-		jsonResp := "{\"Error\":\"Failed retrieving data " + args[0] + ". \"}"
-		fmt.Println("Error retrieving data record for Key = ", args[0], "Error : ", jsonResp)
-		return nil, errors.New(jsonResp)
+		if err != nil {
+			Log(stub).Error("QueryLedger(): Error retrieving data record for Key", "key", args[0], "error", err)
+			return nil, errcode.Wrapf(err, "QueryLedger() operation failed. %s", err)
+		}
+		Log(stub).Debug("QueryLedger(): No record for Key", "table", tableName, "key", args[0])
+		return nil, errcode.New(errcode.NotFound, "QueryLedger(): Cannot find record in " + tableName + " : " + args[0])
 	}
 
-	//fmt.Println("User Query Response:", row)
 	//jsonResp := "{\"Owner\":\"" + string(row.Columns[nCol].GetBytes()) + "\"}"
-	//fmt.Println("User Query Response:%s\n", jsonResp)
 	Avalbytes := row.Columns[nCol].GetBytes()
 
 	// Perform Any additional processing of data
-	fmt.Println("QueryLedger() : Successful - Proceeding to ProcessRequestType ")
+	Log(stub).Debug("QueryLedger(): Successful - Proceeding to ProcessRequestType")
 	err = ProcessQueryResult(stub, Avalbytes, args)
 	if err != nil {
-		fmt.Println("QueryLedger() : Cannot create object  : ", args[1])
-		jsonResp := "{\"QueryLedger() Error\":\" Cannot create Object for key " + args[0] + "\"}"
-		return nil, errors.New(jsonResp)
+		Log(stub).Debug("QueryLedger(): Cannot create object", "table", tableName, "keys", args)
+		return nil, errcode.Wrapf(err, "QueryLedger(): Cannot create Object for key %s : %s", args[0], err)
 	}
 	return Avalbytes, nil
 }
@@ -1411,14 +1455,25 @@ func QueryLedger(stub shim.ChaincodeStubInterface, tableName string, args []stri
 // Get List of Bids for an Auction
 // in the block-chain --
 // ./peer chaincode query -l golang -n mycc -c '{"Function": "GetListOfBids", "Args": ["1111"]}'
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetListOfBids", "Args": ["1111", "20", "<nextToken>"]}'
 // ./peer chaincode query -l golang -n mycc -c '{"Function": "GetLastBid", "Args": ["1111"]}'
 // ./peer chaincode query -l golang -n mycc -c '{"Function": "GetHighestBid", "Args": ["1111"]}'
 /////////////////////////////////////////////////////////////////////////////////////////////////////
 func GetListOfBids(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 
-	rows, err := GetList(stub, "BidTable", args)
+	if len(args) < 1 {
+		Log(stub).Debug("GetListOfBids(): Incorrect number of arguments. Expecting 1")
+		return nil, errcode.New(errcode.ArgCount, "GetListOfBids(): Incorrect number of arguments. Expecting 1 ")
+	}
+
+	pageSize, token, err := PageArgs(args, 1)
 	if err != nil {
-		return nil, fmt.Errorf("GetListOfBids operation failed. Error marshaling JSON: %s", err)
+		return nil, err
+	}
+
+	rows, next, err := GetPage(stub, "BidTable", args[:1], pageSize, token)
+	if err != nil {
+		return nil, errcode.Wrapf(err, "GetListOfBids() operation failed. Error GetPage: %s", err)
 	}
 
 	nCol := GetNumberOfKeys("BidTable")
@@ -1428,15 +1483,15 @@ func GetListOfBids(stub shim.ChaincodeStubInterface, function string, args []str
 		ts := rows[i].Columns[nCol].GetBytes()
 		bid, err := JSONtoBid(ts)
 		if err != nil {
-			fmt.Println("GetListOfBids() Failed : Ummarshall error")
-			return nil, fmt.Errorf("GetListOfBids() operation failed. %s", err)
+			Log(stub).Error("GetListOfBids(): Unmarshal error", "error", err)
+			return nil, errcode.Wrapf(err, "GetListOfBids() operation failed. %s", err)
 		}
 		tlist[i] = bid
 	}
 
-	jsonRows, _ := json.Marshal(tlist)
+	jsonRows, _ := PagetoJSON(tlist, next)
 
-	fmt.Println("List of Bids Requested : ", jsonRows)
+	Log(stub).Debug("GetListOfBids(): List of Bids Requested")
 	return jsonRows, nil
 
 }
@@ -1445,136 +1500,63 @@ func GetListOfBids(stub shim.ChaincodeStubInterface, function string, args []str
 // Get List of Open Auctions  for which bids can be supplied
 // in the block-chain
 // This is a fixed Query to be issued as below
-// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetListOfOpenAucs", "Args": ["2016"]}'
+// The optional arguments are the page size and continuation token, see GetPage
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetListOfOpenContracts", "Args": []}'
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetListOfOpenContracts", "Args": ["20", "<nextToken>"]}'
 ////////////////////////////////////////////////////////////////////////////
 func GetListOfOpenContracts(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 
-	rows, err := GetList(stub, "ContractOpenTable", args)
+	pageSize, token, err := PageArgs(args, 0)
 	if err != nil {
-		return nil, fmt.Errorf("GetListOfOpenContracts operation failed. Error marshaling JSON: %s", err)
+		return nil, err
+	}
+
+	rows, next, err := GetPage(stub, "ContractOpenTable", []string{ContractNamespace}, pageSize, token)
+	if err != nil {
+		return nil, errcode.Wrapf(err, "GetListOfOpenContracts() operation failed. Error GetPage: %s", err)
 	}
 
 	nCol := GetNumberOfKeys("ContractOpenTable")
 
-	tlist := make([]AuctionRequest, len(rows))
+	tlist := make([]ContractObject, len(rows))
 	for i := 0; i < len(rows); i++ {
 		ts := rows[i].Columns[nCol].GetBytes()
 		ar, err := JSONtoAucReq(ts)
 		if err != nil {
-			fmt.Println("GetListOfOpenAucs() Failed : Ummarshall error")
-			return nil, fmt.Errorf("GetListOfOpenAucs() operation failed. %s", err)
+			Log(stub).Error("GetListOfOpenContracts(): Unmarshal error", "error", err)
+			return nil, errcode.Wrapf(err, "GetListOfOpenContracts() operation failed. %s", err)
 		}
 		tlist[i] = ar
 	}
 
-	jsonRows, _ := json.Marshal(tlist)
+	jsonRows, _ := PagetoJSON(tlist, next)
 
-	//fmt.Println("List of Open Auctions : ", jsonRows)
 	return jsonRows, nil
 
 }
 
 ////////////////////////////////////////////////////////////////////////////
-// Get the Item History for an Item
-// in the block-chain .. Pass the Item ID
-// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetItemLog", "Args": ["1000"]}'
-////////////////////////////////////////////////////////////////////////////
-func GetItemLog(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
-
-	// Check there are 1 Arguments provided as per the the struct - two are computed
-	// See example
-	if len(args) < 1 {
-		fmt.Println("GetItemLog(): Incorrect number of arguments. Expecting 1 ")
-		fmt.Println("GetItemLog(): ./peer chaincode query -l golang -n mycc -c '{\"Function\": \"GetItem\", \"Args\": [\"1111\"]}'")
-		return nil, errors.New("CreateItemObject(): Incorrect number of arguments. Expecting 12 ")
-	}
-
-	rows, err := GetList(stub, "ItemHistoryTable", args)
-	if err != nil {
-		return nil, fmt.Errorf("GetItemLog() operation failed. Error marshaling JSON: %s", err)
-	}
-	nCol := GetNumberOfKeys("ItemHistoryTable")
-
-	tlist := make([]ItemLog, len(rows))
-	for i := 0; i < len(rows); i++ {
-		ts := rows[i].Columns[nCol].GetBytes()
-		il, err := JSONtoItemLog(ts)
-		if err != nil {
-			fmt.Println("() Failed : Ummarshall error")
-			return nil, fmt.Errorf("GetItemLog() operation failed. %s", err)
-		}
-		tlist[i] = il
-	}
-
-	jsonRows, _ := json.Marshal(tlist)
-
-	//fmt.Println("All History : ", jsonRows)
-	return jsonRows, nil
-
-}
-
-////////////////////////////////////////////////////////////////////////////
-// Get a List of Items by Category
+// Get a List of Users by Category
 // in the block-chain
-// Input is 2016 + Category
-// Categories include whatever has been defined in the Item Tables - Landscape, Modern, ...
-// See Sample data
-// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetItemListByCat", "Args": ["2016", "Modern"]}'
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetUserListByCat", "Args": ["TR", "20", "<nextToken>"]}'
 ////////////////////////////////////////////////////////////////////////////
-func GetItemListByCat(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+func GetUserListByCat(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 
 	// Check there are 1 Arguments provided as per the the struct - two are computed
 	// See example
 	if len(args) < 1 {
-		fmt.Println("GetItemListByCat(): Incorrect number of arguments. Expecting 1 ")
-		fmt.Println("GetItemListByCat(): ./peer chaincode query -l golang -n mycc -c '{\"Function\": \"GetItemListByCat\", \"Args\": [\"Modern\"]}'")
-		return nil, errors.New("CreateItemObject(): Incorrect number of arguments. Expecting 1 ")
+		Log(stub).Debug("GetUserListByCat(): Incorrect number of arguments. Expecting 1")
+		return nil, errcode.New(errcode.ArgCount, "GetUserListByCat(): Incorrect number of arguments. Expecting 1 ")
 	}
 
-	rows, err := GetList(stub, "ItemCatTable", args)
+	pageSize, token, err := PageArgs(args, 1)
 	if err != nil {
-		return nil, fmt.Errorf("GetItemListByCat() operation failed. Error GetList: %s", err)
-	}
-
-	nCol := GetNumberOfKeys("ItemCatTable")
-
-	tlist := make([]ItemObject, len(rows))
-	for i := 0; i < len(rows); i++ {
-		ts := rows[i].Columns[nCol].GetBytes()
-		io, err := JSONtoAR(ts)
-		if err != nil {
-			fmt.Println("() Failed : Ummarshall error")
-			return nil, fmt.Errorf("GetItemListByCat() operation failed. %s", err)
-		}
-		//TODO: Masking Image binary data, Need a clean solution ?
-		io.ItemImage = []byte{}
-		tlist[i] = io
-	}
-
-	jsonRows, _ := json.Marshal(tlist)
-
-	//fmt.Println("All Items : ", jsonRows)
-	return jsonRows, nil
-
-}
-
-////////////////////////////////////////////////////////////////////////////
-// Get a List of Users by Category
-// in the block-chain
-////////////////////////////////////////////////////////////////////////////
-func GetUserListByCat(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
-
-	// Check there are 1 Arguments provided as per the the struct - two are computed
-	// See example
-	if len(args) < 1 {
-		fmt.Println("GetUserListByCat(): Incorrect number of arguments. Expecting 1 ")
-		fmt.Println("GetUserListByCat(): ./peer chaincode query -l golang -n mycc -c '{\"Function\": \"GetUserListByCat\", \"Args\": [\"AH\"]}'")
-		return nil, errors.New("CreateUserObject(): Incorrect number of arguments. Expecting 1 ")
+		return nil, err
 	}
 
-	rows, err := GetList(stub, "UserCatTable", args)
+	rows, next, err := GetPage(stub, "UserCatTable", args[:1], pageSize, token)
 	if err != nil {
-		return nil, fmt.Errorf("GetUserListByCat() operation failed. Error marshaling JSON: %s", err)
+		return nil, errcode.Wrapf(err, "GetUserListByCat() operation failed. Error GetPage: %s", err)
 	}
 
 	nCol := GetNumberOfKeys("UserCatTable")
@@ -1584,22 +1566,21 @@ func GetUserListByCat(stub shim.ChaincodeStubInterface, function string, args []
 		ts := rows[i].Columns[nCol].GetBytes()
 		uo, err := JSONtoUser(ts)
 		if err != nil {
-			fmt.Println("GetUserListByCat() Failed : Ummarshall error")
-			return nil, fmt.Errorf("GetUserListByCat() operation failed. %s", err)
+			Log(stub).Error("GetUserListByCat(): Unmarshal error", "error", err)
+			return nil, errcode.Wrapf(err, "GetUserListByCat() operation failed. %s", err)
 		}
 		tlist[i] = uo
 	}
 
-	jsonRows, _ := json.Marshal(tlist)
+	jsonRows, _ := PagetoJSON(tlist, next)
 
-	//fmt.Println("All Users : ", jsonRows)
 	return jsonRows, nil
 
 }
 
 ////////////////////////////////////////////////////////////////////////////
 // Get a List of Rows based on query criteria from the OBC
-//
+// Reads every matching row - list queries use GetPage instead
 ////////////////////////////////////////////////////////////////////////////
 func GetList(stub shim.ChaincodeStubInterface, tableName string, args []string) ([]shim.Row, error) {
 	var columns []shim.Column
@@ -1607,8 +1588,8 @@ func GetList(stub shim.ChaincodeStubInterface, tableName string, args []string)
 	nKeys := GetNumberOfKeys(tableName)
 	nCol := len(args)
 	if nCol < 1 {
-		fmt.Println("Atleast 1 Key must be provided \n")
-		return nil, errors.New("GetList failed. Must include at least key values")
+		Log(stub).Error("GetList(): At least 1 Key must be provided")
+		return nil, errcode.New(errcode.Internal, "GetList failed. Must include at least key values")
 	}
 
 	for i := 0; i < nCol; i++ {
@@ -1618,7 +1599,7 @@ func GetList(stub shim.ChaincodeStubInterface, tableName string, args []string)
 
 	rowChannel, err := stub.GetRows(tableName, columns)
 	if err != nil {
-		return nil, fmt.Errorf("GetList operation failed. %s", err)
+		return nil, errcode.Wrapf(err, "GetList operation failed. %s", err)
 	}
 	var rows []shim.Row
 	for {
@@ -1629,7 +1610,6 @@ func GetList(stub shim.ChaincodeStubInterface, tableName string, args []string)
 			} else {
 				rows = append(rows, row)
 				//If required enable for debugging
-				//fmt.Println(row)
 			}
 		}
 		if rowChannel == nil {
@@ -1637,8 +1617,8 @@ func GetList(stub shim.ChaincodeStubInterface, tableName string, args []string)
 		}
 	}
 
-	fmt.Println("Number of Keys retrieved : ", nKeys)
-	fmt.Println("Number of rows retrieved : ", len(rows))
+	Log(stub).Debug("GetList(): Number of Keys retrieved", "keys", nKeys)
+	Log(stub).Debug("GetList(): Number of rows retrieved", "count", len(rows))
 	return rows, nil
 }
 
@@ -1651,7 +1631,7 @@ func GetLastBid(stub shim.ChaincodeStubInterface, function string, args []string
 	tn := "BidTable"
 	rows, err := GetList(stub, tn, args)
 	if err != nil {
-		return nil, fmt.Errorf("GetLastBid operation failed. %s", err)
+		return nil, errcode.Wrapf(err, "GetLastBid() operation failed. %s", err)
 	}
 	nCol := GetNumberOfKeys(tn)
 	var Avalbytes []byte
@@ -1662,13 +1642,13 @@ func GetLastBid(stub shim.ChaincodeStubInterface, function string, args []string
 	for i := 0; i < len(rows); i++ {
 		currentBid := rows[i].Columns[nCol].GetBytes()
 		if err := json.Unmarshal(currentBid, &dat); err != nil {
-			fmt.Println("GetHighestBid() Failed : Ummarshall error")
-			return nil, fmt.Errorf("GetHighestBid(0 operation failed. %s", err)
+			Log(stub).Error("GetLastBid(): Unmarshal error", "error", err)
+			return nil, errcode.Wrapf(err, "GetLastBid() operation failed. %s", err)
 		}
 		bidTime, err := time.Parse(layout, dat["BidTime"].(string))
 		if err != nil {
-			fmt.Println("GetLastBid() Failed : time Conversion error on BidTime")
-			return nil, fmt.Errorf("GetHighestBid() Int Conversion error on BidPrice! failed. %s", err)
+			Log(stub).Error("GetLastBid(): time Conversion error on BidTime", "error", err)
+			return nil, errcode.Wrapf(err, "GetLastBid() Time Conversion error on BidTime! failed. %s", err)
 		}
 
 		if bidTime.Sub(highestTime) > 0 {
@@ -1690,7 +1670,7 @@ func GetNoOfBidsReceived(stub shim.ChaincodeStubInterface, function string, args
 	tn := "BidTable"
 	rows, err := GetList(stub, tn, args)
 	if err != nil {
-		return nil, fmt.Errorf("GetLastBid operation failed. %s", err)
+		return nil, errcode.Wrapf(err, "GetNoOfBidsReceived() operation failed. %s", err)
 	}
 	nBids := len(rows)
 	return []byte(strconv.Itoa(nBids)), nil
@@ -1705,7 +1685,7 @@ func GetHighestBid(stub shim.ChaincodeStubInterface, function string, args []str
 	tn := "BidTable"
 	rows, err := GetList(stub, tn, args)
 	if err != nil {
-		return nil, fmt.Errorf("GetLastBid operation failed. %s", err)
+		return nil, errcode.Wrapf(err, "GetHighestBid() operation failed. %s", err)
 	}
 	nCol := GetNumberOfKeys(tn)
 	var Avalbytes []byte
@@ -1716,13 +1696,13 @@ func GetHighestBid(stub shim.ChaincodeStubInterface, function string, args []str
 	for i := 0; i < len(rows); i++ {
 		currentBid := rows[i].Columns[nCol].GetBytes()
 		if err := json.Unmarshal(currentBid, &dat); err != nil {
-			fmt.Println("GetHighestBid() Failed : Ummarshall error")
-			return nil, fmt.Errorf("GetHighestBid(0 operation failed. %s", err)
+			Log(stub).Error("GetHighestBid(): Unmarshal error", "error", err)
+			return nil, errcode.Wrapf(err, "GetHighestBid() operation failed. %s", err)
 		}
 		bidPrice, err = strconv.Atoi(dat["BidPrice"].(string))
 		if err != nil {
-			fmt.Println("GetHighestBid() Failed : Int Conversion error on BidPrice")
-			return nil, fmt.Errorf("GetHighestBid() Int Conversion error on BidPrice! failed. %s", err)
+			Log(stub).Error("GetHighestBid(): Int Conversion error on BidPrice", "error", err)
+			return nil, errcode.Wrapf(err, "GetHighestBid() Int Conversion error on BidPrice! failed. %s", err)
 		}
 
 		if bidPrice >= highestBid {
@@ -1778,11 +1758,11 @@ func ChkReqType(args []string) bool {
 func CheckRequestType(rt string) bool {
 	for _, val := range recType {
 		if val == rt {
-			fmt.Println("CheckRequestType() : Valid Request Type , val : ", val, rt, "\n")
+			logger.Debug("CheckRequestType(): Valid Request Type", "recType", rt)
 			return true
 		}
 	}
-	fmt.Println("CheckRequestType() : Invalid Request Type , val : ", rt, "\n")
+	logger.Debug("CheckRequestType(): Invalid Request Type", "recType", rt)
 	return false
 }
 
@@ -1813,15 +1793,16 @@ func ProcessQueryResult(stub shim.ChaincodeStubInterface, Avalbytes []byte, args
 		if err != nil {
 			return err
 		}
-		fmt.Println("ProcessRequestType() : ", ur)
+		Log(stub).Debug("ProcessQueryResult()", "record", ur)
 		return err
 
 	case "CREATECONTR":
 		ar, err := JSONtoAR(Avalbytes) //
 		if err != nil {
-			fmt.Println("ProcessRequestType(): Cannot create itemObject \n")
+			Log(stub).Debug("ProcessQueryResult(): Cannot create itemObject")
 			return err
 		}
+		Log(stub).Debug("ProcessQueryResult()", "record", ar)
 		return err
 		
 	case "CLOSECONTRACT":
@@ -1829,7 +1810,7 @@ func ProcessQueryResult(stub shim.ChaincodeStubInterface, Avalbytes []byte, args
 		if err != nil {
 			return err
 		}
-		fmt.Println("ProcessRequestType() : ", ar)
+		Log(stub).Debug("ProcessQueryResult()", "record", ar)
 		return err
 	case "CANCELCONTRACT":
 	case "POSTTRAN":
@@ -1837,15 +1818,61 @@ func ProcessQueryResult(stub shim.ChaincodeStubInterface, Avalbytes []byte, args
 		if err != nil {
 			return err
 		}
-		fmt.Println("ProcessRequestType() : ", atr)
+		Log(stub).Debug("ProcessQueryResult()", "record", atr)
 		return err
 	case "BID":
 		bid, err := JSONtoBid(Avalbytes) //
 		if err != nil {
 			return err
 		}
-		fmt.Println("ProcessRequestType() : ", bid)
+		Log(stub).Debug("ProcessQueryResult()", "record", bid)
+		return err
+	case "DELIVERABLE":
+		dl, err := JSONtoDeliverable(Avalbytes) //
+		if err != nil {
+			return err
+		}
+		Log(stub).Debug("ProcessQueryResult()", "record", dl)
+		return err
+	case "REVIEW":
+		rv, err := JSONtoReview(Avalbytes) //
+		if err != nil {
+			return err
+		}
+		Log(stub).Debug("ProcessQueryResult()", "record", rv)
 		return err
+	case "DISPUTE":
+		dp, err := JSONtoDispute(Avalbytes) //
+		if err != nil {
+			return err
+		}
+		Log(stub).Debug("ProcessQueryResult()", "record", dp)
+		return err
+	case "BOND":
+		bond, err := JSONtoBond(Avalbytes) //
+		if err != nil {
+			return err
+		}
+		Log(stub).Debug("ProcessQueryResult()", "record", bond)
+		return err
+	case "TEMPLATE":
+		tmpl, err := JSONtoTemplate(Avalbytes) //
+		if err != nil {
+			return err
+		}
+		Log(stub).Debug("ProcessQueryResult()", "record", tmpl)
+		return err
+	case "SKILL":
+		// Skill and UserSkill share the record type
+		var sk map[string]interface{}
+		err := json.Unmarshal(Avalbytes, &sk)
+		if err != nil {
+			return err
+		}
+		Log(stub).Debug("ProcessQueryResult()", "record", sk)
+		return err
+	case "REQUEST":
+		return nil
 	case "DEFAULT":
 		return nil
 	case "XFER":
@@ -1854,7 +1881,7 @@ func ProcessQueryResult(stub shim.ChaincodeStubInterface, Avalbytes []byte, args
 		return nil
 	default:
 
-		return errors.New("Unknown")
+		return errcode.New(errcode.Internal, "ProcessQueryResult(): Unknown record type : " + recType)
 	}
 	return nil
 
@@ -1884,68 +1911,62 @@ func ShellCmdToCloseAuction(aucID string) error {
 	x := "sh /opt/gopath/src/github.com/hyperledger/fabric/peer/closeauction.sh"
 	err := exe_cmd(x)
 	if err != nil {
-		fmt.Println("%s", err)
+		logger.Error("ShellCmdToCloseAuction(): Failed", "error", err)
 	}
 
 	err = exe_cmd("rm /opt/gopath/src/github.com/hyperledger/fabric/peer/closeauction.sh")
 	if err != nil {
-		fmt.Println("%s", err)
+		logger.Error("ShellCmdToCloseAuction(): Failed", "error", err)
 	}
 
-	fmt.Println("Kicking off CloseAuction", argStr)
+	logger.Info("ShellCmdToCloseAuction(): Kicking off CloseAuction", "command", argStr)
 	return nil
 }
 
 func exe_cmd(cmd string) error {
 
-	fmt.Println("command :  ", cmd)
+	logger.Debug("exe_cmd(): command", "command", cmd)
 	parts := strings.Fields(cmd)
 	head := parts[0]
 	parts = parts[1:len(parts)]
 
 	_, err := exec.Command(head, parts...).CombinedOutput()
 	if err != nil {
-		fmt.Println("%s", err)
+		logger.Error("exe_cmd(): Failed", "error", err)
 	}
 	return err
 }
 
 //////////////////////////////////////////////////////////////////////////
-// Update the Auction Object
-// This function updates the status of the auction
-// from INIT to OPEN to CLOSED
+// Update the Contract Object
+// This function updates the status of the contract
+// from OPEN to IN_PROGRESS to CLOSED
+// The index tables declared in Records are kept in step
 //////////////////////////////////////////////////////////////////////////
 
-func UpdateContractStatus(stub shim.ChaincodeStubInterface, tableName string, ar AuctionRequest) ([]byte, error) {
+func UpdateContractStatus(stub shim.ChaincodeStubInterface, ar ContractObject) ([]byte, error) {
 
-	buff, err := AucReqtoJSON(ar)
+	buff, err := ReplaceRecord(stub, "CONTRACT", ar)
 	if err != nil {
-		fmt.Println("UpdateContractStatus() : Failed Cannot create object buffer for write : ", ar.AuctionID)
-		return nil, errors.New("UpdateContractStatus(): Failed Cannot create object buffer for write : " + ar.AuctionID)
-	}
-
-	// Update the ledger with the Buffer Data
-	keys := []string{ar.AuctionID, ar.ItemID}
-	err = ReplaceLedgerEntry(stub, "ContractTable", keys, buff)
-	if err != nil {
-		fmt.Println("UpdateContractStatus() : write error while inserting record\n")
-		return buff, err
+		Log(stub).Error("UpdateContractStatus(): write error while replacing record")
+		return nil, err
 	}
-	return buff, err
+	return buff, nil
 }
 
 func main() {
 	// maximize CPU usage for maximum performance
 	//runtime.GOMAXPROCS(runtime.NumCPU())
-	fmt.Println("Starting Services: global job matching marketPlace ")
+	InitLogLevel()
+	logger.Info("Starting Services: global job matching marketPlace", "level", logger.Level())
 
 	gopath = os.Getenv("GOPATH")
 	if len(os.Args) == 2 && strings.EqualFold(os.Args[1], "DEV") {
-		fmt.Println("----------------- STARTED IN DEV MODE -------------------- ")
+		logger.Info("main(): Started in DEV mode")
 		//set chaincode path for DEV MODE
 		ccPath = fmt.Sprintf("%s/src/github.com/hyperledger/fabric/service/global/", gopath)
 	} else {
-		fmt.Println("----------------- STARTED IN NET MODE -------------------- ")
+		logger.Info("main(): Started in NET mode")
 		//set chaincode path for NET MODE
 		ccPath = fmt.Sprintf("%s/src/github.com/Global-Blockchain/service/global/", gopath)
 	}
@@ -1953,6 +1974,6 @@ func main() {
 	// Start the shim -- running the fabric
 	err := shim.Start(new(SimpleChaincode))
 	if err != nil {
-		fmt.Printf("Error starting Simple chaincode: %s", err)
+		logger.Error("main(): Error starting Simple chaincode", "error", err)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+)
+
+// Queries check their own arguments, Query lets zero arguments through
+func TestQueryWithoutArgs(t *testing.T) {
+	stub := newMemStub()
+	stub.initLedger(t)
+
+	optional := map[string]bool{"GetMarketStats": true, "GetListOfOpenContracts": true}
+	for _, function := range []string{"GetUser", "ViewContracts", "GetContract", "GetVersion", "GetDeliverables", "VerifyDeliverable",
+		"GetUserReviews", "GetDispute", "GetBonds", "GetTemplate", "VerifyContractTemplate", "GetContractSeries", "GetNegotiation",
+		"GetContractTree", "RecommendContracts", "RecommendBidders", "GetListOfBids", "GetListOfOpenContracts", "GetUserListByCat",
+		"ListContracts", "GetMarketStats", "ExportTables", "GetContractAudit"} {
+		_, err := new(SimpleChaincode).Query(stub, function, []string{})
+		if optional[function] {
+			if err != nil {
+				t.Errorf("Query(%s) without args error = %v", function, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("Query(%s) without args succeeded", function)
+			continue
+		}
+		if e, ok := errcode.Parse(err.Error()); !ok || e.Code != errcode.ArgCount {
+			t.Errorf("Query(%s) without args error = %v, want %s", function, err, errcode.ArgCount)
+		}
+	}
+}
+
+func TestInvokeRequestID(t *testing.T) {
+	stub := newMemStub()
+	stub.initLedger(t)
+	cc := new(SimpleChaincode)
+
+	// Free text that looks like a request ID stays an argument
+	args := []string{"100", "USER", "Ashley Hart", "TR", "One Market Street", "9161234567", "ahart@example.com", "Bank of America", "requestId=0001234"}
+	first, err := cc.Invoke(stub, "PostUser?requestId=7f3c9a", args)
+	if err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+	user, err := JSONtoUser(first)
+	if err != nil || user.AccountNo != "requestId=0001234" {
+		t.Errorf("PostUser() = %+v, %v", user, err)
+	}
+
+	// A retry returns the first result instead of failing on the existing user
+	again, err := cc.Invoke(stub, "PostUser?requestId=7f3c9a", args)
+	if err != nil || string(again) != string(first) {
+		t.Errorf("retry = %s, %v, want %s", again, err, first)
+	}
+	if _, err := cc.Invoke(stub, "PostUser", args); err == nil {
+		t.Error("Invoke() without a request ID posted the user twice")
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/events"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// A Deliverable is a pointer to a blob kept in an off-chain blob store
+// Only the SHA-256 digest, the size and the storage URI are written to the ledger
+// The blob itself is uploaded through the blobgateway service
+///////////////////////////////////////////////////////////////////////////////////////
+
+type Deliverable struct {
+	ContractId string
+	RecType    string // DELIVERABLE
+	UserID     string // User who posted the deliverable
+	Digest     string // Hex encoded SHA-256 of the blob
+	Size       string // Size of the blob in bytes
+	URI        string // Where the blob store keeps the blob
+	PostTime   string
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Record a deliverable against an IN_PROGRESS contract
+// Only the worker, or a member of the selected team bid, can deliver
+// The digest, size and URI are returned by the blob store after the upload
+// Example
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "PostDeliverable", "Args":["1000", "DELIVERABLE", "200", "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", "4", "fs://sha256/9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func PostDeliverable(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	dl, err := CreateDeliverable(args[0:])
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if the User ID specified is registered and valid
+	_, err = ValidateMember(stub, dl.UserID)
+	if err != nil {
+		Log(stub).Debug("PostDeliverable(): Failed User not registered on the block-chain", "userId", dl.UserID)
+		return nil, err
+	}
+
+	// The contract has to exist before anything can be delivered against it
+	contract, err := GetContractObject(stub, dl.ContractId)
+	if err != nil {
+		return nil, err
+	}
+
+	if contract.Status != "IN_PROGRESS" {
+		Log(stub).Debug("PostDeliverable(): Cannot deliver as Contract is not IN_PROGRESS", "contractId", dl.ContractId)
+		return nil, errcode.New(errcode.InvalidState, "PostDeliverable(): Cannot deliver as Contract is not IN_PROGRESS : " + dl.ContractId)
+	}
+
+	if dl.UserID != contract.WorkerID {
+		bid, err := GetBidObject(stub, contract.ContractId, contract.BidNo)
+		if err != nil {
+			return nil, err
+		}
+		if !OnBidTeam(bid, dl.UserID) {
+			Log(stub).Debug("PostDeliverable(): Only the worker of the contract can deliver", "userId", dl.UserID)
+			return nil, errcode.New(errcode.NotAllowed, "PostDeliverable(): Only the worker of the contract can deliver : " + dl.UserID)
+		}
+	}
+
+	buff, err := DeliverabletoJSON(dl)
+	if err != nil {
+		Log(stub).Error("PostDeliverable(): Failed Cannot create object buffer for write", "contractId", dl.ContractId)
+		return nil, errcode.New(errcode.Internal, "PostDeliverable(): Failed Cannot create object buffer for write : " + dl.ContractId)
+	}
+
+	keys := []string{dl.ContractId, dl.Digest}
+	err = UpdateLedger(stub, "DeliverableTable", keys, buff)
+	if err != nil {
+		Log(stub).Error("PostDeliverable(): write error while inserting record")
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.DeliverablePosted, dl.UserID, events.DeliverableEvent{ContractId: dl.ContractId, Digest: dl.Digest, Size: dl.Size, URI: dl.URI})
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+func CreateDeliverable(args []string) (Deliverable, error) {
+
+	var dl Deliverable
+
+	// Check there are 6 Arguments provided as per the the struct - PostTime is computed
+	if len(args) != 6 {
+		logger.Debug("CreateDeliverable(): Incorrect number of arguments. Expecting 6")
+		return dl, errcode.New(errcode.ArgCount, "CreateDeliverable(): Incorrect number of arguments. Expecting 6 ")
+	}
+
+	_, err := strconv.Atoi(args[0])
+	if err != nil {
+		return dl, errcode.New(errcode.InvalidArgument, "CreateDeliverable(): Contract ID should be an integer").WithField("ContractId")
+	}
+
+	digest, err := hex.DecodeString(args[3])
+	if err != nil || len(digest) != 32 {
+		return dl, errcode.New(errcode.InvalidArgument, "CreateDeliverable(): Digest should be a hex encoded SHA-256").WithField("Digest")
+	}
+
+	size, err := strconv.ParseInt(args[4], 10, 64)
+	if err != nil || size < 0 {
+		return dl, errcode.New(errcode.InvalidArgument, "CreateDeliverable(): Size should be a positive integer").WithField("Size")
+	}
+
+	if args[5] == "" {
+		return dl, errcode.New(errcode.InvalidArgument, "CreateDeliverable(): Storage URI is required").WithField("URI")
+	}
+
+	postTime := time.Now().Format("2006-01-02 15:04:05")
+
+	dl = Deliverable{args[0], args[1], args[2], hex.EncodeToString(digest), args[4], args[5], postTime}
+	logger.Debug("CreateDeliverable(): Deliverable Object", "record", dl)
+
+	return dl, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////
+// Get all Deliverables recorded on a Contract
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetDeliverables", "Args": ["1000"]}'
+// Optional page size and continuation token follow, see GetPage
+/////////////////////////////////////////////////////////////////////////////////////////
+func GetDeliverables(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) < 1 {
+		Log(stub).Debug("GetDeliverables(): Incorrect number of arguments. Expecting 1")
+		return nil, errcode.New(errcode.ArgCount, "GetDeliverables(): Incorrect number of arguments. Expecting 1 ")
+	}
+
+	pageSize, token, err := PageArgs(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, next, err := GetPage(stub, "DeliverableTable", args[:1], pageSize, token)
+	if err != nil {
+		return nil, errcode.Wrapf(err, "GetDeliverables() operation failed. Error GetList: %s", err)
+	}
+
+	nCol := GetNumberOfKeys("DeliverableTable")
+
+	tlist := make([]Deliverable, len(rows))
+	for i := 0; i < len(rows); i++ {
+		ts := rows[i].Columns[nCol].GetBytes()
+		dl, err := JSONtoDeliverable(ts)
+		if err != nil {
+			Log(stub).Error("GetDeliverables(): Unmarshal error", "error", err)
+			return nil, errcode.Wrapf(err, "GetDeliverables() operation failed. %s", err)
+		}
+		tlist[i] = dl
+	}
+
+	jsonRows, _ := PagetoJSON(tlist, next)
+	return jsonRows, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////
+// Confirm that a blob presented by a client is the one recorded on the contract
+// The client hashes the blob (blobgateway POST /verify does this) and passes
+// the digest and the size
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "VerifyDeliverable", "Args": ["1000", "9f86d0...0a08", "4"]}'
+/////////////////////////////////////////////////////////////////////////////////////////
+func VerifyDeliverable(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 3 {
+		Log(stub).Debug("VerifyDeliverable(): Incorrect number of arguments. Expecting 3")
+		return nil, errcode.New(errcode.ArgCount, "VerifyDeliverable(): Incorrect number of arguments. Expecting 3 ")
+	}
+
+	result := struct {
+		ContractId string
+		Digest     string
+		Verified   bool
+		URI        string
+	}{ContractId: args[0], Digest: strings.ToLower(args[1])}
+
+	// A digest that was never recorded simply does not verify
+	Avalbytes, err := QueryLedger(stub, "DeliverableTable", []string{result.ContractId, result.Digest})
+	if err == nil {
+		dl, err := JSONtoDeliverable(Avalbytes)
+		if err != nil {
+			return nil, err
+		}
+		result.Verified = dl.Size == args[2]
+		result.URI = dl.URI
+	}
+
+	Log(stub).Debug("VerifyDeliverable()", "result", result)
+	return json.Marshal(result)
+}
+
+//////////////////////////////////////////////////////////
+// Converts a Deliverable to a JSON String
+//////////////////////////////////////////////////////////
+func DeliverabletoJSON(dl Deliverable) ([]byte, error) {
+
+	ajson, err := json.Marshal(dl)
+	if err != nil {
+		logger.Error("DeliverabletoJSON(): error", "error", err)
+		return nil, err
+	}
+	return ajson, nil
+}
+
+//////////////////////////////////////////////////////////
+// Converts a JSON String to a Deliverable
+//////////////////////////////////////////////////////////
+func JSONtoDeliverable(areq []byte) (Deliverable, error) {
+
+	dl := Deliverable{}
+	err := json.Unmarshal(areq, &dl)
+	if err != nil {
+		logger.Error("JSONtoDeliverable(): error", "error", err)
+		return dl, err
+	}
+	return dl, err
+}
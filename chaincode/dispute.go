@@ -0,0 +1,522 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/events"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// A Dispute can be opened by the owner or the worker of an IN_PROGRESS contract
+// An Appraiser (UserType AP) is assigned as arbitrator and rules on the dispute
+// The ruling is settled into TransTable and the contract is RESOLVED
+//
+// Status : OPEN -> ASSIGNED -> RULED
+// Ruling : PAYOUT - worker receives the full price
+//          SPLIT  - worker receives WorkerShare percent, owner is refunded the rest
+//          REFUND - owner is refunded the full price
+// Payments already made while IN_PROGRESS count towards what the worker receives
+///////////////////////////////////////////////////////////////////////////////////////
+
+type Dispute struct {
+	ContractId   string
+	RecType      string // DISPUTE
+	RaisedBy     string
+	Reason       string
+	Evidence     []Evidence
+	ArbitratorID string
+	Status       string
+	Ruling       string
+	WorkerShare  string // Percentage of the price paid to the worker
+	OpenDate     string
+	RulingDate   string
+}
+
+// Evidence points to a blob in the off-chain blob store, see Deliverable
+type Evidence struct {
+	UserID string
+	Digest string
+	Size   string
+	URI    string
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Open a Dispute on an IN_PROGRESS contract
+// The last argument is a JSON array of evidence returned by the blob store
+// Example
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "OpenDispute", "Args":["1000", "DISPUTE", "100", "Work not delivered", "[{\"Digest\":\"9f86...0a08\",\"Size\":\"4\",\"URI\":\"fs://sha256/9f86...0a08\"}]"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func OpenDispute(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 5 {
+		Log(stub).Debug("OpenDispute(): Incorrect number of arguments. Expecting 5")
+		return nil, errcode.New(errcode.ArgCount, "OpenDispute(): Incorrect number of arguments. Expecting 5 ")
+	}
+
+	contract, err := GetContractObject(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if contract.Status != "IN_PROGRESS" {
+		Log(stub).Debug("OpenDispute(): Cannot open a dispute as Contract is not IN_PROGRESS", "contractId", args[0])
+		return nil, errcode.New(errcode.InvalidState, "OpenDispute(): Cannot open a dispute as Contract is not IN_PROGRESS : " + args[0])
+	}
+
+	if args[2] != contract.UserID && args[2] != contract.WorkerID {
+		Log(stub).Debug("OpenDispute(): User is not a party of the contract", "userId", args[2])
+		return nil, errcode.New(errcode.NotAllowed, "OpenDispute(): User is not a party of the contract : " + args[2])
+	}
+
+	evidence, err := ParseEvidence(args[2], args[4])
+	if err != nil {
+		return nil, err
+	}
+
+	dp := Dispute{ContractId: args[0], RecType: args[1], RaisedBy: args[2], Reason: args[3], Evidence: evidence,
+		Status: "OPEN", OpenDate: time.Now().Format("2006-01-02 15:04:05")}
+
+	buff, err := DisputetoJSON(dp)
+	if err != nil {
+		Log(stub).Error("OpenDispute(): Failed Cannot create object buffer for write", "contractId", args[0])
+		return nil, errcode.New(errcode.Internal, "OpenDispute(): Failed Cannot create object buffer for write : " + args[0])
+	}
+
+	err = UpdateLedger(stub, "DisputeTable", []string{dp.ContractId}, buff)
+	if err != nil {
+		Log(stub).Error("OpenDispute(): write error while inserting record")
+		return nil, err
+	}
+
+	// No work can be closed out while the dispute is pending
+	contract.Status = "DISPUTED"
+	_, err = UpdateContractStatus(stub, contract)
+	if err != nil {
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.DisputeOpened, dp.RaisedBy, DisputeEventOf(dp))
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Add more evidence to a Dispute that has not been ruled yet
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "AddDisputeEvidence", "Args":["1000", "DISPUTE", "200", "[...]"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func AddDisputeEvidence(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 4 {
+		Log(stub).Debug("AddDisputeEvidence(): Incorrect number of arguments. Expecting 4")
+		return nil, errcode.New(errcode.ArgCount, "AddDisputeEvidence(): Incorrect number of arguments. Expecting 4 ")
+	}
+
+	contract, err := GetContractObject(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if args[2] != contract.UserID && args[2] != contract.WorkerID {
+		Log(stub).Debug("AddDisputeEvidence(): User is not a party of the contract", "userId", args[2])
+		return nil, errcode.New(errcode.NotAllowed, "AddDisputeEvidence(): User is not a party of the contract : " + args[2])
+	}
+
+	dp, err := GetDisputeObject(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if dp.Status == "RULED" {
+		Log(stub).Debug("AddDisputeEvidence(): Dispute has already been ruled", "contractId", args[0])
+		return nil, errcode.New(errcode.InvalidState, "AddDisputeEvidence(): Dispute has already been ruled : " + args[0])
+	}
+
+	evidence, err := ParseEvidence(args[2], args[3])
+	if err != nil {
+		return nil, err
+	}
+	dp.Evidence = append(dp.Evidence, evidence...)
+
+	buff, err := ReplaceDispute(stub, dp)
+	if err != nil {
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.DisputeUpdated, args[2], DisputeEventOf(dp))
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Assign an Appraiser as arbitrator of an OPEN Dispute
+// Only an Auction House user (the last argument) can assign the arbitrator
+// The arbitrator must be a registered AP user and cannot be a party of the contract
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "AssignArbitrator", "Args":["1000", "DISPUTE", "300", "900"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func AssignArbitrator(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 4 {
+		Log(stub).Debug("AssignArbitrator(): Incorrect number of arguments. Expecting 4")
+		return nil, errcode.New(errcode.ArgCount, "AssignArbitrator(): Incorrect number of arguments. Expecting 4 ")
+	}
+
+	err := ValidateAuctionHouse(stub, args[3], "AssignArbitrator")
+	if err != nil {
+		return nil, err
+	}
+
+	userBytes, err := ValidateMember(stub, args[2])
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := JSONtoUser(userBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.UserType != "AP" {
+		Log(stub).Debug("AssignArbitrator(): Arbitrator must be an Appraiser", "userId", args[2])
+		return nil, errcode.New(errcode.NotAllowed, "AssignArbitrator(): Arbitrator must be an Appraiser : " + args[2])
+	}
+
+	contract, err := GetContractObject(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if args[2] == contract.UserID || args[2] == contract.WorkerID {
+		Log(stub).Debug("AssignArbitrator(): Arbitrator cannot be a party of the contract", "userId", args[2])
+		return nil, errcode.New(errcode.NotAllowed, "AssignArbitrator(): Arbitrator cannot be a party of the contract : " + args[2])
+	}
+
+	dp, err := GetDisputeObject(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if dp.Status != "OPEN" {
+		Log(stub).Debug("AssignArbitrator(): Dispute is not OPEN", "contractId", args[0])
+		return nil, errcode.New(errcode.InvalidState, "AssignArbitrator(): Dispute is not OPEN : " + args[0])
+	}
+
+	dp.ArbitratorID = args[2]
+	dp.Status = "ASSIGNED"
+
+	buff, err := ReplaceDispute(stub, dp)
+	if err != nil {
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.DisputeUpdated, args[2], DisputeEventOf(dp))
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// The assigned arbitrator rules on the Dispute
+// The ruling is settled into TransTable and the contract is RESOLVED
+// Payments already made to the worker count towards the worker's share and only the rest of the price is settled
+// The last argument is the percentage paid to the worker and is only used for SPLIT
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "RuleDispute", "Args":["1000", "DISPUTE", "300", "SPLIT", "60"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func RuleDispute(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 5 {
+		Log(stub).Debug("RuleDispute(): Incorrect number of arguments. Expecting 5")
+		return nil, errcode.New(errcode.ArgCount, "RuleDispute(): Incorrect number of arguments. Expecting 5 ")
+	}
+
+	dp, err := GetDisputeObject(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if dp.Status != "ASSIGNED" || dp.ArbitratorID != args[2] {
+		Log(stub).Debug("RuleDispute(): Dispute is not assigned to", "userId", args[2])
+		return nil, errcode.New(errcode.NotAllowed, "RuleDispute(): Dispute is not assigned to : " + args[2])
+	}
+
+	var share int
+	switch args[3] {
+	case "PAYOUT":
+		share = 100
+	case "REFUND":
+		share = 0
+	case "SPLIT":
+		share, err = strconv.Atoi(args[4])
+		if err != nil || share < 0 || share > 100 {
+			return nil, errcode.New(errcode.InvalidArgument, "RuleDispute(): Worker share should be an integer between 0 and 100").WithField("WorkerShare")
+		}
+	default:
+		return nil, errcode.New(errcode.InvalidArgument, "RuleDispute(): Ruling should be one of PAYOUT, SPLIT or REFUND : " + args[3]).WithField("Ruling")
+	}
+
+	contract, err := GetContractObject(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// Subcontracts must be finished before the contract is RESOLVED, as for CloseContract
+	err = CheckChildrenFinished(stub, contract.ContractId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Settle on the agreed bid price
+	price, err := strconv.Atoi(contract.BidPrice)
+	if err != nil {
+		return nil, errcode.New(errcode.Internal, "RuleDispute(): Bid Price should be an integer : " + contract.BidPrice)
+	}
+
+	// Payments already posted count towards the worker's share, only the rest of the price is settled
+	paid, err := PaidToWorker(stub, contract.ContractId)
+	if err != nil {
+		return nil, err
+	}
+
+	held := price - paid
+	if held < 0 {
+		held = 0
+	}
+	payout := price*share/100 - paid
+	if payout < 0 {
+		payout = 0
+	}
+	if payout > held {
+		payout = held
+	}
+
+	if payout > 0 {
+		_, err = PostSettlement(stub, contract, "PAYOUT", contract.WorkerID, payout)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if held-payout > 0 {
+		_, err = PostSettlement(stub, contract, "REFUND", contract.UserID, held-payout)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Pay out a claim if the ruling went against a bonded party
+	err = SettleBond(stub, contract, share)
+	if err != nil {
+		return nil, err
+	}
+
+	dp.Ruling = args[3]
+	dp.WorkerShare = strconv.Itoa(share)
+	dp.Status = "RULED"
+	dp.RulingDate = time.Now().Format("2006-01-02 15:04:05")
+
+	buff, err := ReplaceDispute(stub, dp)
+	if err != nil {
+		return nil, err
+	}
+
+	contract.Status = "RESOLVED"
+	_, err = UpdateContractStatus(stub, contract)
+	if err != nil {
+		return nil, err
+	}
+
+	// Payouts, refunds and bond claims of the ruling
+	ev := DisputeEventOf(dp)
+	ev.Payments, err = TxPayments(stub, dp.ContractId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.DisputeRuled, args[2], ev)
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+//////////////////////////////////////////////////////////////////////
+// Work payments are the money paid to the worker for the contract
+// Refunds to the owner and bond premiums and claims are not
+//////////////////////////////////////////////////////////////////////
+func IsWorkPayment(transType string) bool {
+
+	switch transType {
+	case "REFUND", "PREMIUM", "CLAIM":
+		return false
+	}
+	return true
+}
+
+//////////////////////////////////////////////////////////////////////
+// Sum of the work payments posted into TransTable for a contract
+//////////////////////////////////////////////////////////////////////
+func PaidToWorker(stub shim.ChaincodeStubInterface, contractId string) (int, error) {
+
+	rows, err := GetList(stub, "TransTable", []string{contractId})
+	if err != nil {
+		return 0, errcode.Wrapf(err, "PaidToWorker() operation failed. Error GetList: %s", err)
+	}
+
+	nCol := GetNumberOfKeys("TransTable")
+	paid := 0
+	for _, row := range rows {
+		at, err := JSONtoTran(row.Columns[nCol].GetBytes())
+		if err != nil {
+			return 0, err
+		}
+		if !IsWorkPayment(at.TransType) {
+			continue
+		}
+		amount, err := strconv.Atoi(at.TransactionAmount)
+		if err != nil {
+			return 0, errcode.New(errcode.Internal, "PaidToWorker(): Transaction Amount should be an integer : " + at.TransactionAmount)
+		}
+		paid += amount
+	}
+	return paid, nil
+}
+
+//////////////////////////////////////////////////////////////////////
+// Write a settlement entry for a contract into TransTable
+// Settlement entries are keyed by ContractId and a TransactionId of the txid and TransType
+//////////////////////////////////////////////////////////////////////
+func PostSettlement(stub shim.ChaincodeStubInterface, contract ContractObject, transType string, userId string, amount int) ([]byte, error) {
+
+	tr := ItemTransaction{ConractId: contract.ContractId, RecType: "POSTTRAN", TransactionId: stub.GetTxID() + "-" + transType,
+		TransType: transType, UserId: userId, TransDate: time.Now().Format("2006-01-02 15:04:05"),
+		TransactionAmount: strconv.Itoa(amount), BidNo: contract.BidNo}
+
+	// Money going to the worker of a team bid is split among the members
+	trans := []ItemTransaction{tr}
+	if userId == contract.WorkerID && contract.BidNo != "" {
+		bid, err := GetBidObject(stub, contract.ContractId, contract.BidNo)
+		if err != nil {
+			return nil, err
+		}
+		trans, err = SplitTransaction(tr, bid)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, at := range trans {
+		_, err := InsertRecord(stub, "TRANSACTION", at)
+		if err != nil {
+			Log(stub).Error("PostSettlement(): write error while inserting record")
+			return nil, err
+		}
+	}
+
+	Log(stub).Info("PostSettlement(): Posted", "transType", transType, "amount", amount, "userId", userId)
+	return json.Marshal(trans)
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////
+// Retrieve the Dispute of a Contract
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetDispute", "Args": ["1000"]}'
+/////////////////////////////////////////////////////////////////////////////////////////
+func GetDispute(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) < 1 {
+		Log(stub).Debug("GetDispute(): Incorrect number of arguments. Expecting 1")
+		return nil, errcode.New(errcode.ArgCount, "GetDispute(): Incorrect number of arguments. Expecting 1 ")
+	}
+
+	Avalbytes, err := QueryLedger(stub, "DisputeTable", args[:1])
+	if err != nil {
+		Log(stub).Debug("GetDispute(): Failed to Query Object")
+		return nil, errcode.Wrapf(err, "GetDispute(): Cannot find Dispute record : %s", args[0]).WithField("ContractId")
+	}
+
+	return Avalbytes, nil
+}
+
+func GetDisputeObject(stub shim.ChaincodeStubInterface, contractId string) (Dispute, error) {
+
+	Avalbytes, err := QueryLedger(stub, "DisputeTable", []string{contractId})
+	if err != nil {
+		Log(stub).Debug("GetDisputeObject(): Cannot find Dispute record", "contractId", contractId)
+		return Dispute{}, errcode.New(errcode.NotFound, "GetDisputeObject(): Cannot find Dispute record : " + contractId).WithField("ContractId")
+	}
+
+	return JSONtoDispute(Avalbytes)
+}
+
+func ReplaceDispute(stub shim.ChaincodeStubInterface, dp Dispute) ([]byte, error) {
+
+	buff, err := DisputetoJSON(dp)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ReplaceLedgerEntry(stub, "DisputeTable", []string{dp.ContractId}, buff)
+	if err != nil {
+		Log(stub).Error("ReplaceDispute(): write error while replacing record")
+		return nil, err
+	}
+	return buff, nil
+}
+
+//////////////////////////////////////////////////////////
+// Parse a JSON array of Evidence supplied by userId
+//////////////////////////////////////////////////////////
+func ParseEvidence(userId string, data string) ([]Evidence, error) {
+
+	var evidence []Evidence
+	if err := json.Unmarshal([]byte(data), &evidence); err != nil {
+		return nil, errcode.New(errcode.InvalidArgument, "ParseEvidence(): Evidence should be a JSON array : " + err.Error()).WithField("Evidence")
+	}
+
+	for i := range evidence {
+		digest, err := hex.DecodeString(evidence[i].Digest)
+		if err != nil || len(digest) != 32 || evidence[i].URI == "" {
+			return nil, errcode.New(errcode.InvalidArgument, "ParseEvidence(): Evidence needs a hex encoded SHA-256 Digest and a URI").WithField("Evidence")
+		}
+		evidence[i].UserID = userId
+	}
+	return evidence, nil
+}
+
+//////////////////////////////////////////////////////////
+// Converts a Dispute to a JSON String
+//////////////////////////////////////////////////////////
+func DisputetoJSON(dp Dispute) ([]byte, error) {
+
+	ajson, err := json.Marshal(dp)
+	if err != nil {
+		logger.Error("DisputetoJSON(): error", "error", err)
+		return nil, err
+	}
+	return ajson, nil
+}
+
+//////////////////////////////////////////////////////////
+// Converts a JSON String to a Dispute
+//////////////////////////////////////////////////////////
+func JSONtoDispute(areq []byte) (Dispute, error) {
+
+	dp := Dispute{}
+	err := json.Unmarshal(areq, &dp)
+	if err != nil {
+		logger.Error("JSONtoDispute(): error", "error", err)
+		return dp, err
+	}
+	return dp, err
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// An IN_PROGRESS contract of price 4000 with paid of it already posted to
+// the worker and a Dispute assigned to arbitrator 300
+func disputedLedger(t *testing.T, paid string) *memStub {
+	stub := newMemStub()
+	stub.initLedger(t)
+
+	contract := ContractObject{ContractId: "1000", RecType: "CREATECONTR", Type: "IT", Amount: "5000", CreationDate: "2016-10-18 10:00:00",
+		UserID: "100", WorkerID: "200", BidPrice: "4000", Status: "IN_PROGRESS"}
+	if _, err := InsertRecord(stub, "CONTRACT", contract); err != nil {
+		t.Fatal(err)
+	}
+	if paid != "" {
+		tr := ItemTransaction{ConractId: "1000", RecType: "POSTTRAN", TransactionId: "tx0", TransType: "PAYMENT", UserId: "200", TransactionAmount: paid}
+		if _, err := InsertRecord(stub, "TRANSACTION", tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	buff, err := DisputetoJSON(Dispute{ContractId: "1000", RecType: "DISPUTE", RaisedBy: "100", Status: "ASSIGNED", ArbitratorID: "300"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateLedger(stub, "DisputeTable", []string{"1000"}, buff); err != nil {
+		t.Fatal(err)
+	}
+	return stub
+}
+
+// settled returns the amounts of the ruling by TransType
+func settled(t *testing.T, stub *memStub) map[string]string {
+	t.Helper()
+	rows, err := GetList(stub, "TransTable", []string{"1000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	amounts := map[string]string{}
+	for _, row := range rows {
+		at, err := JSONtoTran(row.Columns[GetNumberOfKeys("TransTable")].GetBytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if at.TransactionId != "tx0" {
+			amounts[at.TransType] = at.TransactionAmount
+		}
+	}
+	return amounts
+}
+
+func TestRuleDispute(t *testing.T) {
+	tests := []struct {
+		name, paid, ruling, share string
+		want                      map[string]string
+	}{
+		{"nothing paid", "", "SPLIT", "60", map[string]string{"PAYOUT": "2400", "REFUND": "1600"}},
+		{"part paid", "1000", "SPLIT", "60", map[string]string{"PAYOUT": "1400", "REFUND": "1600"}},
+		{"more than the share paid", "3000", "SPLIT", "50", map[string]string{"REFUND": "1000"}},
+		{"fully paid", "4000", "PAYOUT", "", map[string]string{}},
+		{"fully paid and refunded", "4000", "REFUND", "", map[string]string{}},
+	}
+	for _, tt := range tests {
+		stub := disputedLedger(t, tt.paid)
+		if _, err := RuleDispute(stub, "RuleDispute", []string{"1000", "DISPUTE", "300", tt.ruling, tt.share}); err != nil {
+			t.Fatalf("%s: RuleDispute() error = %v", tt.name, err)
+		}
+		if got := settled(t, stub); len(got) != len(tt.want) || got["PAYOUT"] != tt.want["PAYOUT"] || got["REFUND"] != tt.want["REFUND"] {
+			t.Errorf("%s: settled %v, want %v", tt.name, got, tt.want)
+		}
+		if c, _ := GetContractObject(stub, "1000"); c.Status != "RESOLVED" {
+			t.Errorf("%s: contract is %s", tt.name, c.Status)
+		}
+	}
+}
+
+func TestRuleDisputeOpenSubcontract(t *testing.T) {
+	stub := disputedLedger(t, "")
+	child := ContractObject{ContractId: "1001", RecType: "CREATECONTR", Type: "IT", Amount: "500", CreationDate: "2016-10-19 10:00:00",
+		UserID: "200", ParentId: "1000", Status: "OPEN"}
+	if _, err := InsertRecord(stub, "CONTRACT", child); err != nil {
+		t.Fatal(err)
+	}
+	entry, _ := json.Marshal(SubcontractEntry{"1000", "SUBCONTRACT", "1001", "2016-10-19 10:00:00"})
+	if err := UpdateLedger(stub, "SubcontractTable", []string{"1000", "1001"}, entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := RuleDispute(stub, "RuleDispute", []string{"1000", "DISPUTE", "300", "PAYOUT", ""}); err == nil {
+		t.Fatal("RuleDispute() with an OPEN subcontract succeeded")
+	}
+	if got := settled(t, stub); len(got) != 0 {
+		t.Errorf("settled %v before the subcontract was finished", got)
+	}
+}
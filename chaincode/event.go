@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/events"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// Chaincode events
+// Every invoke that changes the ledger sets one event, see package events for the
+// names and payloads that clients decode. Fabric keeps only the last event of a
+// transaction, so events are emitted by the invoke functions right before they
+// return and never by the helpers they share
+// During a Batch the events of the operations are collected and set as one
+// BatchApplied event, see batch.go
+// Events naming a contract are also appended to its audit trail, see audit.go
+///////////////////////////////////////////////////////////////////////////////////////
+
+// Events of the operations of every Batch in progress, by txID
+var (
+	batchMu     sync.Mutex
+	batchEvents = map[string]*[]events.Envelope{}
+)
+
+// Collect the events of a transaction into pending, nil ends the collection
+func SetBatchEvents(txID string, pending *[]events.Envelope) {
+	batchMu.Lock()
+	defer batchMu.Unlock()
+	if pending == nil {
+		delete(batchEvents, txID)
+	} else {
+		batchEvents[txID] = pending
+	}
+}
+
+func GetBatchEvents(txID string) *[]events.Envelope {
+	batchMu.Lock()
+	defer batchMu.Unlock()
+	return batchEvents[txID]
+}
+
+func EmitEvent(stub shim.ChaincodeStubInterface, name string, actor string, payload interface{}) error {
+
+	buff, err := events.Encode(name, stub.GetTxID(), time.Now().Format("2006-01-02 15:04:05"), actor, payload)
+	if err != nil {
+		Log(stub).Error("EmitEvent(): Failed to encode event", "event", name, "error", err)
+		return err
+	}
+
+	err = AppendAudit(stub, buff)
+	if err != nil {
+		return err
+	}
+
+	if pending := GetBatchEvents(stub.GetTxID()); pending != nil {
+		var env events.Envelope
+		err = json.Unmarshal(buff, &env)
+		if err != nil {
+			return errcode.Wrapf(err, "EmitEvent(): Failed to collect event %s : %s", name, err)
+		}
+		// Events of a batch share the TxID, Seq tells them apart
+		env.Seq = len(*pending) + 1
+		*pending = append(*pending, env)
+		return nil
+	}
+
+	err = stub.SetEvent(name, buff)
+	if err != nil {
+		Log(stub).Error("EmitEvent(): Failed to set event", "event", name, "error", err)
+		return err
+	}
+	return nil
+}
+
+//////////////////////////////////////////////////////////
+// Event payloads of the ledger records
+//////////////////////////////////////////////////////////
+func ContractEventOf(c ContractObject) events.ContractEvent {
+	return events.ContractEvent{ContractId: c.ContractId, Type: c.Type, Amount: c.Amount, Status: c.Status,
+		OwnerID: c.UserID, WorkerID: c.WorkerID, BidNo: c.BidNo, BidPrice: c.BidPrice,
+		ParentId: c.ParentId, SeriesId: c.SeriesId, Cycle: c.Cycle}
+}
+
+func BidEventOf(bid Bid) events.BidEvent {
+	ev := events.BidEvent{ContractId: bid.ContractId, BidNo: bid.BidNo, BidderID: bid.UserID, Price: bid.BidPrice}
+	for _, m := range bid.Members {
+		ev.Members = append(ev.Members, events.Member{UserID: m.UserID, Share: m.Share, Signed: m.Signed})
+	}
+	return ev
+}
+
+func OfferEventOf(offer Offer) events.OfferEvent {
+	return events.OfferEvent{ContractId: offer.ContractId, BidNo: offer.BidNo, Seq: offer.Seq, Role: offer.Role,
+		Action: offer.Action, Price: offer.Price, Duration: offer.Duration}
+}
+
+func DisputeEventOf(dp Dispute) events.DisputeEvent {
+	return events.DisputeEvent{ContractId: dp.ContractId, Status: dp.Status, RaisedBy: dp.RaisedBy,
+		ArbitratorID: dp.ArbitratorID, Ruling: dp.Ruling, WorkerShare: dp.WorkerShare}
+}
+
+func BondEventOf(bond Bond) events.BondEvent {
+	return events.BondEvent{ContractId: bond.ContractId, InsurerID: bond.InsurerID, InsuredID: bond.InsuredID,
+		Premium: bond.Premium, Coverage: bond.Coverage, Status: bond.Status}
+}
+
+func PaymentsOf(trans []ItemTransaction) []events.Payment {
+	payments := make([]events.Payment, len(trans))
+	for i, at := range trans {
+		payments[i] = events.Payment{TransactionId: at.TransactionId, TransType: at.TransType,
+			UserID: at.UserId, Amount: at.TransactionAmount}
+	}
+	return payments
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Settlements posted on a contract by the current transaction
+// PostSettlement keys them by the transaction ID, see dispute.go
+////////////////////////////////////////////////////////////////////////////
+func TxPayments(stub shim.ChaincodeStubInterface, contractId string) ([]events.Payment, error) {
+
+	rows, err := GetList(stub, "TransTable", []string{contractId})
+	if err != nil {
+		return nil, errcode.Wrapf(err, "TxPayments() operation failed. Error GetList: %s", err)
+	}
+
+	nCol := GetNumberOfKeys("TransTable")
+	prefix := stub.GetTxID() + "-"
+
+	var trans []ItemTransaction
+	for _, row := range rows {
+		at, err := JSONtoTran(row.Columns[nCol].GetBytes())
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(at.TransactionId, prefix) {
+			trans = append(trans, at)
+		}
+	}
+	return PaymentsOf(trans), nil
+}
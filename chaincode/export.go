@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/model"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// Ledger export and import
+// ExportTables pages through every table of aucTables, in that order, and returns the
+// rows as JSON Lines, one model.ExportLine per row with the table, the keys and the
+// decoded Details. Tables are scanned with an empty partial key
+// A page holds at most page size rows, see PageArgs. When there are more rows the last
+// line of the page only holds the nextToken of the next page
+// ImportRows inserts exported rows into a fresh ledger, an existing row fails the import
+// cmd/ccledger exports a ledger to a file and imports the file again
+// Only Auction House (AH) users can export or import. The AH user running the first
+// import into a ledger without users must be one of the imported users
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "ExportTables", "Args": ["100", "500", ""]}'
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "ImportRows", "Args":["IMPORT", "100", "{\"table\":\"SkillTable\",\"keys\":[\"GO\"],\"details\":{\"SkillId\":\"GO\",\"RecType\":\"SKILL\",\"Name\":\"Go programming\"}}"]}'
+///////////////////////////////////////////////////////////////////////////////////////
+
+func ExportTables(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) < 1 {
+		Log(stub).Debug("ExportTables(): Incorrect number of arguments. Expecting 1")
+		return nil, errcode.New(errcode.ArgCount, "ExportTables(): Incorrect number of arguments. Expecting 1 ")
+	}
+
+	pageSize, token, err := PageArgs(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ValidateAuctionHouse(stub, args[0], "ExportTables")
+	if err != nil {
+		return nil, err
+	}
+
+	// The token holds the table and keys of the last row exported
+	start := 0
+	var after []string
+	if token != "" {
+		t, err := DecodePageToken(token)
+		start = TableIndex(t.T)
+		if err != nil || start < 0 || len(t.K) != GetNumberOfKeys(t.T) {
+			return nil, errcode.New(errcode.InvalidArgument, "ExportTables(): Invalid continuation token").WithField("token")
+		}
+		after = t.K
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	count := 0
+	var last pageToken
+	next := ""
+	for i := start; i < len(aucTables) && next == ""; i++ {
+		table := aucTables[i]
+		nKeys := GetNumberOfKeys(table)
+
+		rowChannel, err := stub.GetRows(table, []shim.Column{})
+		if err != nil {
+			return nil, errcode.Wrapf(err, "ExportTables(): GetRows of %s failed. %s", table, err)
+		}
+
+		for row := range rowChannel {
+			// Keep reading once the page is full so the channel is drained
+			if next != "" {
+				continue
+			}
+			keys := RowKeys(row, nKeys)
+			if i == start && after != nil && CompareKeys(keys, after) <= 0 {
+				continue
+			}
+			if count == pageSize {
+				next = EncodePageToken(last)
+				continue
+			}
+
+			err = enc.Encode(model.ExportLine{Table: table, Keys: keys, Details: model.ExportDetails(row.Columns[nKeys].GetBytes())})
+			if err != nil {
+				return nil, errcode.Wrapf(err, "ExportTables(): Cannot export a row of %s : %s", table, err)
+			}
+			count++
+			last = pageToken{table, keys}
+		}
+	}
+
+	if next != "" {
+		enc.Encode(model.ExportLine{NextToken: next})
+	}
+
+	Log(stub).Info("ExportTables(): Rows exported", "count", count, "more", next != "")
+	return buf.Bytes(), nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////
+// Insert rows written by ExportTables
+// Args are IMPORT, the AH user running the import and JSON Lines of at most
+// model.MaxBatchBytes. The rows are all inserted or, if one fails, none is
+// Returns the number of rows inserted by table
+/////////////////////////////////////////////////////////////////////////////////////////////
+
+func ImportRows(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 3 {
+		Log(stub).Debug("ImportRows(): Incorrect number of arguments. Expecting 3")
+		return nil, errcode.New(errcode.ArgCount, "ImportRows(): Incorrect number of arguments. Expecting 3 ")
+	}
+	if len(args[2]) > model.MaxBatchBytes {
+		return nil, errcode.Errorf(errcode.InvalidArgument, "ImportRows(): Rows exceed %d bytes", model.MaxBatchBytes).WithField("rows")
+	}
+
+	var lines []model.ExportLine
+	err := model.ReadExport(strings.NewReader(args[2]), func(line model.ExportLine) error {
+		if TableIndex(line.Table) < 0 {
+			return errcode.New(errcode.InvalidArgument, "ImportRows(): Unknown table : " + line.Table).WithField("table")
+		}
+		if len(line.Keys) != GetNumberOfKeys(line.Table) {
+			return errcode.Errorf(errcode.InvalidArgument, "ImportRows(): %s rows have %d keys, got %d", line.Table, GetNumberOfKeys(line.Table), len(line.Keys)).WithField("keys")
+		}
+		lines = append(lines, line)
+		return nil
+	})
+	if err != nil {
+		return nil, errcode.Wrapf(err, "ImportRows(): %s", err).WithField("rows")
+	}
+	if len(lines) == 0 {
+		return nil, errcode.New(errcode.InvalidArgument, "ImportRows(): No rows to import").WithField("rows")
+	}
+
+	err = ValidateAuctionHouse(stub, args[1], "ImportRows")
+	if err != nil && errcode.From(err).Code == errcode.NotRegistered {
+		hasUsers, herr := LedgerHasUsers(stub)
+		if herr != nil {
+			return nil, herr
+		}
+		if !hasUsers {
+			// A fresh ledger, the AH user comes with the rows
+			err = ImportedAuctionHouse(lines, args[1])
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for i, line := range lines {
+		err = UpdateLedger(stub, line.Table, line.Keys, model.ImportDetails(line))
+		if err != nil {
+			Log(stub).Debug("ImportRows(): Row not imported", "table", line.Table, "keys", line.Keys)
+			return nil, errcode.Wrapf(err, "ImportRows(): Row %d of %s not imported, no row was : %s", i, line.Table, err).WithField("rows[" + strconv.Itoa(i) + "]")
+		}
+		counts[line.Table]++
+	}
+
+	Log(stub).Info("ImportRows(): Rows imported", "count", len(lines))
+	return json.Marshal(counts)
+}
+
+//////////////////////////////////////////////////////////
+// Fails unless userId is a registered AH user
+//////////////////////////////////////////////////////////
+func ValidateAuctionHouse(stub shim.ChaincodeStubInterface, userId string, caller string) error {
+
+	userBytes, err := ValidateMember(stub, userId)
+	if err != nil {
+		return err
+	}
+
+	user, err := JSONtoUser(userBytes)
+	if err != nil {
+		return errcode.Wrapf(err, caller + "(): Cannot read user %s : %s", userId, err)
+	}
+
+	if user.UserType != "AH" {
+		Log(stub).Debug(caller + "(): Only Auction House users are allowed", "userId", userId)
+		return errcode.New(errcode.NotAllowed, caller + "(): Only Auction House users are allowed : " + userId)
+	}
+	return nil
+}
+
+//////////////////////////////////////////////////////////
+// Fails unless userId is an AH user among the rows
+// imported into a ledger without users
+//////////////////////////////////////////////////////////
+func ImportedAuctionHouse(lines []model.ExportLine, userId string) error {
+
+	for _, line := range lines {
+		if line.Table != "UserTable" || line.Keys[0] != userId {
+			continue
+		}
+		user, err := JSONtoUser(model.ImportDetails(line))
+		if err != nil || user.UserType != "AH" {
+			break
+		}
+		return nil
+	}
+	return errcode.New(errcode.NotAllowed, "ImportRows(): The first import must be run by an imported Auction House user : " + userId)
+}
+
+//////////////////////////////////////////////////////////
+// Whether UserTable has any row
+//////////////////////////////////////////////////////////
+func LedgerHasUsers(stub shim.ChaincodeStubInterface) (bool, error) {
+
+	rowChannel, err := stub.GetRows("UserTable", []shim.Column{})
+	if err != nil {
+		return false, errcode.Wrapf(err, "LedgerHasUsers(): GetRows of UserTable failed. %s", err)
+	}
+
+	found := false
+	for range rowChannel {
+		found = true
+	}
+	return found, nil
+}
+
+//////////////////////////////////////////////////////////
+// Position of a table in aucTables, -1 if it has none
+//////////////////////////////////////////////////////////
+func TableIndex(table string) int {
+
+	for i, val := range aucTables {
+		if val == table {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// Secondary indexes
+// A record type declares its primary table and its index tables in Records, each
+// with a function extracting the keys from the record. Index tables hold a copy
+// of the record like ContractCatTable always did
+// InsertRecord and ReplaceRecord write the primary table and every index in the
+// same invoke, moving index entries whose keys changed. Records are never deleted,
+// a contract ends as CLOSED or CANCELLED
+// An index returning nil keys does not list the record, e.g. ContractOpenTable
+// only lists OPEN contracts
+// OnSave hooks run after every save with the old record, nil for an insert
+///////////////////////////////////////////////////////////////////////////////////////
+
+type Index struct {
+	Table string
+	Keys  func(rec interface{}) []string
+}
+
+type Record struct {
+	Table   string
+	Keys    func(rec interface{}) []string
+	Decode  func(data []byte) (interface{}, error)
+	Indexes []Index
+	OnSave  []func(stub shim.ChaincodeStubInterface, old interface{}, rec interface{}) error
+}
+
+var Records = map[string]Record{
+	"CONTRACT": {
+		Table: "ContractTable",
+		Keys:  contractKeys(func(c ContractObject) []string { return []string{c.ContractId} }),
+		Decode: func(data []byte) (interface{}, error) {
+			return JSONtoAucReq(data)
+		},
+		Indexes: []Index{
+			// Partitions of the contracts, see ListContracts
+			{"ContractCatTable", contractKeys(func(c ContractObject) []string { return []string{ContractNamespace, c.Type, c.ContractId} })},
+			{"ContractOpenTable", contractKeys(func(c ContractObject) []string {
+				if c.Status != "OPEN" {
+					return nil
+				}
+				return []string{ContractNamespace, c.ContractId}
+			})},
+			{"ContractPeriodTable", contractKeys(func(c ContractObject) []string { return append(ContractPeriod(c.CreationDate), c.ContractId) })},
+			// Search indexes used by ViewContracts
+			{"ContractStatusTable", contractKeys(func(c ContractObject) []string { return []string{c.Status, c.ContractId} })},
+			{"ContractOwnerTable", contractKeys(func(c ContractObject) []string { return []string{c.UserID, c.ContractId} })},
+		},
+		OnSave: []func(shim.ChaincodeStubInterface, interface{}, interface{}) error{CountContract},
+	},
+	"BID": {
+		Table: "BidTable",
+		Keys:  func(rec interface{}) []string { b := rec.(Bid); return []string{b.ContractId, b.BidNo} },
+		Decode: func(data []byte) (interface{}, error) {
+			return JSONtoBid(data)
+		},
+		OnSave: []func(shim.ChaincodeStubInterface, interface{}, interface{}) error{CountBid},
+	},
+	"TRANSACTION": {
+		Table: "TransTable",
+		Keys:  func(rec interface{}) []string { at := rec.(ItemTransaction); return []string{at.ConractId, at.TransactionId} },
+		Decode: func(data []byte) (interface{}, error) {
+			return JSONtoTran(data)
+		},
+		OnSave: []func(shim.ChaincodeStubInterface, interface{}, interface{}) error{CountTransaction},
+	},
+	"USER": {
+		Table: "UserTable",
+		Keys:  userKeys(func(u UserObject) []string { return []string{u.UserID} }),
+		Decode: func(data []byte) (interface{}, error) {
+			return JSONtoUser(data)
+		},
+		Indexes: []Index{
+			{"UserCatTable", userKeys(func(u UserObject) []string { return []string{u.UserType, u.UserID} })},
+		},
+	},
+	"USERSKILL": {
+		Table: "UserSkillTable",
+		Keys:  userSkillKeys(func(us UserSkill) []string { return []string{us.UserID, us.SkillId} }),
+		Decode: func(data []byte) (interface{}, error) {
+			var us UserSkill
+			err := json.Unmarshal(data, &us)
+			return us, err
+		},
+		Indexes: []Index{
+			{"SkillUserTable", userSkillKeys(func(us UserSkill) []string { return []string{us.SkillId, us.UserID} })},
+		},
+	},
+}
+
+func contractKeys(f func(ContractObject) []string) func(interface{}) []string {
+	return func(rec interface{}) []string { return f(rec.(ContractObject)) }
+}
+
+func userKeys(f func(UserObject) []string) func(interface{}) []string {
+	return func(rec interface{}) []string { return f(rec.(UserObject)) }
+}
+
+func userSkillKeys(f func(UserSkill) []string) func(interface{}) []string {
+	return func(rec interface{}) []string { return f(rec.(UserSkill)) }
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Insert a new record - fails if the primary key already exists
+////////////////////////////////////////////////////////////////////////////
+func InsertRecord(stub shim.ChaincodeStubInterface, recName string, rec interface{}) ([]byte, error) {
+	return SaveRecord(stub, recName, rec, false)
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Replace an existing record - fails if the primary key does not exist
+////////////////////////////////////////////////////////////////////////////
+func ReplaceRecord(stub shim.ChaincodeStubInterface, recName string, rec interface{}) ([]byte, error) {
+	return SaveRecord(stub, recName, rec, true)
+}
+
+func SaveRecord(stub shim.ChaincodeStubInterface, recName string, rec interface{}, replace bool) ([]byte, error) {
+
+	def, ok := Records[recName]
+	if !ok {
+		return nil, errcode.New(errcode.Internal, "SaveRecord(): Unknown record type : " + recName)
+	}
+
+	buff, err := json.Marshal(rec)
+	if err != nil {
+		Log(stub).Error("SaveRecord(): Failed Cannot create object buffer for write", "recName", recName)
+		return nil, err
+	}
+
+	keys := def.Keys(rec)
+	oldBytes, found, err := LookupLedger(stub, def.Table, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var old interface{}
+	if replace {
+		if !found {
+			return nil, errcode.New(errcode.NotFound, "SaveRecord(): Cannot find record in " + def.Table + " : " + keys[0])
+		}
+		old, err = def.Decode(oldBytes)
+		if err != nil {
+			return nil, err
+		}
+		err = ReplaceLedgerEntry(stub, def.Table, keys, buff)
+	} else {
+		err = UpdateLedger(stub, def.Table, keys, buff)
+	}
+	if err != nil {
+		Log(stub).Error("SaveRecord(): write error in", "table", def.Table)
+		return nil, err
+	}
+
+	for _, idx := range def.Indexes {
+		newKeys := idx.Keys(rec)
+		var oldKeys []string
+		if old != nil {
+			oldKeys = idx.Keys(old)
+		}
+
+		same := oldKeys != nil && newKeys != nil && CompareKeys(oldKeys, newKeys) == 0
+		if oldKeys != nil && !same {
+			err = DeleteFromLedger(stub, idx.Table, oldKeys)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if newKeys == nil {
+			continue
+		}
+
+		if same {
+			err = ReplaceLedgerEntry(stub, idx.Table, newKeys, buff)
+		} else {
+			err = UpdateLedger(stub, idx.Table, newKeys, buff)
+		}
+		if err != nil {
+			Log(stub).Error("SaveRecord(): write error in index", "table", idx.Table)
+			return nil, err
+		}
+	}
+
+	for _, hook := range def.OnSave {
+		err = hook(stub, old, rec)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return buff, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Write every index entry of a record again, adding the ones that are missing
+// Used when index tables or keys change
+////////////////////////////////////////////////////////////////////////////
+func RebuildIndexes(stub shim.ChaincodeStubInterface, recName string, rec interface{}) error {
+
+	def, ok := Records[recName]
+	if !ok {
+		return errcode.New(errcode.Internal, "RebuildIndexes(): Unknown record type : " + recName)
+	}
+
+	buff, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	for _, idx := range def.Indexes {
+		keys := idx.Keys(rec)
+		if keys == nil {
+			continue
+		}
+		err = DeleteFromLedger(stub, idx.Table, keys)
+		if err != nil {
+			return err
+		}
+		err = UpdateLedger(stub, idx.Table, keys, buff)
+		if err != nil {
+			Log(stub).Error("RebuildIndexes(): write error in index", "table", idx.Table)
+			return err
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Number of key columns of a table as it was created on the ledger
+// It differs from GetNumberOfKeys for tables created by an older chaincode
+// whose keys changed since, see ReshapeTable
+////////////////////////////////////////////////////////////////////////////
+func LedgerKeyCount(stub shim.ChaincodeStubInterface, tableName string) (int, error) {
+
+	tbl, err := stub.GetTable(tableName)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, def := range tbl.ColumnDefinitions {
+		if def.Key {
+			n++
+		}
+	}
+	return n, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Create a table again with the key columns of GetNumberOfKeys
+// BidTable was keyed by ContractId alone and ContractCatTable by the 2016 key
+// and Type before their keys gained a column. InsertRow rejects rows with
+// another number of columns, so the old table is dropped. Rows of a primary
+// table are written back under the keys of their record, index tables come
+// back empty and are filled again by RebuildIndexes
+////////////////////////////////////////////////////////////////////////////
+func ReshapeTable(stub shim.ChaincodeStubInterface, tableName string) error {
+
+	var primary *Record
+	index := false
+	for _, def := range Records {
+		if def.Table == tableName {
+			found := def
+			primary = &found
+		}
+		for _, idx := range def.Indexes {
+			index = index || idx.Table == tableName
+		}
+	}
+	if primary == nil && !index {
+		return errcode.New(errcode.Internal, "ReshapeTable(): No record is kept in " + tableName)
+	}
+
+	rowChannel, err := stub.GetRows(tableName, []shim.Column{})
+	if err != nil {
+		return errcode.Wrapf(err, "ReshapeTable(): GetRows of %s failed. %s", tableName, err)
+	}
+	var rows [][]byte
+	for row := range rowChannel {
+		rows = append(rows, row.Columns[len(row.Columns)-1].GetBytes())
+	}
+
+	err = stub.DeleteTable(tableName)
+	if err != nil {
+		return errcode.Wrapf(err, "ReshapeTable(): DeleteTable of %s failed. %s", tableName, err)
+	}
+	err = InitLedger(stub, tableName)
+	if err != nil {
+		return err
+	}
+	Log(stub).Info("ReshapeTable(): Created table again", "table", tableName, "keys", GetNumberOfKeys(tableName), "rows", len(rows))
+
+	if primary == nil {
+		return nil
+	}
+
+	// The old keys were shorter, rows that collided under them were never written
+	for _, buff := range rows {
+		rec, err := primary.Decode(buff)
+		if err != nil {
+			return errcode.Wrapf(err, "ReshapeTable(): Cannot read a row of %s : %s", tableName, err)
+		}
+		err = UpdateLedger(stub, tableName, primary.Keys(rec), buff)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Read a row by its full key without the RecType check of QueryLedger
+// The bool is false if there is no such row
+////////////////////////////////////////////////////////////////////////////
+func LookupLedger(stub shim.ChaincodeStubInterface, tableName string, keys []string) ([]byte, bool, error) {
+
+	var columns []shim.Column
+	nCol := GetNumberOfKeys(tableName)
+	if len(keys) != nCol {
+		return nil, false, errcode.Errorf(errcode.Internal, "LookupLedger(): %s expects %d keys", tableName, nCol)
+	}
+
+	for i := 0; i < nCol; i++ {
+		colNext := shim.Column{Value: &shim.Column_String_{String_: keys[i]}}
+		columns = append(columns, colNext)
+	}
+
+	row, err := stub.GetRow(tableName, columns)
+	if err != nil {
+		return nil, false, errcode.Wrapf(err, "LookupLedger operation failed. %s", err)
+	}
+
+	if len(row.Columns) == 0 {
+		return nil, false, nil
+	}
+	return row.Columns[nCol].GetBytes(), true, nil
+}
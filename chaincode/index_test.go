@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+// Tables as the first chaincode created them, with fewer key columns
+func TestReshapeTable(t *testing.T) {
+	stub := newMemStub()
+	stub.seed(t, "BidTable", []string{"1000"}, Bid{ContractId: "1000", RecType: "BID", BidNo: "1", UserID: "200", BidPrice: "4500"})
+	stub.seed(t, "BidTable", []string{"1001"}, Bid{ContractId: "1001", RecType: "BID", BidNo: "3", UserID: "300", BidPrice: "900"})
+	stub.seed(t, "ContractCatTable", []string{"2016", "IT"}, ContractObject{ContractId: "1000", RecType: "CREATECONTR", Type: "IT"})
+
+	stub.initLedger(t)
+
+	// A second bid on a contract needs the BidNo key
+	second := Bid{ContractId: "1000", RecType: "BID", BidNo: "2", UserID: "300", BidPrice: "4000"}
+	if _, err := InsertRecord(stub, "BID", second); err == nil {
+		t.Fatal("InsertRecord() into the old BidTable succeeded")
+	}
+
+	for _, table := range []string{"BidTable", "ContractCatTable"} {
+		if err := ReshapeTable(stub, table); err != nil {
+			t.Fatalf("ReshapeTable(%s) error = %v", table, err)
+		}
+		if n, _ := LedgerKeyCount(stub, table); n != GetNumberOfKeys(table) {
+			t.Errorf("%s has %d keys, want %d", table, n, GetNumberOfKeys(table))
+		}
+	}
+
+	// Bids are kept under their new keys
+	for _, want := range []Bid{{ContractId: "1000", BidNo: "1", UserID: "200"}, {ContractId: "1001", BidNo: "3", UserID: "300"}} {
+		bid, err := GetBidObject(stub, want.ContractId, want.BidNo)
+		if err != nil || bid.UserID != want.UserID {
+			t.Errorf("GetBidObject(%s, %s) = %+v, %v", want.ContractId, want.BidNo, bid, err)
+		}
+	}
+	if _, err := InsertRecord(stub, "BID", second); err != nil {
+		t.Errorf("InsertRecord() of a second bid error = %v", err)
+	}
+
+	// Index tables come back empty for RebuildIndexes
+	if rows, err := GetList(stub, "ContractCatTable", []string{"2016"}); err != nil || len(rows) != 0 {
+		t.Errorf("ContractCatTable rows = %d, %v, want 0", len(rows), err)
+	}
+
+	if err := ReshapeTable(stub, "NoSuchTable"); err == nil {
+		t.Error("ReshapeTable() of a table without records succeeded")
+	}
+}
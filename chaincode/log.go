@@ -0,0 +1,124 @@
+package main
+
+import (
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/logging"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"os"
+	"strings"
+	"sync"
+)
+
+//////////////////////////////////////////////////////////////////////////////////////////
+// Logging
+// The level is INFO unless set by the CHAINCODE_LOG_LEVEL environment variable of the
+// chaincode container, and can be changed by deploying with a logLevel argument
+// ./peer chaincode deploy -l golang -n mycc -c '{"Function": "init", "Args": ["logLevel=DEBUG"]}'
+//
+// Log(stub) tags every line with the function and txID of the transaction, add
+// contractId and userId where they are known. Secret fields are redacted, see logging.Secrets
+//////////////////////////////////////////////////////////////////////////////////////////
+
+const LogLevelEnv = "CHAINCODE_LOG_LEVEL"
+
+var logger = logging.New(os.Stdout, logging.Info)
+
+// Function name of every transaction in progress, by txID
+var (
+	txMu        sync.Mutex
+	txFunctions = map[string]string{}
+)
+
+func Log(stub shim.ChaincodeStubInterface) *logging.Logger {
+	if function := TxFunction(stub); function != "" {
+		return logger.With("function", function, "txID", stub.GetTxID())
+	}
+	return logger.With("txID", stub.GetTxID())
+}
+
+// Function of the transaction, empty outside of BeginTx
+func TxFunction(stub shim.ChaincodeStubInterface) string {
+	txMu.Lock()
+	defer txMu.Unlock()
+	return txFunctions[stub.GetTxID()]
+}
+
+//////////////////////////////////////////////////////////////
+// Record the function of a transaction for Log
+// The returned func ends the transaction
+//////////////////////////////////////////////////////////////
+
+func BeginTx(stub shim.ChaincodeStubInterface, function string) func() {
+	txID := stub.GetTxID()
+	txMu.Lock()
+	txFunctions[txID] = function
+	txMu.Unlock()
+	return func() {
+		txMu.Lock()
+		delete(txFunctions, txID)
+		txMu.Unlock()
+	}
+}
+
+//////////////////////////////////////////////////////////////
+// Log the outcome of an Invoke or Query
+// Rejected requests are logged as WARNING, failures as ERROR
+//////////////////////////////////////////////////////////////
+
+func LogResult(stub shim.ChaincodeStubInterface, caller string, success logging.Level, err error) {
+	log := Log(stub)
+	if err == nil {
+		if success == logging.Debug {
+			log.Debug(caller + " : Done")
+		} else {
+			log.Info(caller + " : Done")
+		}
+		return
+	}
+
+	e := errcode.From(err)
+	kv := []interface{}{"code", e.Code, "error", e.Message}
+	if e.Field != "" {
+		kv = append(kv, "field", e.Field)
+	}
+	if e.Class == errcode.Failure {
+		log.Error(caller+" : Failed", kv...)
+	} else {
+		log.Warning(caller+" : Rejected", kv...)
+	}
+}
+
+//////////////////////////////////////////////////////////////
+// Set the log level from the environment, if set
+//////////////////////////////////////////////////////////////
+
+func InitLogLevel() {
+	value := os.Getenv(LogLevelEnv)
+	if value == "" {
+		return
+	}
+	level, err := logging.ParseLevel(value)
+	if err != nil {
+		logger.Warning("InitLogLevel(): Ignoring "+LogLevelEnv, "error", err)
+		return
+	}
+	logger.SetLevel(level)
+}
+
+//////////////////////////////////////////////////////////////
+// Set the log level from a logLevel=LEVEL argument of Init
+//////////////////////////////////////////////////////////////
+
+func SetLogLevel(args []string) error {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "logLevel=") {
+			continue
+		}
+		level, err := logging.ParseLevel(strings.TrimPrefix(arg, "logLevel="))
+		if err != nil {
+			return errcode.New(errcode.InvalidArgument, "SetLogLevel(): "+err.Error()).WithField("logLevel")
+		}
+		logger.SetLevel(level)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// memStub keeps the tables of the shim in memory for tests. Rows are keyed
+// like the shim keys them, see EncodeKey, and InsertRow rejects rows whose
+// column count differs from the table like the shim does. Stub methods the
+// tests do not need are left to the embedded nil interface.
+type memStub struct {
+	shim.ChaincodeStubInterface
+	txID   string
+	tables map[string]*memTable
+	state  map[string][]byte
+	events []string
+}
+
+type memTable struct {
+	defs []*shim.ColumnDefinition
+	rows map[string]shim.Row
+}
+
+func newMemStub() *memStub {
+	return &memStub{txID: "tx1", tables: map[string]*memTable{}, state: map[string][]byte{}}
+}
+
+func (s *memStub) GetTxID() string { return s.txID }
+
+func (s *memStub) GetCallerCertificate() ([]byte, error) { return nil, nil }
+
+func (s *memStub) SetEvent(name string, payload []byte) error {
+	s.events = append(s.events, name)
+	return nil
+}
+
+func (s *memStub) GetState(key string) ([]byte, error) { return s.state[key], nil }
+
+func (s *memStub) PutState(key string, value []byte) error {
+	s.state[key] = value
+	return nil
+}
+
+func (s *memStub) DelState(key string) error {
+	delete(s.state, key)
+	return nil
+}
+
+func (s *memStub) CreateTable(name string, defs []*shim.ColumnDefinition) error {
+	if _, ok := s.tables[name]; ok {
+		return errors.New("CreateTable operation failed. Table " + name + " already exists.")
+	}
+	s.tables[name] = &memTable{defs: defs, rows: map[string]shim.Row{}}
+	return nil
+}
+
+func (s *memStub) GetTable(name string) (*shim.Table, error) {
+	t, ok := s.tables[name]
+	if !ok {
+		return nil, shim.ErrTableNotFound
+	}
+	return &shim.Table{Name: name, ColumnDefinitions: t.defs}, nil
+}
+
+func (s *memStub) DeleteTable(name string) error {
+	delete(s.tables, name)
+	return nil
+}
+
+func (s *memStub) table(name string) (*memTable, error) {
+	t, ok := s.tables[name]
+	if !ok {
+		return nil, shim.ErrTableNotFound
+	}
+	return t, nil
+}
+
+func (t *memTable) nKeys() int {
+	n := 0
+	for _, d := range t.defs {
+		if d.Key {
+			n++
+		}
+	}
+	return n
+}
+
+func columnKey(cols []shim.Column) string {
+	keys := make([]string, len(cols))
+	for i := range cols {
+		keys[i] = cols[i].GetString_()
+	}
+	return EncodeKey(keys)
+}
+
+func (s *memStub) rowKey(t *memTable, row shim.Row) (string, error) {
+	if len(row.Columns) != len(t.defs) {
+		return "", errors.New("Invalid row: wrong number of columns")
+	}
+	cols := make([]shim.Column, t.nKeys())
+	for i := range cols {
+		cols[i] = *row.Columns[i]
+	}
+	return columnKey(cols), nil
+}
+
+func (s *memStub) writeRow(name string, row shim.Row, replace bool) (bool, error) {
+	t, err := s.table(name)
+	if err != nil {
+		return false, err
+	}
+	key, err := s.rowKey(t, row)
+	if err != nil {
+		return false, err
+	}
+	if _, exists := t.rows[key]; exists != replace {
+		return false, nil
+	}
+	t.rows[key] = row
+	return true, nil
+}
+
+func (s *memStub) InsertRow(name string, row shim.Row) (bool, error) {
+	return s.writeRow(name, row, false)
+}
+
+func (s *memStub) ReplaceRow(name string, row shim.Row) (bool, error) {
+	return s.writeRow(name, row, true)
+}
+
+func (s *memStub) GetRow(name string, key []shim.Column) (shim.Row, error) {
+	t, err := s.table(name)
+	if err != nil {
+		return shim.Row{}, err
+	}
+	return t.rows[columnKey(key)], nil
+}
+
+func (s *memStub) GetRows(name string, key []shim.Column) (<-chan shim.Row, error) {
+	t, err := s.table(name)
+	if err != nil {
+		return nil, err
+	}
+	prefix := columnKey(key)
+	var keys []string
+	for k := range t.rows {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	ch := make(chan shim.Row, len(keys))
+	for _, k := range keys {
+		ch <- t.rows[k]
+	}
+	close(ch)
+	return ch, nil
+}
+
+func (s *memStub) DeleteRow(name string, key []shim.Column) error {
+	t, err := s.table(name)
+	if err != nil {
+		return err
+	}
+	delete(t.rows, columnKey(key))
+	return nil
+}
+
+// seed writes a row of rec under keys into a table of len(keys) key
+// columns, creating the table on first use like an older chaincode did.
+func (s *memStub) seed(t *testing.T, table string, keys []string, rec interface{}) {
+	t.Helper()
+	if _, ok := s.tables[table]; !ok {
+		var defs []*shim.ColumnDefinition
+		for range keys {
+			defs = append(defs, &shim.ColumnDefinition{Type: shim.ColumnDefinition_STRING, Key: true})
+		}
+		defs = append(defs, &shim.ColumnDefinition{Name: "Details", Type: shim.ColumnDefinition_BYTES})
+		s.tables[table] = &memTable{defs: defs, rows: map[string]shim.Row{}}
+	}
+
+	buff, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cols []*shim.Column
+	for _, k := range keys {
+		cols = append(cols, &shim.Column{Value: &shim.Column_String_{String_: k}})
+	}
+	cols = append(cols, &shim.Column{Value: &shim.Column_Bytes{Bytes: buff}})
+	if ok, err := s.InsertRow(table, shim.Row{Columns: cols}); !ok || err != nil {
+		t.Fatalf("seed %s %v: %v, %v", table, keys, ok, err)
+	}
+}
+
+// initLedger creates every table of the chaincode not seeded yet.
+func (s *memStub) initLedger(t *testing.T) {
+	t.Helper()
+	for _, table := range aucTables {
+		if _, ok := s.tables[table]; ok {
+			continue
+		}
+		if err := InitLedger(s, table); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/events"
+	"github.com/AkshayKulkarni03/hackathon/model"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// Negotiation on a Bid
+// The bid itself is the opening offer of the bidder. The owner of the contract
+// answers it and the two parties take turns until one of them accepts or rejects
+// Every step is an Offer in NegotiationTable, ordered by Seq
+// SelectBidder only awards a bid whose negotiation ended with ACCEPT
+//
+// Role   : OWNER / BIDDER
+// Action : COUNTER / ACCEPT / REJECT
+///////////////////////////////////////////////////////////////////////////////////////
+
+type Offer model.Offer
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Counter the last offer on a bid with a new price, duration and terms
+// Empty duration or terms keep the values of the offer being countered
+// Example
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "CounterOffer", "Args":["1000", "OFFER", "1", "100", "900", "20", ""]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func CounterOffer(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 7 {
+		Log(stub).Debug("CounterOffer(): Incorrect number of arguments. Expecting 7")
+		return nil, errcode.New(errcode.ArgCount, "CounterOffer(): Incorrect number of arguments. Expecting 7 ")
+	}
+
+	if _, err := strconv.Atoi(args[4]); err != nil {
+		return nil, errcode.New(errcode.InvalidArgument, "CounterOffer(): Price should be an integer").WithField("Price")
+	}
+
+	offer, err := NextOffer(stub, args[0], args[2], args[3], "COUNTER")
+	if err != nil {
+		return nil, err
+	}
+
+	offer.Price = args[4]
+	if args[5] != "" {
+		offer.Duration = args[5]
+	}
+	if args[6] != "" {
+		offer.Terms = args[6]
+	}
+
+	buff, err := PostOffer(stub, offer)
+	if err != nil {
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.OfferMade, offer.UserID, OfferEventOf(offer))
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Accept the last offer on a bid - the accepted offer can then be awarded with SelectBidder
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "AcceptOffer", "Args":["1000", "OFFER", "1", "200"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func AcceptOffer(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 4 {
+		Log(stub).Debug("AcceptOffer(): Incorrect number of arguments. Expecting 4")
+		return nil, errcode.New(errcode.ArgCount, "AcceptOffer(): Incorrect number of arguments. Expecting 4 ")
+	}
+
+	offer, err := NextOffer(stub, args[0], args[2], args[3], "ACCEPT")
+	if err != nil {
+		return nil, err
+	}
+
+	buff, err := PostOffer(stub, offer)
+	if err != nil {
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.OfferMade, offer.UserID, OfferEventOf(offer))
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Reject the last offer on a bid, which ends the negotiation
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "RejectOffer", "Args":["1000", "OFFER", "1", "200"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func RejectOffer(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 4 {
+		Log(stub).Debug("RejectOffer(): Incorrect number of arguments. Expecting 4")
+		return nil, errcode.New(errcode.ArgCount, "RejectOffer(): Incorrect number of arguments. Expecting 4 ")
+	}
+
+	offer, err := NextOffer(stub, args[0], args[2], args[3], "REJECT")
+	if err != nil {
+		return nil, err
+	}
+
+	buff, err := PostOffer(stub, offer)
+	if err != nil {
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.OfferMade, offer.UserID, OfferEventOf(offer))
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Build the next step of a negotiation for userId
+// Checks that the contract is OPEN, that userId is the owner or the bidder,
+// that it is their turn and that the negotiation has not ended
+// The new step starts as a copy of the offer it answers
+////////////////////////////////////////////////////////////////////////////
+func NextOffer(stub shim.ChaincodeStubInterface, contractId string, bidNo string, userId string, action string) (Offer, error) {
+
+	contract, err := GetContractObject(stub, contractId)
+	if err != nil {
+		return Offer{}, err
+	}
+
+	if contract.Status != "OPEN" {
+		return Offer{}, errcode.New(errcode.InvalidState, "NextOffer(): Cannot negotiate as Contract is not OPEN : " + contractId)
+	}
+
+	bid, err := GetBidObject(stub, contractId, bidNo)
+	if err != nil {
+		return Offer{}, err
+	}
+
+	var role string
+	switch userId {
+	case contract.UserID:
+		role = "OWNER"
+	case bid.UserID:
+		role = "BIDDER"
+	default:
+		return Offer{}, errcode.New(errcode.NotAllowed, "NextOffer(): User is not the owner or the bidder : " + userId)
+	}
+
+	thread, err := GetOfferList(stub, contractId, bidNo)
+	if err != nil {
+		return Offer{}, err
+	}
+
+	// The bid is the opening offer of the bidder
+	last := Offer{ContractId: contractId, RecType: "OFFER", BidNo: bidNo, Seq: "0", UserID: bid.UserID, Role: "BIDDER",
+		Action: "COUNTER", Price: bid.BidPrice, Duration: contract.Duration, Terms: contract.Terms, OfferTime: bid.BidTime}
+	if len(thread) > 0 {
+		last = thread[len(thread)-1]
+	}
+
+	if last.Action != "COUNTER" {
+		return Offer{}, errcode.New(errcode.InvalidState, "NextOffer(): Negotiation has already ended with " + last.Action)
+	}
+
+	if last.Role == role {
+		return Offer{}, errcode.New(errcode.InvalidState, "NextOffer(): Waiting for the other party to answer the last offer")
+	}
+
+	seq, _ := strconv.Atoi(last.Seq)
+	next := last
+	next.Seq = strconv.Itoa(seq + 1)
+	next.UserID = userId
+	next.Role = role
+	next.Action = action
+	next.OfferTime = time.Now().Format("2006-01-02 15:04:05")
+
+	return next, nil
+}
+
+func PostOffer(stub shim.ChaincodeStubInterface, offer Offer) ([]byte, error) {
+
+	buff, err := OffertoJSON(offer)
+	if err != nil {
+		Log(stub).Error("PostOffer(): Failed Cannot create object buffer for write", "contractId", offer.ContractId)
+		return nil, errcode.New(errcode.Internal, "PostOffer(): Failed Cannot create object buffer for write : " + offer.ContractId)
+	}
+
+	seq, _ := strconv.Atoi(offer.Seq)
+	keys := []string{offer.ContractId, offer.BidNo, fmt.Sprintf("%04d", seq)}
+	err = UpdateLedger(stub, "NegotiationTable", keys, buff)
+	if err != nil {
+		Log(stub).Error("PostOffer(): write error while inserting record")
+		return nil, err
+	}
+
+	return buff, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Returns the accepted offer on a bid or nil if the bid was not accepted
+////////////////////////////////////////////////////////////////////////////
+func GetAcceptedOffer(stub shim.ChaincodeStubInterface, contractId string, bidNo string) (*Offer, error) {
+
+	thread, err := GetOfferList(stub, contractId, bidNo)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(thread) == 0 || thread[len(thread)-1].Action != "ACCEPT" {
+		return nil, nil
+	}
+	return &thread[len(thread)-1], nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////
+// Get the negotiation thread of a bid in order
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetNegotiation", "Args": ["1000", "1"]}'
+// Optional page size and continuation token follow, see GetPage
+/////////////////////////////////////////////////////////////////////////////////////////
+func GetNegotiation(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) < 2 {
+		Log(stub).Debug("GetNegotiation(): Incorrect number of arguments. Expecting 2")
+		return nil, errcode.New(errcode.ArgCount, "GetNegotiation(): Incorrect number of arguments. Expecting 2 ")
+	}
+
+	pageSize, token, err := PageArgs(args, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, next, err := GetPage(stub, "NegotiationTable", args[:2], pageSize, token)
+	if err != nil {
+		return nil, errcode.Wrapf(err, "GetNegotiation() operation failed. Error GetPage: %s", err)
+	}
+
+	thread, err := RowstoOffers(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return PagetoJSON(thread, next)
+}
+
+func GetOfferList(stub shim.ChaincodeStubInterface, contractId string, bidNo string) ([]Offer, error) {
+
+	rows, err := GetList(stub, "NegotiationTable", []string{contractId, bidNo})
+	if err != nil {
+		return nil, errcode.Wrapf(err, "GetOfferList() operation failed. Error GetList: %s", err)
+	}
+
+	return RowstoOffers(rows)
+}
+
+func RowstoOffers(rows []shim.Row) ([]Offer, error) {
+
+	nCol := GetNumberOfKeys("NegotiationTable")
+
+	// Seq keys are zero padded so rows come back in order
+	tlist := make([]Offer, len(rows))
+	for i := 0; i < len(rows); i++ {
+		ts := rows[i].Columns[nCol].GetBytes()
+		offer, err := JSONtoOffer(ts)
+		if err != nil {
+			logger.Error("RowstoOffers(): Unmarshal error", "error", err)
+			return nil, errcode.Wrapf(err, "RowstoOffers() operation failed. %s", err)
+		}
+		tlist[i] = offer
+	}
+
+	return tlist, nil
+}
+
+//////////////////////////////////////////////////////////
+// Converts an Offer to a JSON String
+//////////////////////////////////////////////////////////
+func OffertoJSON(offer Offer) ([]byte, error) {
+
+	ajson, err := json.Marshal(offer)
+	if err != nil {
+		logger.Error("OffertoJSON(): error", "error", err)
+		return nil, err
+	}
+	return ajson, nil
+}
+
+//////////////////////////////////////////////////////////
+// Converts a JSON String to an Offer
+//////////////////////////////////////////////////////////
+func JSONtoOffer(areq []byte) (Offer, error) {
+
+	offer := Offer{}
+	err := json.Unmarshal(areq, &offer)
+	if err != nil {
+		logger.Error("JSONtoOffer(): error", "error", err)
+		return offer, err
+	}
+	return offer, err
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// Pagination of list queries
+// Every list query takes two optional arguments after its own arguments
+//   page size          - number of items, DefaultPageSize if empty, at most MaxPageSize
+//   continuation token - nextToken of the previous page, empty for the first page
+// and returns a Page. nextToken is empty on the last page
+// Example
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetListOfBids", "Args": ["1111", "20", ""]}'
+///////////////////////////////////////////////////////////////////////////////////////
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+type Page struct {
+	Items     interface{} `json:"items"`
+	NextToken string      `json:"nextToken"`
+}
+
+// Content of a continuation token - the table and keys of the last row returned
+// For lists built in memory the table is empty and Keys holds the offset
+type pageToken struct {
+	T string
+	K []string
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Read the page size and continuation token that follow the first n args
+////////////////////////////////////////////////////////////////////////////
+func PageArgs(args []string, n int) (int, string, error) {
+
+	pageSize := DefaultPageSize
+	if len(args) > n && args[n] != "" {
+		size, err := strconv.Atoi(args[n])
+		if err != nil || size < 1 || size > MaxPageSize {
+			return 0, "", errcode.Errorf(errcode.InvalidArgument, "PageArgs(): Page size should be an integer between 1 and %d", MaxPageSize).WithField("pageSize")
+		}
+		pageSize = size
+	}
+
+	token := ""
+	if len(args) > n+1 {
+		token = args[n+1]
+	}
+	return pageSize, token, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Get one page of the rows matching the partial key
+// Rows come back from GetRows in key order, the token holds the keys of the
+// last row of the previous page and rows up to it are skipped
+// Only the rows of the page are kept in memory, but every row up to the end of
+// the page is read as GetRows cannot start at a key - reading all N rows of a
+// partition page by page reads O(N*N/pageSize) rows. Use the largest page size
+// the client can take and the narrowest partial key
+////////////////////////////////////////////////////////////////////////////
+func GetPage(stub shim.ChaincodeStubInterface, tableName string, args []string, pageSize int, token string) ([]shim.Row, string, error) {
+
+	var columns []shim.Column
+
+	nKeys := GetNumberOfKeys(tableName)
+	if len(args) < 1 {
+		Log(stub).Error("GetPage(): At least 1 Key must be provided")
+		return nil, "", errcode.New(errcode.Internal, "GetPage failed. Must include at least key values")
+	}
+
+	var after []string
+	if token != "" {
+		t, err := DecodePageToken(token)
+		if err != nil || t.T != tableName || len(t.K) != nKeys {
+			return nil, "", errcode.New(errcode.InvalidArgument, "GetPage(): Invalid continuation token").WithField("token")
+		}
+		for i := range args {
+			if t.K[i] != args[i] {
+				return nil, "", errcode.New(errcode.InvalidArgument, "GetPage(): Continuation token does not belong to this query").WithField("token")
+			}
+		}
+		after = t.K
+	}
+
+	for i := 0; i < len(args); i++ {
+		colNext := shim.Column{Value: &shim.Column_String_{String_: args[i]}}
+		columns = append(columns, colNext)
+	}
+
+	rowChannel, err := stub.GetRows(tableName, columns)
+	if err != nil {
+		return nil, "", errcode.Wrapf(err, "GetPage operation failed. %s", err)
+	}
+
+	var rows []shim.Row
+	more := false
+	for row := range rowChannel {
+		// Keep reading once the page is full so the channel is drained
+		if more {
+			continue
+		}
+		if after != nil && CompareKeys(RowKeys(row, nKeys), after) <= 0 {
+			continue
+		}
+		if len(rows) == pageSize {
+			more = true
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	next := ""
+	if more {
+		next = EncodePageToken(pageToken{tableName, RowKeys(rows[len(rows)-1], nKeys)})
+	}
+
+	Log(stub).Debug("GetPage(): rows retrieved from", "table", tableName, "count", len(rows))
+	return rows, next, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Page through a list of n items that was built in memory
+// Returns the bounds of the page and the token of the next page
+////////////////////////////////////////////////////////////////////////////
+func PageBounds(n int, pageSize int, token string) (int, int, string, error) {
+
+	start := 0
+	if token != "" {
+		t, err := DecodePageToken(token)
+		if err != nil || t.T != "" || len(t.K) != 1 {
+			return 0, 0, "", errcode.New(errcode.InvalidArgument, "PageBounds(): Invalid continuation token").WithField("token")
+		}
+		start, err = strconv.Atoi(t.K[0])
+		if err != nil || start < 0 {
+			return 0, 0, "", errcode.New(errcode.InvalidArgument, "PageBounds(): Invalid continuation token").WithField("token")
+		}
+	}
+
+	if start > n {
+		start = n
+	}
+	end := start + pageSize
+	if end >= n {
+		return start, n, "", nil
+	}
+	return start, end, EncodePageToken(pageToken{"", []string{strconv.Itoa(end)}}), nil
+}
+
+func PagetoJSON(items interface{}, next string) ([]byte, error) {
+
+	ajson, err := json.Marshal(Page{items, next})
+	if err != nil {
+		logger.Error("PagetoJSON(): error", "error", err)
+		return nil, err
+	}
+	return ajson, nil
+}
+
+//////////////////////////////////////////////////////////
+// The string keys of a row
+//////////////////////////////////////////////////////////
+func RowKeys(row shim.Row, nKeys int) []string {
+
+	keys := make([]string, nKeys)
+	for i := 0; i < nKeys; i++ {
+		keys[i] = row.Columns[i].GetString_()
+	}
+	return keys
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Compare two keys in the order of the ledger
+// The shim stores the key of a row as its columns, each written as its
+// length in decimal followed by its value, and rows come back in the byte
+// order of that string, so "10..." (length 10) sorts before "9..." (length 9)
+////////////////////////////////////////////////////////////////////////////
+func CompareKeys(a []string, b []string) int {
+
+	return strings.Compare(EncodeKey(a), EncodeKey(b))
+}
+
+//////////////////////////////////////////////////////////
+// Key of a row as the shim stores it
+//////////////////////////////////////////////////////////
+func EncodeKey(keys []string) string {
+
+	var buf bytes.Buffer
+	for _, k := range keys {
+		buf.WriteString(strconv.Itoa(len(k)))
+		buf.WriteString(k)
+	}
+	return buf.String()
+}
+
+func EncodePageToken(t pageToken) string {
+
+	ajson, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(ajson)
+}
+
+func DecodePageToken(token string) (pageToken, error) {
+
+	var t pageToken
+	ajson, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return t, err
+	}
+	err = json.Unmarshal(ajson, &t)
+	return t, err
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestCompareKeys(t *testing.T) {
+	tests := []struct {
+		a, b []string
+		want int
+	}{
+		{[]string{"1000"}, []string{"1000"}, 0},
+		{[]string{"1000"}, []string{"1001"}, -1},
+		{[]string{"2"}, []string{"10"}, -1},                 // "12" < "210"
+		{[]string{"1000000001"}, []string{"999999999"}, -1}, // "101000000001" < "9999999999"
+		{[]string{"1000", "1000000000"}, []string{"1000", "999999999"}, -1},
+		{[]string{"1000"}, []string{"1000", "1"}, -1}, // a partial key sorts before its rows
+		{[]string{"IT", "1000"}, []string{"ITX", "1"}, -1},
+		{[]string{""}, []string{"0"}, -1},
+	}
+	for _, tt := range tests {
+		if got := sign(CompareKeys(tt.a, tt.b)); got != tt.want {
+			t.Errorf("CompareKeys(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := sign(CompareKeys(tt.b, tt.a)); got != -tt.want {
+			t.Errorf("CompareKeys(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+// The order of CompareKeys is the byte order of the keys as the shim
+// stores them, which is the order GetRows returns rows in.
+func TestCompareKeysLedgerOrder(t *testing.T) {
+	keys := [][]string{{"9", "1"}, {"10", "1"}, {"999999999", "2"}, {"1000000000", "1"}, {"10", "02"}, {"100", "1"}}
+	sort.Slice(keys, func(i, j int) bool { return CompareKeys(keys[i], keys[j]) < 0 })
+	for i := 1; i < len(keys); i++ {
+		if EncodeKey(keys[i-1]) >= EncodeKey(keys[i]) {
+			t.Errorf("%q sorted before %q", keys[i-1], keys[i])
+		}
+	}
+	// The 10 digit value comes first as "10" < "9"
+	if keys[0][0] != "1000000000" {
+		t.Errorf("first key %q, want 1000000000", keys[0])
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/events"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// Contract partitions
+// GetRows needs a leading key, contracts used to be filed under the dummy key 2016
+// They are now filed under
+//   ContractCatTable    - ContractNamespace, Type, ContractId
+//   ContractOpenTable   - ContractNamespace, ContractId       (OPEN contracts only)
+//   ContractPeriodTable - year, month of CreationDate, ContractId
+// so a contract list can scan all contracts, one type or one period
+// MigrateContractKeys moves rows written under 2016 into this scheme
+///////////////////////////////////////////////////////////////////////////////////////
+
+const (
+	ContractNamespace = "CONTRACT"
+	legacyContractKey = "2016"
+)
+
+/////////////////////////////////////////////////////////////////////////////////////////
+// List contracts
+// Scope ALL     - every contract, by type
+// Scope TYPE    - contracts of one Type
+// Scope PERIOD  - contracts created in a year (2016) or a month (2016-10)
+// Optional page size and continuation token follow, see GetPage
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "ListContracts", "Args": ["ALL", ""]}'
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "ListContracts", "Args": ["TYPE", "Painting", "20", "<nextToken>"]}'
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "ListContracts", "Args": ["PERIOD", "2016-10"]}'
+/////////////////////////////////////////////////////////////////////////////////////////
+func ListContracts(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) < 2 {
+		Log(stub).Debug("ListContracts(): Incorrect number of arguments. Expecting 2")
+		return nil, errcode.New(errcode.ArgCount, "ListContracts(): Incorrect number of arguments. Expecting 2 ")
+	}
+
+	pageSize, token, err := PageArgs(args, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	var table string
+	var keys []string
+	switch args[0] {
+	case "ALL":
+		table, keys = "ContractCatTable", []string{ContractNamespace}
+	case "TYPE":
+		table, keys = "ContractCatTable", []string{ContractNamespace, args[1]}
+	case "PERIOD":
+		table, keys = "ContractPeriodTable", strings.SplitN(args[1], "-", 2)
+		if len(keys[0]) != 4 || (len(keys) == 2 && len(keys[1]) != 2) {
+			return nil, errcode.New(errcode.InvalidArgument, "ListContracts(): Period should be a year or a month like 2016 or 2016-10 : " + args[1]).WithField("value")
+		}
+	default:
+		return nil, errcode.New(errcode.InvalidArgument, "ListContracts(): Scope should be ALL, TYPE or PERIOD : " + args[0]).WithField("scope")
+	}
+
+	rows, next, err := GetPage(stub, table, keys, pageSize, token)
+	if err != nil {
+		return nil, errcode.Wrapf(err, "ListContracts() operation failed. Error GetPage: %s", err)
+	}
+
+	nCol := GetNumberOfKeys(table)
+
+	tlist := make([]ContractObject, len(rows))
+	for i := 0; i < len(rows); i++ {
+		c, err := JSONtoAucReq(rows[i].Columns[nCol].GetBytes())
+		if err != nil {
+			Log(stub).Error("ListContracts(): Unmarshal error", "error", err)
+			return nil, errcode.Wrapf(err, "ListContracts() operation failed. %s", err)
+		}
+		tlist[i] = c
+	}
+
+	return PagetoJSON(tlist, next)
+}
+
+//////////////////////////////////////////////////////////
+// Year and month partition keys of a CreationDate
+//////////////////////////////////////////////////////////
+func ContractPeriod(date string) []string {
+
+	day := ContractDay(date)
+	return []string{day[:4], day[5:7]}
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Move contracts filed under the 2016 key into the partition scheme
+// Creates tables missing from an older ledger and creates tables whose keys changed
+// again, see ReshapeTable. Deletes the 2016 rows and rebuilds every index of every
+// contract. The counters of StatsTable are recounted
+// as the rebuilt indexes do not run the OnSave hooks. Can be run again safely
+// Only Auction House (AH) users can run the migration
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "MigrateContractKeys", "Args":["CREATECONTR", "100"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func MigrateContractKeys(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 2 {
+		Log(stub).Debug("MigrateContractKeys(): Incorrect number of arguments. Expecting 2")
+		return nil, errcode.New(errcode.ArgCount, "MigrateContractKeys(): Incorrect number of arguments. Expecting 2 ")
+	}
+
+	userBytes, err := ValidateMember(stub, args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := JSONtoUser(userBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.UserType != "AH" {
+		Log(stub).Debug("MigrateContractKeys(): Only Auction House users can migrate the ledger", "userId", args[1])
+		return nil, errcode.New(errcode.NotAllowed, "MigrateContractKeys(): Only Auction House users can migrate the ledger : " + args[1])
+	}
+
+	// CreateTable fails on a table that exists, only create the missing ones
+	// and the ones created with other keys, see ReshapeTable
+	for _, val := range aucTables {
+		nKeys, err := LedgerKeyCount(stub, val)
+		switch {
+		case err == shim.ErrTableNotFound:
+			Log(stub).Info("MigrateContractKeys(): Creating missing table", "table", val)
+			err = InitLedger(stub, val)
+		case err == nil && nKeys != GetNumberOfKeys(val):
+			Log(stub).Info("MigrateContractKeys(): Reshaping table", "table", val, "keys", nKeys)
+			err = ReshapeTable(stub, val)
+		}
+		if err != nil {
+			return nil, errcode.Wrapf(err, "MigrateContractKeys(): Cannot create table %s : %s", val, err)
+		}
+	}
+
+	// Rows left under 2016 in tables that kept their keys
+	for _, table := range []string{"ContractCatTable", "ContractOpenTable"} {
+		rows, err := GetList(stub, table, []string{legacyContractKey})
+		if err != nil {
+			return nil, errcode.Wrapf(err, "MigrateContractKeys() operation failed. Error GetList: %s", err)
+		}
+
+		for _, row := range rows {
+			err = DeleteFromLedger(stub, table, RowKeys(row, GetNumberOfKeys(table)))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// The 2016 rows of the first chaincode were keyed by Type alone, so only
+	// one contract of a Type was listed. Every contract of ContractTable is
+	// indexed again, the ContractId is read from the record
+	rowChannel, err := stub.GetRows("ContractTable", []shim.Column{})
+	if err != nil {
+		return nil, errcode.Wrapf(err, "MigrateContractKeys(): GetRows of ContractTable failed. %s", err)
+	}
+	var contracts []ContractObject
+	for row := range rowChannel {
+		c, err := JSONtoAucReq(row.Columns[GetNumberOfKeys("ContractTable")].GetBytes())
+		if err != nil {
+			return nil, errcode.Wrapf(err, "MigrateContractKeys(): Cannot read a contract : %s", err)
+		}
+		contracts = append(contracts, c)
+	}
+
+	ids := make([]string, len(contracts))
+	for i, c := range contracts {
+		err = RebuildIndexes(stub, "CONTRACT", c)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = c.ContractId
+	}
+
+	_, err = RecountStats(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	Log(stub).Info("MigrateContractKeys(): Migrated contracts", "count", len(ids))
+	buff, err := json.Marshal(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.ContractKeysMigrated, args[1], events.MigrationEvent{Contracts: ids})
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
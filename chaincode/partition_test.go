@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// A ledger written by the first chaincode: ContractCatTable keyed by 2016 and
+// Type, so only the first contract of a Type was listed, and BidTable keyed
+// by ContractId alone
+func baselineLedger(t *testing.T) *memStub {
+	stub := newMemStub()
+	stub.seed(t, "UserTable", []string{"900"}, UserObject{UserID: "900", RecType: "USER", UserType: "AH"})
+	stub.seed(t, "UserTable", []string{"100"}, UserObject{UserID: "100", RecType: "USER", UserType: "TR"})
+
+	contracts := []ContractObject{
+		{ContractId: "1000", Type: "IT", Amount: "5000", CreationDate: "2016-10-18 10:00:00", UserID: "100", Status: "OPEN", RecType: "CREATECONTR"},
+		{ContractId: "1001", Type: "IT", Amount: "800", CreationDate: "2016-11-02 09:30:00", UserID: "100", Status: "OPEN", RecType: "CREATECONTR"},
+		{ContractId: "1002", Type: "LEGAL", Amount: "1200", CreationDate: "2016-11-05 16:00:00", UserID: "100", Status: "OPEN", RecType: "CREATECONTR"},
+	}
+	for _, c := range contracts {
+		stub.seed(t, "ContractTable", []string{c.ContractId}, c)
+	}
+	stub.seed(t, "ContractCatTable", []string{legacyContractKey, "IT"}, contracts[0])
+	stub.seed(t, "ContractCatTable", []string{legacyContractKey, "LEGAL"}, contracts[2])
+	stub.seed(t, "BidTable", []string{"1000"}, Bid{ContractId: "1000", RecType: "BID", BidNo: "1", UserID: "200", BidPrice: "4500"})
+	return stub
+}
+
+func listContracts(t *testing.T, stub *memStub, args ...string) []string {
+	t.Helper()
+	buff, err := ListContracts(stub, "ListContracts", args)
+	if err != nil {
+		t.Fatalf("ListContracts(%v) error = %v", args, err)
+	}
+	var page struct{ Items []ContractObject }
+	if err := json.Unmarshal(buff, &page); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, c := range page.Items {
+		ids = append(ids, c.ContractId)
+	}
+	return ids
+}
+
+func TestMigrateContractKeys(t *testing.T) {
+	stub := baselineLedger(t)
+
+	if _, err := MigrateContractKeys(stub, "MigrateContractKeys", []string{"CREATECONTR", "100"}); err == nil {
+		t.Fatal("MigrateContractKeys() by a TR user succeeded")
+	}
+
+	// Running the migration again changes nothing
+	for run := 1; run <= 2; run++ {
+		buff, err := MigrateContractKeys(stub, "MigrateContractKeys", []string{"CREATECONTR", "900"})
+		if err != nil {
+			t.Fatalf("run %d: MigrateContractKeys() error = %v", run, err)
+		}
+		if string(buff) != `["1000","1001","1002"]` {
+			t.Errorf("run %d: migrated %s", run, buff)
+		}
+
+		if ids := listContracts(t, stub, "ALL", ""); len(ids) != 3 {
+			t.Errorf("run %d: ALL lists %v", run, ids)
+		}
+		if ids := listContracts(t, stub, "TYPE", "IT"); len(ids) != 2 || ids[0] != "1000" || ids[1] != "1001" {
+			t.Errorf("run %d: TYPE IT lists %v", run, ids)
+		}
+		if ids := listContracts(t, stub, "PERIOD", "2016-11"); len(ids) != 2 {
+			t.Errorf("run %d: PERIOD 2016-11 lists %v", run, ids)
+		}
+		if rows, _ := GetList(stub, "ContractCatTable", []string{legacyContractKey}); len(rows) != 0 {
+			t.Errorf("run %d: %d rows left under %s", run, len(rows), legacyContractKey)
+		}
+
+		if bid, err := GetBidObject(stub, "1000", "1"); err != nil || bid.UserID != "200" {
+			t.Errorf("run %d: GetBidObject() = %+v, %v", run, bid, err)
+		}
+
+		buff, err = GetMarketStats(stub, "GetMarketStats", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stats MarketStats
+		if err := json.Unmarshal(buff, &stats); err != nil {
+			t.Fatal(err)
+		}
+		if stats.Bids.Contracts != 3 || stats.Bids.Bids != 1 || stats.Contracts["IT"]["OPEN"] != 2 || stats.Contracts["LEGAL"]["OPEN"] != 1 {
+			t.Errorf("run %d: stats = %s", run, buff)
+		}
+	}
+
+	// New bids take the BidNo key
+	if _, err := InsertRecord(stub, "BID", Bid{ContractId: "1000", RecType: "BID", BidNo: "2", UserID: "300", BidPrice: "4000"}); err != nil {
+		t.Errorf("InsertRecord() of a second bid error = %v", err)
+	}
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/events"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// Recurring contracts
+// A contract with a recurrence rule is the first cycle of a series
+// Closing a cycle creates the next one with a derived ContractId, the incumbent
+// worker is kept so the next cycle starts IN_PROGRESS, with a copy of the selected bid
+// A cycle whose derived ContractId is taken by another contract is skipped
+// The owner can reopen a cycle to fresh bidding with ReopenCycle
+//
+// Recurrence : WEEKLY / MONTHLY / QUARTERLY / YEARLY
+// ContractId of cycle n : SeriesId followed by n as three digits - 1000 -> 1000002
+///////////////////////////////////////////////////////////////////////////////////////
+
+type SeriesEntry struct {
+	SeriesId   string
+	RecType    string // SERIES
+	Cycle      string
+	ContractId string
+}
+
+var recurrenceStep = map[string][3]int{
+	"WEEKLY":    {0, 0, 7},
+	"MONTHLY":   {0, 1, 0},
+	"QUARTERLY": {0, 3, 0},
+	"YEARLY":    {1, 0, 0},
+}
+
+const MaxCycles = 999
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Put a recurrence rule on a contract, which becomes cycle 1 of the series
+// Example - monthly for 12 cycles
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "SetRecurrence", "Args":["1000", "CREATECONTR", "100", "MONTHLY", "12"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func SetRecurrence(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 5 {
+		Log(stub).Debug("SetRecurrence(): Incorrect number of arguments. Expecting 5")
+		return nil, errcode.New(errcode.ArgCount, "SetRecurrence(): Incorrect number of arguments. Expecting 5 ")
+	}
+
+	if _, ok := recurrenceStep[args[3]]; !ok {
+		return nil, errcode.New(errcode.InvalidArgument, "SetRecurrence(): Recurrence should be one of WEEKLY, MONTHLY, QUARTERLY or YEARLY : " + args[3]).WithField("Recurrence")
+	}
+
+	cycles, err := strconv.Atoi(args[4])
+	if err != nil || cycles < 2 || cycles > MaxCycles {
+		return nil, errcode.Errorf(errcode.InvalidArgument, "SetRecurrence(): Cycles should be an integer between 2 and %d", MaxCycles).WithField("Cycles")
+	}
+
+	contract, err := GetContractObject(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if contract.UserID != args[2] {
+		Log(stub).Debug("SetRecurrence(): Only the owner of the contract can set a recurrence", "userId", args[2])
+		return nil, errcode.New(errcode.NotAllowed, "SetRecurrence(): Only the owner of the contract can set a recurrence : " + args[2])
+	}
+
+	if contract.Status != "OPEN" && contract.Status != "IN_PROGRESS" {
+		return nil, errcode.New(errcode.InvalidState, "SetRecurrence(): Contract is not OPEN or IN_PROGRESS : " + args[0])
+	}
+
+	// Cycles of a child contract would bypass the budget of the parent
+	if contract.ParentId != "" {
+		return nil, errcode.New(errcode.InvalidState, "SetRecurrence(): A child contract cannot recur : " + args[0])
+	}
+
+	if contract.SeriesId != "" {
+		return nil, errcode.New(errcode.InvalidState, "SetRecurrence(): Contract is already part of series : " + contract.SeriesId)
+	}
+
+	contract.SeriesId = contract.ContractId
+	contract.Cycle = "1"
+	contract.Recurrence = args[3]
+	contract.Cycles = args[4]
+
+	err = PostSeriesEntry(stub, contract)
+	if err != nil {
+		return nil, err
+	}
+
+	buff, err := UpdateContractStatus(stub, contract)
+	if err != nil {
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.ContractUpdated, args[2], ContractEventOf(contract))
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Create the next cycle of a series after a cycle has been closed
+// Nothing is done for contracts without recurrence or for the last cycle
+// Cycles whose ContractId is taken are skipped, the series ends when all are
+// Returns the ContractId of the new cycle, empty if none was created
+////////////////////////////////////////////////////////////////////////////
+func SpawnNextCycle(stub shim.ChaincodeStubInterface, contract ContractObject) (string, error) {
+
+	if contract.SeriesId == "" {
+		return "", nil
+	}
+
+	cycle, _ := strconv.Atoi(contract.Cycle)
+	cycles, _ := strconv.Atoi(contract.Cycles)
+	if cycle >= cycles {
+		Log(stub).Info("SpawnNextCycle(): Last cycle of series closed", "seriesId", contract.SeriesId)
+		return "", nil
+	}
+
+	// Cycles whose ContractId is taken by another contract are skipped
+	next := contract
+	for {
+		cycle++
+		if cycle > cycles {
+			Log(stub).Warning("SpawnNextCycle(): ContractIds of the remaining cycles already exist, series ends", "seriesId", contract.SeriesId)
+			return "", nil
+		}
+		next.ContractId = CycleContractId(contract.SeriesId, cycle)
+		_, found, err := LookupLedger(stub, "ContractTable", []string{next.ContractId})
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			break
+		}
+		Log(stub).Warning("SpawnNextCycle(): ContractId of cycle already exists, cycle skipped", "seriesId", contract.SeriesId, "contractId", next.ContractId)
+	}
+	next.Cycle = strconv.Itoa(cycle)
+	next.CreationDate = NextCycleDate(contract.CreationDate, contract.Recurrence)
+	next.Status = "OPEN"
+
+	if next.WorkerID != "" {
+		// The incumbent keeps the work unless the owner reopens the cycle
+		// The selected bid is carried over so payments and deliverables find it
+		// It was counted on the first cycle and is not counted again, see CountBid
+		bid, err := GetBidObject(stub, contract.ContractId, contract.BidNo)
+		if err != nil {
+			return "", err
+		}
+		bid.ContractId = next.ContractId
+		bid.CarriedFrom = contract.ContractId
+		_, err = InsertRecord(stub, "BID", bid)
+		if err != nil {
+			Log(stub).Error("SpawnNextCycle(): write error while inserting bid", "contractId", next.ContractId)
+			return "", err
+		}
+		next.Status = "IN_PROGRESS"
+	}
+
+	_, err := PostContract(stub, next)
+	if err != nil {
+		return "", err
+	}
+
+	Log(stub).Info("SpawnNextCycle(): Created cycle", "cycle", next.Cycle, "seriesId", next.SeriesId, "contractId", next.ContractId)
+	return next.ContractId, PostSeriesEntry(stub, next)
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// The owner reopens a cycle of a series to fresh bidding
+// The incumbent worker is removed and the contract is OPEN again
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "ReopenCycle", "Args":["1000002", "CREATECONTR", "100"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func ReopenCycle(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 3 {
+		Log(stub).Debug("ReopenCycle(): Incorrect number of arguments. Expecting 3")
+		return nil, errcode.New(errcode.ArgCount, "ReopenCycle(): Incorrect number of arguments. Expecting 3 ")
+	}
+
+	contract, err := GetContractObject(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if contract.UserID != args[2] {
+		Log(stub).Debug("ReopenCycle(): Only the owner of the contract can reopen it", "userId", args[2])
+		return nil, errcode.New(errcode.NotAllowed, "ReopenCycle(): Only the owner of the contract can reopen it : " + args[2])
+	}
+
+	if contract.SeriesId == "" {
+		return nil, errcode.New(errcode.InvalidState, "ReopenCycle(): Contract is not part of a series : " + args[0])
+	}
+
+	if contract.Status != "IN_PROGRESS" {
+		return nil, errcode.New(errcode.InvalidState, "ReopenCycle(): Only an IN_PROGRESS cycle can be reopened : " + args[0])
+	}
+
+	contract.WorkerID = ""
+	contract.BidNo = ""
+	contract.BidPrice = ""
+	contract.Status = "OPEN"
+
+	buff, err := UpdateContractStatus(stub, contract)
+	if err != nil {
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.CycleReopened, args[2], ContractEventOf(contract))
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////
+// List all the cycles of a series with their current status
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetContractSeries", "Args": ["1000"]}'
+// Optional page size and continuation token follow, see GetPage
+/////////////////////////////////////////////////////////////////////////////////////////
+func GetContractSeries(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) < 1 {
+		Log(stub).Debug("GetContractSeries(): Incorrect number of arguments. Expecting 1")
+		return nil, errcode.New(errcode.ArgCount, "GetContractSeries(): Incorrect number of arguments. Expecting 1 ")
+	}
+
+	pageSize, token, err := PageArgs(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, next, err := GetPage(stub, "SeriesTable", args[:1], pageSize, token)
+	if err != nil {
+		return nil, errcode.Wrapf(err, "GetContractSeries() operation failed. Error GetPage: %s", err)
+	}
+
+	nCol := GetNumberOfKeys("SeriesTable")
+
+	// Cycle keys are zero padded so rows come back in cycle order
+	tlist := make([]ContractObject, len(rows))
+	for i := 0; i < len(rows); i++ {
+		var entry SeriesEntry
+		if err := json.Unmarshal(rows[i].Columns[nCol].GetBytes(), &entry); err != nil {
+			Log(stub).Error("GetContractSeries(): Unmarshal error", "error", err)
+			return nil, errcode.Wrapf(err, "GetContractSeries() operation failed. %s", err)
+		}
+		tlist[i], err = GetContractObject(stub, entry.ContractId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return PagetoJSON(tlist, next)
+}
+
+func PostSeriesEntry(stub shim.ChaincodeStubInterface, contract ContractObject) error {
+
+	cycle, _ := strconv.Atoi(contract.Cycle)
+	entry := SeriesEntry{contract.SeriesId, "SERIES", contract.Cycle, contract.ContractId}
+
+	buff, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{contract.SeriesId, fmt.Sprintf("%03d", cycle)}
+	err = UpdateLedger(stub, "SeriesTable", keys, buff)
+	if err != nil {
+		Log(stub).Error("PostSeriesEntry(): write error while inserting record")
+		return err
+	}
+	return nil
+}
+
+//////////////////////////////////////////////////////////
+// ContractId of a cycle of a series
+//////////////////////////////////////////////////////////
+func CycleContractId(seriesId string, cycle int) string {
+	return fmt.Sprintf("%s%03d", seriesId, cycle)
+}
+
+//////////////////////////////////////////////////////////
+// CreationDate of the next cycle
+// Dates that cannot be parsed are replaced by today
+//////////////////////////////////////////////////////////
+func NextCycleDate(date string, recurrence string) string {
+
+	step := recurrenceStep[recurrence]
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02"} {
+		t, err := time.Parse(layout, date)
+		if err == nil {
+			return t.AddDate(step[0], step[1], step[2]).Format(layout)
+		}
+	}
+	return time.Now().Format("2006-01-02")
+}
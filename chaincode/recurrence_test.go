@@ -0,0 +1,72 @@
+package main
+
+import "testing"
+
+func bidCount(t *testing.T, stub *memStub) int {
+	t.Helper()
+	counters, err := GetStatCounters(stub, "BIDS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, c := range counters {
+		n += c.Values["Bids"]
+	}
+	return n
+}
+
+func TestSpawnNextCycle(t *testing.T) {
+	stub := newMemStub()
+	stub.initLedger(t)
+	if _, err := InsertRecord(stub, "USER", UserObject{UserID: "100", RecType: "USER", UserType: "TR"}); err != nil {
+		t.Fatal(err)
+	}
+
+	first := ContractObject{ContractId: "1000", RecType: "CREATECONTR", Type: "IT", Amount: "5000", CreationDate: "2016-10-18 10:00:00",
+		UserID: "100", WorkerID: "200", BidNo: "1", BidPrice: "4000", Status: "CLOSED",
+		SeriesId: "1000", Cycle: "1", Recurrence: "MONTHLY", Cycles: "3"}
+	// Cycle 2 of the series was taken by another contract
+	taken := ContractObject{ContractId: "1000002", RecType: "CREATECONTR", Type: "LEGAL", Amount: "100", CreationDate: "2016-10-20 10:00:00",
+		UserID: "100", Status: "OPEN"}
+	for _, c := range []ContractObject{first, taken} {
+		if _, err := InsertRecord(stub, "CONTRACT", c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := InsertRecord(stub, "BID", Bid{ContractId: "1000", RecType: "BID", BidNo: "1", UserID: "200", BidPrice: "4000"}); err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := SpawnNextCycle(stub, first)
+	if err != nil || id != "1000003" {
+		t.Fatalf("SpawnNextCycle() = %q, %v, want 1000003", id, err)
+	}
+	next, err := GetContractObject(stub, id)
+	if err != nil || next.Cycle != "3" || next.Status != "IN_PROGRESS" || next.CreationDate != "2016-11-18 10:00:00" {
+		t.Errorf("next cycle = %+v, %v", next, err)
+	}
+	if bid, err := GetBidObject(stub, id, "1"); err != nil || bid.UserID != "200" || bid.CarriedFrom != "1000" {
+		t.Errorf("carried bid = %+v, %v", bid, err)
+	}
+	if taken, _ := GetContractObject(stub, "1000002"); taken.SeriesId != "" {
+		t.Errorf("contract 1000002 joined the series: %+v", taken)
+	}
+
+	// The carried bid is not a new bid, neither when saved nor when recounted
+	if n := bidCount(t, stub); n != 1 {
+		t.Errorf("BIDS = %d after the cycle, want 1", n)
+	}
+	if _, err := RecountStats(stub); err != nil {
+		t.Fatal(err)
+	}
+	if n := bidCount(t, stub); n != 1 {
+		t.Errorf("BIDS = %d after RecountStats, want 1", n)
+	}
+
+	// No cycle is left once the last one is taken
+	last := first
+	last.Cycles = "2"
+	if id, err := SpawnNextCycle(stub, last); err != nil || id != "" {
+		t.Errorf("SpawnNextCycle() past the last free cycle = %q, %v", id, err)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/model"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// Client request IDs make invokes safe to retry
+// Any invoke accepts ?requestId=<id> after its function name, never among the arguments
+// as those can be free text. The first invoke with an ID
+// records its result in RequestTable; a retry with the same ID and arguments returns
+// that result without running the function again, and a request reusing the ID with
+// other arguments is rejected with REQUEST_ID_REUSED
+// Failed invokes are not recorded, so they can be retried with the same ID
+//./peer chaincode invoke -l golang -n mycc -c '{"Function": "PostTransaction?requestId=7f3c9a", "Args":["1000", "POSTTRAN", "5000", "PAYMENT", "200", "2016-10-01", "900", "1"]}'
+///////////////////////////////////////////////////////////////////////////////////////
+
+type ClientRequest struct {
+	RequestId string
+	RecType   string // REQUEST
+	Function  string
+	Digest    string // model.RequestDigest of the function and its arguments
+	TxID      string // Transaction that ran the request
+	Result    []byte
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////
+// Return the result of an earlier invoke with requestId
+// found is false if there was none and the invoke should run
+/////////////////////////////////////////////////////////////////////////////////////////////
+
+func ReplayRequest(stub shim.ChaincodeStubInterface, requestId string, function string, args []string) ([]byte, bool, error) {
+
+	Avalbytes, err := QueryLedger(stub, "RequestTable", []string{requestId})
+	if err != nil {
+		if errcode.From(err).Code == errcode.NotFound {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	req, err := JSONtoClientRequest(Avalbytes)
+	if err != nil {
+		return nil, false, errcode.Wrapf(err, "ReplayRequest(): Cannot read request %s : %s", requestId, err)
+	}
+
+	if req.Digest != model.RequestDigest(function, args) {
+		Log(stub).Debug("ReplayRequest(): Request ID used for another request", "requestId", requestId, "firstTxID", req.TxID)
+		return nil, false, errcode.New(errcode.RequestIDReused, "ReplayRequest(): Request ID already used for another request : " + requestId).WithField("requestId")
+	}
+
+	Log(stub).Info("ReplayRequest(): Returning the result of the first request", "requestId", requestId, "firstTxID", req.TxID)
+	return req.Result, true, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////
+// Record the result of an invoke with requestId for ReplayRequest
+/////////////////////////////////////////////////////////////////////////////////////////////
+
+func RecordRequest(stub shim.ChaincodeStubInterface, requestId string, function string, args []string, result []byte) error {
+
+	req := ClientRequest{
+		RequestId: requestId,
+		RecType:   "REQUEST",
+		Function:  function,
+		Digest:    model.RequestDigest(function, args),
+		TxID:      stub.GetTxID(),
+		Result:    result,
+	}
+
+	buff, err := ClientRequesttoJSON(req)
+	if err != nil {
+		return errcode.Wrapf(err, "RecordRequest(): Cannot create object buffer for request %s : %s", requestId, err)
+	}
+
+	err = UpdateLedger(stub, "RequestTable", []string{requestId}, buff)
+	if err != nil {
+		Log(stub).Error("RecordRequest(): write error while inserting record", "requestId", requestId)
+		return err
+	}
+	return nil
+}
+
+//////////////////////////////////////////////////////////
+// Converts a ClientRequest to a JSON String
+//////////////////////////////////////////////////////////
+func ClientRequesttoJSON(req ClientRequest) ([]byte, error) {
+
+	ajson, err := json.Marshal(req)
+	if err != nil {
+		logger.Error("ClientRequesttoJSON(): error", "error", err)
+		return nil, err
+	}
+	return ajson, nil
+}
+
+//////////////////////////////////////////////////////////
+// Converts a JSON String to a ClientRequest
+//////////////////////////////////////////////////////////
+func JSONtoClientRequest(areq []byte) (ClientRequest, error) {
+
+	req := ClientRequest{}
+	err := json.Unmarshal(areq, &req)
+	if err != nil {
+		logger.Error("JSONtoClientRequest(): error", "error", err)
+		return req, err
+	}
+	return req, err
+}
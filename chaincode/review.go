@@ -0,0 +1,276 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/events"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// A Review is left by the owner or the worker of a CLOSED contract about the other party
+// Each party can review a contract only once
+// Reviews are keyed by Reviewee so that all reviews of a user can be listed and
+// aggregated into the Rating on the UserObject
+///////////////////////////////////////////////////////////////////////////////////////
+
+type Review struct {
+	ContractId string
+	RecType    string // REVIEW
+	ReviewerID string
+	RevieweeID string
+	Score      string // 1 to 5
+	Comment    string
+	ReviewTime string
+}
+
+// Reviews lose half of their weight in the Rating every ReviewHalfLife
+const ReviewHalfLife = 180 * 24 * time.Hour
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Post a Review about the other party of a CLOSED contract
+// The Reviewee is derived from the contract - owner reviews worker and vice versa
+// Example
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "PostReview", "Args":["1000", "REVIEW", "100", "5", "Delivered ahead of schedule"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func PostReview(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	rv, err := CreateReview(args[0:])
+	if err != nil {
+		return nil, err
+	}
+
+	contract, err := GetContractObject(stub, rv.ContractId)
+	if err != nil {
+		return nil, err
+	}
+
+	if contract.Status != "CLOSED" {
+		Log(stub).Debug("PostReview(): Cannot review as Contract is not CLOSED", "contractId", rv.ContractId)
+		return nil, errcode.New(errcode.InvalidState, "PostReview(): Cannot review as Contract is not CLOSED : " + rv.ContractId)
+	}
+
+	switch rv.ReviewerID {
+	case contract.UserID:
+		rv.RevieweeID = contract.WorkerID
+	case contract.WorkerID:
+		rv.RevieweeID = contract.UserID
+	default:
+		Log(stub).Debug("PostReview(): Reviewer is not a party of the contract", "userId", rv.ReviewerID)
+		return nil, errcode.New(errcode.NotAllowed, "PostReview(): Reviewer is not a party of the contract : " + rv.ReviewerID)
+	}
+
+	// One review per party per contract
+	keys := []string{rv.RevieweeID, rv.ContractId, rv.ReviewerID}
+	_, err = QueryLedger(stub, "ReviewTable", keys)
+	if err == nil {
+		Log(stub).Debug("PostReview(): Contract already reviewed by", "userId", rv.ReviewerID)
+		return nil, errcode.New(errcode.AlreadyExists, "PostReview(): Contract already reviewed by : " + rv.ReviewerID)
+	}
+
+	buff, err := ReviewtoJSON(rv)
+	if err != nil {
+		Log(stub).Error("PostReview(): Failed Cannot create object buffer for write", "contractId", rv.ContractId)
+		return nil, errcode.New(errcode.Internal, "PostReview(): Failed Cannot create object buffer for write : " + rv.ContractId)
+	}
+
+	err = UpdateLedger(stub, "ReviewTable", keys, buff)
+	if err != nil {
+		Log(stub).Error("PostReview(): write error while inserting record")
+		return nil, err
+	}
+
+	reviewee, err := RecomputeRating(stub, rv.RevieweeID)
+	if err != nil {
+		Log(stub).Error("PostReview(): Failed to recompute Rating for", "userId", rv.RevieweeID)
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.ReviewPosted, rv.ReviewerID,
+		events.ReviewEvent{ContractId: rv.ContractId, ReviewerID: rv.ReviewerID, RevieweeID: rv.RevieweeID, Score: rv.Score, Rating: reviewee.Rating})
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+func CreateReview(args []string) (Review, error) {
+
+	var rv Review
+
+	// Check there are 5 Arguments - Reviewee and ReviewTime are computed
+	if len(args) != 5 {
+		logger.Debug("CreateReview(): Incorrect number of arguments. Expecting 5")
+		return rv, errcode.New(errcode.ArgCount, "CreateReview(): Incorrect number of arguments. Expecting 5 ")
+	}
+
+	_, err := strconv.Atoi(args[0])
+	if err != nil {
+		return rv, errcode.New(errcode.InvalidArgument, "CreateReview(): Contract ID should be an integer").WithField("ContractId")
+	}
+
+	score, err := strconv.Atoi(args[3])
+	if err != nil || score < 1 || score > 5 {
+		return rv, errcode.New(errcode.InvalidArgument, "CreateReview(): Score should be an integer between 1 and 5").WithField("Score")
+	}
+
+	reviewTime := time.Now().Format("2006-01-02 15:04:05")
+
+	rv = Review{ContractId: args[0], RecType: args[1], ReviewerID: args[2], Score: args[3], Comment: args[4], ReviewTime: reviewTime}
+	logger.Debug("CreateReview(): Review Object", "record", rv)
+
+	return rv, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Recompute the Rating of a User from all the Reviews received
+// ReviewCount - number of reviews
+// ReviewMean  - plain average of the scores
+// Rating      - average weighted by the age of each review, see ReviewHalfLife
+// Ages are taken from the latest review and not the clock, so the Rating
+// only changes when a review is posted
+// The user is replaced in UserTable and its indexes
+////////////////////////////////////////////////////////////////////////////
+func RecomputeRating(stub shim.ChaincodeStubInterface, userId string) (UserObject, error) {
+
+	userBytes, err := ValidateMember(stub, userId)
+	if err != nil {
+		return UserObject{}, err
+	}
+
+	user, err := JSONtoUser(userBytes)
+	if err != nil {
+		return user, err
+	}
+
+	reviews, err := GetReviewList(stub, userId)
+	if err != nil {
+		return user, err
+	}
+
+	layout := "2006-01-02 15:04:05"
+	var now time.Time
+	for _, rv := range reviews {
+		if rt, err := time.Parse(layout, rv.ReviewTime); err == nil && rt.After(now) {
+			now = rt
+		}
+	}
+
+	var sum, wsum, weights float64
+
+	for _, rv := range reviews {
+		score, _ := strconv.Atoi(rv.Score)
+		weight := 1.0
+		if rt, err := time.Parse(layout, rv.ReviewTime); err == nil && now.After(rt) {
+			weight = math.Pow(0.5, float64(now.Sub(rt))/float64(ReviewHalfLife))
+		}
+		sum += float64(score)
+		wsum += weight * float64(score)
+		weights += weight
+	}
+
+	user.ReviewCount = strconv.Itoa(len(reviews))
+	if len(reviews) > 0 {
+		user.ReviewMean = strconv.FormatFloat(sum/float64(len(reviews)), 'f', 2, 64)
+		user.Rating = strconv.FormatFloat(wsum/weights, 'f', 2, 64)
+	}
+
+	_, err = ReplaceRecord(stub, "USER", user)
+	if err != nil {
+		Log(stub).Error("RecomputeRating(): Failed ReplaceRecord of User")
+		return user, err
+	}
+
+	Log(stub).Info("RecomputeRating(): Rating of", "userId", userId, "rating", user.Rating)
+	return user, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////
+// Get the Review history of a User
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetUserReviews", "Args": ["200"]}'
+// Optional page size and continuation token follow, see GetPage
+/////////////////////////////////////////////////////////////////////////////////////////
+func GetUserReviews(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) < 1 {
+		Log(stub).Debug("GetUserReviews(): Incorrect number of arguments. Expecting 1")
+		return nil, errcode.New(errcode.ArgCount, "GetUserReviews(): Incorrect number of arguments. Expecting 1 ")
+	}
+
+	pageSize, token, err := PageArgs(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, next, err := GetPage(stub, "ReviewTable", args[:1], pageSize, token)
+	if err != nil {
+		return nil, errcode.Wrapf(err, "GetUserReviews() operation failed. Error GetPage: %s", err)
+	}
+
+	reviews, err := RowstoReviews(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return PagetoJSON(reviews, next)
+}
+
+func GetReviewList(stub shim.ChaincodeStubInterface, userId string) ([]Review, error) {
+
+	rows, err := GetList(stub, "ReviewTable", []string{userId})
+	if err != nil {
+		return nil, errcode.Wrapf(err, "GetReviewList() operation failed. Error GetList: %s", err)
+	}
+
+	return RowstoReviews(rows)
+}
+
+func RowstoReviews(rows []shim.Row) ([]Review, error) {
+
+	nCol := GetNumberOfKeys("ReviewTable")
+
+	tlist := make([]Review, len(rows))
+	for i := 0; i < len(rows); i++ {
+		ts := rows[i].Columns[nCol].GetBytes()
+		rv, err := JSONtoReview(ts)
+		if err != nil {
+			logger.Error("RowstoReviews(): Unmarshal error", "error", err)
+			return nil, errcode.Wrapf(err, "RowstoReviews() operation failed. %s", err)
+		}
+		tlist[i] = rv
+	}
+
+	return tlist, nil
+}
+
+//////////////////////////////////////////////////////////
+// Converts a Review to a JSON String
+//////////////////////////////////////////////////////////
+func ReviewtoJSON(rv Review) ([]byte, error) {
+
+	ajson, err := json.Marshal(rv)
+	if err != nil {
+		logger.Error("ReviewtoJSON(): error", "error", err)
+		return nil, err
+	}
+	return ajson, nil
+}
+
+//////////////////////////////////////////////////////////
+// Converts a JSON String to a Review
+//////////////////////////////////////////////////////////
+func JSONtoReview(areq []byte) (Review, error) {
+
+	rv := Review{}
+	err := json.Unmarshal(areq, &rv)
+	if err != nil {
+		logger.Error("JSONtoReview(): error", "error", err)
+		return rv, err
+	}
+	return rv, err
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// Contract search
+// ViewContracts filters contracts on Type, Status, owner, Amount range, creation
+// date range and a text match on Description / RequirementDescription, and sorts
+// them on Amount, CreationDate or Deadline (CreationDate + Duration days)
+//
+// Secondary indexes, each holding a copy of the contract like ContractCatTable
+//   ContractStatusTable - keys Status, ContractId
+//   ContractOwnerTable  - keys UserID, ContractId
+// They are declared with the CONTRACT record, see Records
+// ContractCatTable and ContractPeriodTable are used as well, see ListContracts
+// The most selective index for the filter provides the candidates
+///////////////////////////////////////////////////////////////////////////////////////
+
+type ContractFilter struct {
+	Type      string
+	Status    string
+	UserID    string
+	MinAmount string
+	MaxAmount string
+	FromDate  string // 2006-01-02, inclusive
+	ToDate    string // 2006-01-02, inclusive
+	Text      string
+	SortBy    string // Amount / CreationDate / Deadline
+	Desc      bool
+}
+
+type indexLookup struct {
+	table string
+	keys  []string
+}
+
+// Longest creation date range searched month by month in ContractPeriodTable
+const maxSearchMonths = 24
+
+/////////////////////////////////////////////////////////////////////////////////////////
+// Search contracts - the filter is a JSON object, empty fields are not filtered on
+// Optional page size and continuation token follow, see GetPage
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "ViewContracts", "Args": ["{\"Status\":\"OPEN\",\"MinAmount\":\"500\",\"Text\":\"fence\",\"SortBy\":\"Amount\"}"]}'
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "ViewContracts", "Args": ["{\"UserID\":\"100\",\"SortBy\":\"Deadline\"}", "20", "<nextToken>"]}'
+/////////////////////////////////////////////////////////////////////////////////////////
+func ViewContracts(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) < 1 {
+		Log(stub).Debug("ViewContracts(): Incorrect number of arguments. Expecting 1")
+		return nil, errcode.New(errcode.ArgCount, "ViewContracts(): Incorrect number of arguments. Expecting 1 ")
+	}
+
+	var filter ContractFilter
+	if args[0] != "" {
+		if err := json.Unmarshal([]byte(args[0]), &filter); err != nil {
+			return nil, errcode.New(errcode.InvalidArgument, "ViewContracts(): Filter should be a JSON object : " + args[0]).WithField("filter")
+		}
+	}
+
+	pageSize, token, err := PageArgs(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	minAmount, maxAmount, err := AmountRange(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	switch filter.SortBy {
+	case "", "Amount", "CreationDate", "Deadline":
+	default:
+		return nil, errcode.New(errcode.InvalidArgument, "ViewContracts(): SortBy should be Amount, CreationDate or Deadline : " + filter.SortBy).WithField("SortBy")
+	}
+
+	candidates, err := SearchCandidates(stub, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.ToLower(filter.Text)
+	var tlist []ContractObject
+	for _, c := range candidates {
+		if filter.Type != "" && c.Type != filter.Type {
+			continue
+		}
+		if filter.Status != "" && c.Status != filter.Status {
+			continue
+		}
+		if filter.UserID != "" && c.UserID != filter.UserID {
+			continue
+		}
+		amount, _ := strconv.Atoi(c.Amount)
+		if amount < minAmount || amount > maxAmount {
+			continue
+		}
+		day := ContractDay(c.CreationDate)
+		if filter.FromDate != "" && day < filter.FromDate {
+			continue
+		}
+		if filter.ToDate != "" && day > filter.ToDate {
+			continue
+		}
+		if text != "" && !strings.Contains(strings.ToLower(c.Description), text) &&
+			!strings.Contains(strings.ToLower(c.RequirementDescription), text) {
+			continue
+		}
+		tlist = append(tlist, c)
+	}
+
+	SortContracts(tlist, filter.SortBy, filter.Desc)
+
+	start, end, next, err := PageBounds(len(tlist), pageSize, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return PagetoJSON(tlist[start:end], next)
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Read the contracts of the most selective index for the filter
+////////////////////////////////////////////////////////////////////////////
+func SearchCandidates(stub shim.ChaincodeStubInterface, filter ContractFilter) ([]ContractObject, error) {
+
+	var lookups []indexLookup
+	switch {
+	case filter.UserID != "":
+		lookups = append(lookups, indexLookup{"ContractOwnerTable", []string{filter.UserID}})
+	case filter.Status != "":
+		lookups = append(lookups, indexLookup{"ContractStatusTable", []string{filter.Status}})
+	case filter.Type != "":
+		lookups = append(lookups, indexLookup{"ContractCatTable", []string{ContractNamespace, filter.Type}})
+	default:
+		months := SearchMonths(filter.FromDate, filter.ToDate)
+		for _, m := range months {
+			lookups = append(lookups, indexLookup{"ContractPeriodTable", strings.Split(m, "-")})
+		}
+		if months == nil {
+			lookups = append(lookups, indexLookup{"ContractCatTable", []string{ContractNamespace}})
+		}
+	}
+
+	var tlist []ContractObject
+	for _, l := range lookups {
+		rows, err := GetList(stub, l.table, l.keys)
+		if err != nil {
+			return nil, errcode.Wrapf(err, "SearchCandidates() operation failed. Error GetList: %s", err)
+		}
+
+		nCol := GetNumberOfKeys(l.table)
+		for i := 0; i < len(rows); i++ {
+			c, err := JSONtoAucReq(rows[i].Columns[nCol].GetBytes())
+			if err != nil {
+				Log(stub).Error("SearchCandidates(): Unmarshal error", "error", err)
+				return nil, errcode.Wrapf(err, "SearchCandidates() operation failed. %s", err)
+			}
+			tlist = append(tlist, c)
+		}
+	}
+
+	return tlist, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// The months between two dates, nil if the range is open or too long
+////////////////////////////////////////////////////////////////////////////
+func SearchMonths(from string, to string) []string {
+
+	f, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", to)
+	if err != nil || t.Before(f) {
+		return nil
+	}
+
+	var months []string
+	for m := time.Date(f.Year(), f.Month(), 1, 0, 0, 0, 0, time.UTC); !m.After(t); m = m.AddDate(0, 1, 0) {
+		if len(months) == maxSearchMonths {
+			return nil
+		}
+		months = append(months, m.Format("2006-01"))
+	}
+	return months
+}
+
+func AmountRange(filter ContractFilter) (int, int, error) {
+
+	min, max := 0, int(^uint(0)>>1)
+	var err error
+	if filter.MinAmount != "" {
+		min, err = strconv.Atoi(filter.MinAmount)
+		if err != nil {
+			return 0, 0, errcode.New(errcode.InvalidArgument, "AmountRange(): MinAmount should be an integer : " + filter.MinAmount).WithField("MinAmount")
+		}
+	}
+	if filter.MaxAmount != "" {
+		max, err = strconv.Atoi(filter.MaxAmount)
+		if err != nil {
+			return 0, 0, errcode.New(errcode.InvalidArgument, "AmountRange(): MaxAmount should be an integer : " + filter.MaxAmount).WithField("MaxAmount")
+		}
+	}
+	return min, max, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Sort contracts on Amount, CreationDate or Deadline, ContractId otherwise
+////////////////////////////////////////////////////////////////////////////
+func SortContracts(tlist []ContractObject, sortBy string, desc bool) {
+
+	sort.Stable(contractSorter{tlist, sortBy, desc})
+}
+
+type contractSorter struct {
+	tlist  []ContractObject
+	sortBy string
+	desc   bool
+}
+
+func (s contractSorter) Len() int      { return len(s.tlist) }
+func (s contractSorter) Swap(i, j int) { s.tlist[i], s.tlist[j] = s.tlist[j], s.tlist[i] }
+func (s contractSorter) Less(i, j int) bool {
+	if s.desc {
+		return s.less(s.tlist[j], s.tlist[i])
+	}
+	return s.less(s.tlist[i], s.tlist[j])
+}
+
+func (s contractSorter) less(a ContractObject, b ContractObject) bool {
+	switch s.sortBy {
+	case "Amount":
+		x, _ := strconv.Atoi(a.Amount)
+		y, _ := strconv.Atoi(b.Amount)
+		if x != y {
+			return x < y
+		}
+	case "CreationDate":
+		if a.CreationDate != b.CreationDate {
+			return a.CreationDate < b.CreationDate
+		}
+	case "Deadline":
+		x, y := ContractDeadline(a), ContractDeadline(b)
+		if x != y {
+			return x < y
+		}
+	}
+	return a.ContractId < b.ContractId
+}
+
+//////////////////////////////////////////////////////////
+// The day a contract is due - CreationDate + Duration days
+//////////////////////////////////////////////////////////
+func ContractDeadline(c ContractObject) string {
+
+	t, err := time.Parse("2006-01-02", ContractDay(c.CreationDate))
+	if err != nil {
+		return ""
+	}
+	days, _ := strconv.Atoi(c.Duration)
+	return t.AddDate(0, 0, days).Format("2006-01-02")
+}
+
+//////////////////////////////////////////////////////////
+// Day of a CreationDate
+// Dates that cannot be parsed sort before all others
+//////////////////////////////////////////////////////////
+func ContractDay(date string) string {
+
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	return "0000-00-00"
+}
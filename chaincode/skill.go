@@ -0,0 +1,540 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/events"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// Skills
+// The taxonomy of skills in SkillTable is managed by Auction House (AH) users
+// Users declare skills from the taxonomy and other users can endorse them
+// Contracts list the skills they require
+//
+// UserSkillTable  - skills of a user          keys UserID, SkillId
+// SkillUserTable  - users having a skill      keys SkillId, UserID
+//
+// RecommendContracts and RecommendBidders rank by
+//   skill overlap 60% - share of the required skills the user has, endorsed skills count fully
+//   budget fit    20% - how close the contract Amount is to the price expected by the user
+//   rating        20% - Rating of the user out of 5, unrated users count as 2.5
+///////////////////////////////////////////////////////////////////////////////////////
+
+type Skill struct {
+	SkillId      string
+	RecType      string // SKILL
+	Name         string
+	Category     string
+	UserID       string // AH user who added the skill
+	CreationDate string
+}
+
+type UserSkill struct {
+	UserID       string
+	RecType      string // SKILL
+	SkillId      string
+	Endorsements []Endorsement
+	DeclareTime  string
+}
+
+type Endorsement struct {
+	UserID  string
+	EndTime string
+}
+
+/////////////////////////////////////////////////////////////
+// A ranked entry returned by RecommendContracts and
+// RecommendBidders
+/////////////////////////////////////////////////////////////
+
+type Recommendation struct {
+	ContractId    string
+	UserID        string
+	Score         string
+	SkillMatch    string
+	BudgetFit     string
+	Rating        string
+	MatchedSkills []string
+}
+
+const (
+	skillWeight  = 0.6
+	budgetWeight = 0.2
+	ratingWeight = 0.2
+	// Weight of a declared skill that nobody has endorsed yet
+	unendorsedSkill = 0.75
+)
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Add a skill to the taxonomy
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "PostSkill", "Args":["GO", "SKILL", "100", "Go programming", "Software"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func PostSkill(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 5 {
+		Log(stub).Debug("PostSkill(): Incorrect number of arguments. Expecting 5")
+		return nil, errcode.New(errcode.ArgCount, "PostSkill(): Incorrect number of arguments. Expecting 5 ")
+	}
+
+	userBytes, err := ValidateMember(stub, args[2])
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := JSONtoUser(userBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.UserType != "AH" {
+		Log(stub).Debug("PostSkill(): Only Auction House users manage the skill taxonomy", "userId", args[2])
+		return nil, errcode.New(errcode.NotAllowed, "PostSkill(): Only Auction House users manage the skill taxonomy : " + args[2])
+	}
+
+	if _, err := QueryLedger(stub, "SkillTable", args[:1]); err == nil {
+		return nil, errcode.New(errcode.AlreadyExists, "PostSkill(): Skill already exists : " + args[0]).WithField("SkillId")
+	}
+
+	skill := Skill{args[0], args[1], args[3], args[4], args[2], time.Now().Format("2006-01-02 15:04:05")}
+	buff, err := json.Marshal(skill)
+	if err != nil {
+		return nil, err
+	}
+
+	err = UpdateLedger(stub, "SkillTable", []string{skill.SkillId}, buff)
+	if err != nil {
+		Log(stub).Error("PostSkill(): write error while inserting record")
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.SkillPosted, skill.UserID, events.SkillEvent{SkillId: skill.SkillId})
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// A user declares one of the skills of the taxonomy
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "DeclareSkill", "Args":["200", "SKILL", "GO"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func DeclareSkill(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 3 {
+		Log(stub).Debug("DeclareSkill(): Incorrect number of arguments. Expecting 3")
+		return nil, errcode.New(errcode.ArgCount, "DeclareSkill(): Incorrect number of arguments. Expecting 3 ")
+	}
+
+	_, err := ValidateMember(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := QueryLedger(stub, "SkillTable", args[2:3]); err != nil {
+		return nil, errcode.New(errcode.NotFound, "DeclareSkill(): Skill is not part of the taxonomy : " + args[2]).WithField("SkillId")
+	}
+
+	if _, err := QueryLedger(stub, "UserSkillTable", []string{args[0], args[2]}); err == nil {
+		return nil, errcode.New(errcode.AlreadyExists, "DeclareSkill(): Skill already declared : " + args[2]).WithField("SkillId")
+	}
+
+	us := UserSkill{UserID: args[0], RecType: args[1], SkillId: args[2], DeclareTime: time.Now().Format("2006-01-02 15:04:05")}
+	buff, err := PostUserSkill(stub, us, false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.SkillDeclared, us.UserID, events.SkillEvent{SkillId: us.SkillId, UserID: us.UserID})
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Endorse the skill of another user
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "EndorseSkill", "Args":["200", "SKILL", "GO", "300"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func EndorseSkill(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 4 {
+		Log(stub).Debug("EndorseSkill(): Incorrect number of arguments. Expecting 4")
+		return nil, errcode.New(errcode.ArgCount, "EndorseSkill(): Incorrect number of arguments. Expecting 4 ")
+	}
+
+	if args[0] == args[3] {
+		return nil, errcode.New(errcode.NotAllowed, "EndorseSkill(): Users cannot endorse their own skills : " + args[3])
+	}
+
+	_, err := ValidateMember(stub, args[3])
+	if err != nil {
+		return nil, err
+	}
+
+	usBytes, err := QueryLedger(stub, "UserSkillTable", []string{args[0], args[2]})
+	if err != nil {
+		return nil, errcode.New(errcode.NotFound, "EndorseSkill(): User has not declared skill : " + args[2]).WithField("SkillId")
+	}
+
+	var us UserSkill
+	if err := json.Unmarshal(usBytes, &us); err != nil {
+		return nil, err
+	}
+
+	for _, e := range us.Endorsements {
+		if e.UserID == args[3] {
+			return nil, errcode.New(errcode.AlreadyExists, "EndorseSkill(): Skill already endorsed by : " + args[3])
+		}
+	}
+
+	us.Endorsements = append(us.Endorsements, Endorsement{args[3], time.Now().Format("2006-01-02 15:04:05")})
+	buff, err := PostUserSkill(stub, us, true)
+	if err != nil {
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.SkillEndorsed, args[3], events.SkillEvent{SkillId: us.SkillId, UserID: us.UserID, EndorserID: args[3]})
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Write a user skill to UserSkillTable and its SkillUserTable index
+////////////////////////////////////////////////////////////////////////////
+func PostUserSkill(stub shim.ChaincodeStubInterface, us UserSkill, replace bool) ([]byte, error) {
+
+	buff, err := SaveRecord(stub, "USERSKILL", us, replace)
+	if err != nil {
+		Log(stub).Error("PostUserSkill(): write error while saving user skill")
+		return nil, err
+	}
+
+	return buff, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// The owner sets the skills required by an OPEN contract as a JSON array of SkillIds
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "SetContractSkills", "Args":["1000", "CREATECONTR", "100", "[\"GO\",\"SQL\"]"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func SetContractSkills(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 4 {
+		Log(stub).Debug("SetContractSkills(): Incorrect number of arguments. Expecting 4")
+		return nil, errcode.New(errcode.ArgCount, "SetContractSkills(): Incorrect number of arguments. Expecting 4 ")
+	}
+
+	var skills []string
+	if err := json.Unmarshal([]byte(args[3]), &skills); err != nil {
+		return nil, errcode.New(errcode.InvalidArgument, "SetContractSkills(): Skills should be a JSON array : " + args[3]).WithField("Skills")
+	}
+
+	for _, s := range skills {
+		if _, err := QueryLedger(stub, "SkillTable", []string{s}); err != nil {
+			return nil, errcode.New(errcode.NotFound, "SetContractSkills(): Skill is not part of the taxonomy : " + s).WithField("Skills")
+		}
+	}
+
+	contract, err := GetContractObject(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if contract.UserID != args[2] {
+		Log(stub).Debug("SetContractSkills(): Only the owner of the contract can set skills", "userId", args[2])
+		return nil, errcode.New(errcode.NotAllowed, "SetContractSkills(): Only the owner of the contract can set skills : " + args[2])
+	}
+
+	if contract.Status != "OPEN" {
+		return nil, errcode.New(errcode.InvalidState, "SetContractSkills(): Contract is not OPEN : " + args[0])
+	}
+
+	contract.Skills = skills
+	buff, err := UpdateContractStatus(stub, contract)
+	if err != nil {
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.ContractUpdated, args[2], ContractEventOf(contract))
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////
+// Rank the open contracts for a user
+// The optional second argument is the price the user expects for a contract
+// Optional page size and continuation token follow, see GetPage
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "RecommendContracts", "Args": ["200", "1000"]}'
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "RecommendContracts", "Args": ["200", "", "20", "<nextToken>"]}'
+/////////////////////////////////////////////////////////////////////////////////////////
+func RecommendContracts(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) < 1 {
+		Log(stub).Debug("RecommendContracts(): Incorrect number of arguments. Expecting 1")
+		return nil, errcode.New(errcode.ArgCount, "RecommendContracts(): Incorrect number of arguments. Expecting 1 ")
+	}
+
+	userBytes, err := ValidateMember(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := JSONtoUser(userBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize, token, err := PageArgs(args, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := 0
+	if len(args) > 1 && args[1] != "" {
+		expected, err = strconv.Atoi(args[1])
+		if err != nil {
+			return nil, errcode.New(errcode.InvalidArgument, "RecommendContracts(): Expected price should be an integer : " + args[1]).WithField("price")
+		}
+	}
+
+	skills, err := GetUserSkills(stub, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := GetList(stub, "ContractOpenTable", []string{ContractNamespace})
+	if err != nil {
+		return nil, errcode.Wrapf(err, "RecommendContracts() operation failed. Error GetList: %s", err)
+	}
+
+	nCol := GetNumberOfKeys("ContractOpenTable")
+
+	var recs []Recommendation
+	for i := 0; i < len(rows); i++ {
+		contract, err := JSONtoAucReq(rows[i].Columns[nCol].GetBytes())
+		if err != nil {
+			Log(stub).Error("RecommendContracts(): Unmarshal error", "error", err)
+			return nil, errcode.Wrapf(err, "RecommendContracts() operation failed. %s", err)
+		}
+
+		if contract.UserID == user.UserID {
+			continue
+		}
+
+		match, matched := SkillMatch(contract.Skills, skills)
+		if len(contract.Skills) > 0 && len(matched) == 0 {
+			continue
+		}
+
+		amount, _ := strconv.Atoi(contract.Amount)
+		recs = append(recs, NewRecommendation(contract.ContractId, user, match, matched, BudgetFit(amount, expected)))
+	}
+
+	return RankRecommendations(recs, pageSize, token)
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////
+// Rank the users having the skills required by a contract
+// The budget fit of a user comes from the price of the user's bid, if any
+// Optional page size and continuation token follow, see GetPage
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "RecommendBidders", "Args": ["1000"]}'
+/////////////////////////////////////////////////////////////////////////////////////////
+func RecommendBidders(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) < 1 {
+		Log(stub).Debug("RecommendBidders(): Incorrect number of arguments. Expecting 1")
+		return nil, errcode.New(errcode.ArgCount, "RecommendBidders(): Incorrect number of arguments. Expecting 1 ")
+	}
+
+	pageSize, token, err := PageArgs(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	contract, err := GetContractObject(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	amount, _ := strconv.Atoi(contract.Amount)
+
+	// Lowest price bid by each user on this contract
+	prices := make(map[string]int)
+	bidRows, err := GetList(stub, "BidTable", []string{contract.ContractId})
+	if err != nil {
+		return nil, errcode.Wrapf(err, "RecommendBidders() operation failed. Error GetList: %s", err)
+	}
+	for _, row := range bidRows {
+		bid, err := JSONtoBid(row.Columns[GetNumberOfKeys("BidTable")].GetBytes())
+		if err != nil {
+			return nil, err
+		}
+		price, _ := strconv.Atoi(bid.BidPrice)
+		if p, ok := prices[bid.UserID]; !ok || price < p {
+			prices[bid.UserID] = price
+		}
+	}
+
+	// Candidates are the users having at least one of the required skills
+	candidates := make(map[string]bool)
+	for _, s := range contract.Skills {
+		rows, err := GetList(stub, "SkillUserTable", []string{s})
+		if err != nil {
+			return nil, errcode.Wrapf(err, "RecommendBidders() operation failed. Error GetList: %s", err)
+		}
+		for _, row := range rows {
+			candidates[row.Columns[1].GetString_()] = true
+		}
+	}
+	for userId := range prices {
+		candidates[userId] = true
+	}
+	delete(candidates, contract.UserID)
+
+	var recs []Recommendation
+	for userId := range candidates {
+		userBytes, err := ValidateMember(stub, userId)
+		if err != nil {
+			return nil, err
+		}
+		user, err := JSONtoUser(userBytes)
+		if err != nil {
+			return nil, err
+		}
+
+		skills, err := GetUserSkills(stub, userId)
+		if err != nil {
+			return nil, err
+		}
+
+		match, matched := SkillMatch(contract.Skills, skills)
+		recs = append(recs, NewRecommendation(contract.ContractId, user, match, matched, BudgetFit(amount, prices[userId])))
+	}
+
+	return RankRecommendations(recs, pageSize, token)
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Skill overlap between the required skills and the skills of a user
+// Returns a score between 0 and 1 and the required skills the user has
+// A contract without required skills matches everybody with 0
+////////////////////////////////////////////////////////////////////////////
+func SkillMatch(required []string, skills map[string]UserSkill) (float64, []string) {
+
+	if len(required) == 0 {
+		return 0, nil
+	}
+
+	var score float64
+	var matched []string
+	for _, s := range required {
+		us, ok := skills[s]
+		if !ok {
+			continue
+		}
+		matched = append(matched, s)
+		if len(us.Endorsements) > 0 {
+			score += 1
+		} else {
+			score += unendorsedSkill
+		}
+	}
+	return score / float64(len(required)), matched
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Budget fit between the Amount of a contract and the expected price
+// 1 when they are equal, the ratio of the smaller to the larger otherwise
+// Without an expected price everything fits
+////////////////////////////////////////////////////////////////////////////
+func BudgetFit(amount int, expected int) float64 {
+
+	if expected <= 0 || amount <= 0 {
+		return 1
+	}
+	if amount < expected {
+		return float64(amount) / float64(expected)
+	}
+	return float64(expected) / float64(amount)
+}
+
+func NewRecommendation(contractId string, user UserObject, match float64, matched []string, fit float64) Recommendation {
+
+	rating, err := strconv.ParseFloat(user.Rating, 64)
+	if err != nil {
+		rating = 2.5
+	}
+
+	score := skillWeight*match + budgetWeight*fit + ratingWeight*rating/5
+
+	return Recommendation{ContractId: contractId, UserID: user.UserID,
+		Score:         strconv.FormatFloat(score, 'f', 3, 64),
+		SkillMatch:    strconv.FormatFloat(match, 'f', 2, 64),
+		BudgetFit:     strconv.FormatFloat(fit, 'f', 2, 64),
+		Rating:        user.Rating,
+		MatchedSkills: matched}
+}
+
+func RankRecommendations(recs []Recommendation, pageSize int, token string) ([]byte, error) {
+
+	sort.Stable(byScore(recs))
+
+	start, end, next, err := PageBounds(len(recs), pageSize, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return PagetoJSON(recs[start:end], next)
+}
+
+// Highest Score first, ties by ContractId and UserID
+type byScore []Recommendation
+
+func (r byScore) Len() int      { return len(r) }
+func (r byScore) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r byScore) Less(i, j int) bool {
+	si, _ := strconv.ParseFloat(r[i].Score, 64)
+	sj, _ := strconv.ParseFloat(r[j].Score, 64)
+	if si != sj {
+		return si > sj
+	}
+	return r[i].ContractId+r[i].UserID < r[j].ContractId+r[j].UserID
+}
+
+////////////////////////////////////////////////////////////////////////////
+// The skills of a user by SkillId
+////////////////////////////////////////////////////////////////////////////
+func GetUserSkills(stub shim.ChaincodeStubInterface, userId string) (map[string]UserSkill, error) {
+
+	rows, err := GetList(stub, "UserSkillTable", []string{userId})
+	if err != nil {
+		return nil, errcode.Wrapf(err, "GetUserSkills() operation failed. Error GetList: %s", err)
+	}
+
+	nCol := GetNumberOfKeys("UserSkillTable")
+
+	skills := make(map[string]UserSkill)
+	for i := 0; i < len(rows); i++ {
+		var us UserSkill
+		if err := json.Unmarshal(rows[i].Columns[nCol].GetBytes(), &us); err != nil {
+			Log(stub).Error("GetUserSkills(): Unmarshal error", "error", err)
+			return nil, errcode.Wrapf(err, "GetUserSkills() operation failed. %s", err)
+		}
+		skills[us.SkillId] = us
+	}
+
+	return skills, nil
+}
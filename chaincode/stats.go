@@ -0,0 +1,436 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// Marketplace statistics
+// Counters in StatsTable are updated by the OnSave hooks of the CONTRACT, BID and
+// TRANSACTION records so GetMarketStats never scans the other tables
+// RebuildStats recounts them from the records, see MigrateContractKeys
+//
+// Stat       Bucket         Values
+// CONTRACTS  Type           number of contracts per Status
+// WINNING    TOTAL          Count, Bids and Amounts of awarded contracts
+// WINNING    RATIO          awards per winning bid in % of Amount, for the average and median
+// BIDS       TOTAL          Contracts and Bids posted
+// VOLUME     2016-10        Count and Amount of work payments per month, see IsWorkPayment
+///////////////////////////////////////////////////////////////////////////////////////
+
+type StatCounter struct {
+	Stat    string
+	RecType string // STAT
+	Bucket  string
+	Values  map[string]int
+}
+
+type MarketStats struct {
+	Contracts   map[string]map[string]int // Type -> Status -> count
+	WinningBids WinningStats
+	Bids        BidStats
+	Volume      map[string]VolumeStats // 2016-10 -> settled
+}
+
+type WinningStats struct {
+	Count       int
+	AvgBid      string
+	AvgAmount   string
+	AvgRatio    string // Winning bid in % of the posted Amount
+	MedianRatio string
+}
+
+type BidStats struct {
+	Contracts      int
+	Bids           int
+	AvgPerContract string
+}
+
+type VolumeStats struct {
+	Count  int
+	Amount int
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Add the deltas to the values of a counter, creating it on first use
+////////////////////////////////////////////////////////////////////////////
+func AdjustStat(stub shim.ChaincodeStubInterface, stat string, bucket string, deltas map[string]int) error {
+
+	keys := []string{stat, bucket}
+	buff, found, err := LookupLedger(stub, "StatsTable", keys)
+	if err != nil {
+		return err
+	}
+
+	counter := StatCounter{stat, "STAT", bucket, map[string]int{}}
+	if found {
+		if err := json.Unmarshal(buff, &counter); err != nil {
+			return err
+		}
+	}
+
+	for k, d := range deltas {
+		counter.Values[k] += d
+	}
+
+	buff, err = json.Marshal(counter)
+	if err != nil {
+		return err
+	}
+
+	if found {
+		err = ReplaceLedgerEntry(stub, "StatsTable", keys, buff)
+	} else {
+		err = UpdateLedger(stub, "StatsTable", keys, buff)
+	}
+	if err != nil {
+		Log(stub).Error("AdjustStat(): write error in StatsTable", "stat", stat, "bucket", bucket)
+	}
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////////
+// OnSave hook of CONTRACT
+// Moves the contract between the status counters of its Type and records
+// the winning bid when a bid is selected
+////////////////////////////////////////////////////////////////////////////
+func CountContract(stub shim.ChaincodeStubInterface, old interface{}, rec interface{}) error {
+
+	c := rec.(ContractObject)
+
+	if old == nil {
+		err := AdjustStat(stub, "BIDS", "TOTAL", map[string]int{"Contracts": 1})
+		if err != nil {
+			return err
+		}
+		return AdjustStat(stub, "CONTRACTS", c.Type, map[string]int{c.Status: 1})
+	}
+
+	o := old.(ContractObject)
+	if o.Type != c.Type || o.Status != c.Status {
+		err := AdjustStat(stub, "CONTRACTS", o.Type, map[string]int{o.Status: -1})
+		if err != nil {
+			return err
+		}
+		err = AdjustStat(stub, "CONTRACTS", c.Type, map[string]int{c.Status: 1})
+		if err != nil {
+			return err
+		}
+	}
+
+	// A bid was selected, or a reopened cycle dropped or replaced its award
+	if o.WorkerID != c.WorkerID || o.BidPrice != c.BidPrice || o.Amount != c.Amount {
+		err := CountAward(stub, o, -1)
+		if err != nil {
+			return err
+		}
+		return CountAward(stub, c, 1)
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Add (n = 1) or remove (n = -1) the winning bid of a contract
+// Contracts without a worker have no award to count
+////////////////////////////////////////////////////////////////////////////
+func CountAward(stub shim.ChaincodeStubInterface, c ContractObject, n int) error {
+
+	if c.WorkerID == "" {
+		return nil
+	}
+
+	bid, _ := strconv.Atoi(c.BidPrice)
+	amount, _ := strconv.Atoi(c.Amount)
+	ratio := 0.0
+	if amount > 0 {
+		ratio = float64(bid) * 100 / float64(amount)
+	}
+
+	err := AdjustStat(stub, "WINNING", "TOTAL", map[string]int{"Count": n, "Bids": n * bid, "Amounts": n * amount})
+	if err != nil {
+		return err
+	}
+	return AdjustStat(stub, "WINNING", "RATIO", map[string]int{strconv.FormatFloat(ratio, 'f', -1, 64): n})
+}
+
+////////////////////////////////////////////////////////////////////////////
+// OnSave hook of BID - counts new bids
+////////////////////////////////////////////////////////////////////////////
+func CountBid(stub shim.ChaincodeStubInterface, old interface{}, rec interface{}) error {
+
+	// A bid carried over to the next cycle of a series was counted on its first cycle
+	if old != nil || rec.(Bid).CarriedFrom != "" {
+		return nil
+	}
+	return AdjustStat(stub, "BIDS", "TOTAL", map[string]int{"Bids": 1})
+}
+
+////////////////////////////////////////////////////////////////////////////
+// OnSave hook of TRANSACTION - adds new payments to the month settled
+// Refunds, bond premiums and claims are not work and are left out
+////////////////////////////////////////////////////////////////////////////
+func CountTransaction(stub shim.ChaincodeStubInterface, old interface{}, rec interface{}) error {
+
+	at := rec.(ItemTransaction)
+	if old != nil || !IsWorkPayment(at.TransType) {
+		return nil
+	}
+
+	amount, _ := strconv.Atoi(at.TransactionAmount)
+	period := ContractPeriod(at.TransDate)
+
+	return AdjustStat(stub, "VOLUME", period[0]+"-"+period[1], map[string]int{"Count": 1, "Amount": amount})
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Recount every counter of StatsTable from the contracts, bids and transactions
+// Counters of a ledger written before StatsTable existed start from the records
+// this way - RebuildIndexes does not run the OnSave hooks. Can be run again safely
+// Only Auction House (AH) users can rebuild the statistics
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "RebuildStats", "Args":["STAT", "100"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+func RebuildStats(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 2 {
+		Log(stub).Debug("RebuildStats(): Incorrect number of arguments. Expecting 2")
+		return nil, errcode.New(errcode.ArgCount, "RebuildStats(): Incorrect number of arguments. Expecting 2 ")
+	}
+
+	err := ValidateAuctionHouse(stub, args[1], "RebuildStats")
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := RecountStats(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	Log(stub).Info("RebuildStats(): Recounted contracts", "count", count)
+	return []byte("RebuildStats(): Recounted " + strconv.Itoa(count) + " contracts"), nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Delete the counters and run the OnSave hooks again as if every contract,
+// bid and transaction were inserted. Returns the number of contracts
+////////////////////////////////////////////////////////////////////////////
+func RecountStats(stub shim.ChaincodeStubInterface) (int, error) {
+
+	for _, stat := range []string{"CONTRACTS", "WINNING", "BIDS", "VOLUME"} {
+		counters, err := GetStatCounters(stub, stat)
+		if err != nil {
+			return 0, err
+		}
+		for _, c := range counters {
+			err = DeleteFromLedger(stub, "StatsTable", []string{c.Stat, c.Bucket})
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	rows, err := GetList(stub, "ContractCatTable", []string{ContractNamespace})
+	if err != nil {
+		return 0, errcode.Wrapf(err, "RecountStats() operation failed. Error GetList: %s", err)
+	}
+
+	nCol := GetNumberOfKeys("ContractCatTable")
+	nBidCol := GetNumberOfKeys("BidTable")
+	nTranCol := GetNumberOfKeys("TransTable")
+	for _, row := range rows {
+		c, err := JSONtoAucReq(row.Columns[nCol].GetBytes())
+		if err != nil {
+			return 0, err
+		}
+
+		err = CountContract(stub, nil, c)
+		if err != nil {
+			return 0, err
+		}
+		err = CountAward(stub, c, 1)
+		if err != nil {
+			return 0, err
+		}
+
+		bidRows, err := GetList(stub, "BidTable", []string{c.ContractId})
+		if err != nil {
+			return 0, errcode.Wrapf(err, "RecountStats() operation failed. Error GetList: %s", err)
+		}
+		for _, bidRow := range bidRows {
+			bid, err := JSONtoBid(bidRow.Columns[nBidCol].GetBytes())
+			if err != nil {
+				return 0, err
+			}
+			err = CountBid(stub, nil, bid)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		tranRows, err := GetList(stub, "TransTable", []string{c.ContractId})
+		if err != nil {
+			return 0, errcode.Wrapf(err, "RecountStats() operation failed. Error GetList: %s", err)
+		}
+		for _, tranRow := range tranRows {
+			at, err := JSONtoTran(tranRow.Columns[nTranCol].GetBytes())
+			if err != nil {
+				return 0, err
+			}
+			err = CountTransaction(stub, nil, at)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return len(rows), nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////
+// Marketplace statistics for dashboards
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetMarketStats", "Args": []}'
+/////////////////////////////////////////////////////////////////////////////////////////
+func GetMarketStats(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	stats := MarketStats{Contracts: map[string]map[string]int{}, Volume: map[string]VolumeStats{}}
+
+	counters, err := GetStatCounters(stub, "CONTRACTS")
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range counters {
+		stats.Contracts[c.Bucket] = c.Values
+	}
+
+	counters, err = GetStatCounters(stub, "WINNING")
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range counters {
+		switch c.Bucket {
+		case "TOTAL":
+			n := c.Values["Count"]
+			stats.WinningBids.Count = n
+			if n > 0 {
+				stats.WinningBids.AvgBid = strconv.FormatFloat(float64(c.Values["Bids"])/float64(n), 'f', 2, 64)
+				stats.WinningBids.AvgAmount = strconv.FormatFloat(float64(c.Values["Amounts"])/float64(n), 'f', 2, 64)
+			}
+		case "RATIO":
+			stats.WinningBids.AvgRatio = MeanOfHistogram(c.Values)
+			stats.WinningBids.MedianRatio = MedianOfHistogram(c.Values)
+		}
+	}
+
+	counters, err = GetStatCounters(stub, "BIDS")
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range counters {
+		stats.Bids.Contracts = c.Values["Contracts"]
+		stats.Bids.Bids = c.Values["Bids"]
+		if stats.Bids.Contracts > 0 {
+			stats.Bids.AvgPerContract = strconv.FormatFloat(float64(stats.Bids.Bids)/float64(stats.Bids.Contracts), 'f', 2, 64)
+		}
+	}
+
+	counters, err = GetStatCounters(stub, "VOLUME")
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range counters {
+		stats.Volume[c.Bucket] = VolumeStats{c.Values["Count"], c.Values["Amount"]}
+	}
+
+	return json.Marshal(stats)
+}
+
+func GetStatCounters(stub shim.ChaincodeStubInterface, stat string) ([]StatCounter, error) {
+
+	rows, err := GetList(stub, "StatsTable", []string{stat})
+	if err != nil {
+		return nil, errcode.Wrapf(err, "GetStatCounters() operation failed. Error GetList: %s", err)
+	}
+
+	nCol := GetNumberOfKeys("StatsTable")
+
+	tlist := make([]StatCounter, len(rows))
+	for i := 0; i < len(rows); i++ {
+		if err := json.Unmarshal(rows[i].Columns[nCol].GetBytes(), &tlist[i]); err != nil {
+			Log(stub).Error("GetStatCounters(): Unmarshal error", "error", err)
+			return nil, errcode.Wrapf(err, "GetStatCounters() operation failed. %s", err)
+		}
+	}
+
+	return tlist, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Mean of a histogram of values -> counts
+////////////////////////////////////////////////////////////////////////////
+func MeanOfHistogram(hist map[string]int) string {
+
+	sum, total := 0.0, 0
+	for k, n := range hist {
+		v, err := strconv.ParseFloat(k, 64)
+		if err != nil || n <= 0 {
+			continue
+		}
+		sum += v * float64(n)
+		total += n
+	}
+	if total == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(sum/float64(total), 'f', 2, 64)
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Median of a histogram of values -> counts
+////////////////////////////////////////////////////////////////////////////
+func MedianOfHistogram(hist map[string]int) string {
+
+	var keys []string
+	total := 0
+	for k, n := range hist {
+		if _, err := strconv.ParseFloat(k, 64); err != nil || n <= 0 {
+			continue
+		}
+		keys = append(keys, k)
+		total += n
+	}
+	if total == 0 {
+		return ""
+	}
+	value := func(k string) float64 { v, _ := strconv.ParseFloat(k, 64); return v }
+	sort.Sort(byNumber(keys))
+
+	// Middle element, or the average of the two middle elements
+	at := func(pos int) float64 {
+		for _, k := range keys {
+			pos -= hist[k]
+			if pos < 0 {
+				return value(k)
+			}
+		}
+		return value(keys[len(keys)-1])
+	}
+
+	median := (at((total-1)/2) + at(total/2)) / 2
+	return strconv.FormatFloat(median, 'f', 2, 64)
+}
+
+// Histogram keys in numeric order
+type byNumber []string
+
+func (k byNumber) Len() int      { return len(k) }
+func (k byNumber) Swap(i, j int) { k[i], k[j] = k[j], k[i] }
+func (k byNumber) Less(i, j int) bool {
+	x, _ := strconv.ParseFloat(k[i], 64)
+	y, _ := strconv.ParseFloat(k[j], 64)
+	return x < y
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	tests := []struct {
+		hist      map[string]int
+		mean, med string
+	}{
+		{map[string]int{}, "", ""},
+		{map[string]int{"90": 1}, "90.00", "90.00"},
+		// Ratios keep their fraction, 2/3 of the Amount is not 66%
+		{map[string]int{"66.66666666666667": 2, "100": 1}, "77.78", "66.67"},
+		{map[string]int{"80": 1, "9.5": 1}, "44.75", "44.75"},
+		// Awards removed by a reopened cycle leave empty buckets behind
+		{map[string]int{"80": 0, "85": 1, "x": 3, "70": -1}, "85.00", "85.00"},
+	}
+	for _, tt := range tests {
+		if got := MeanOfHistogram(tt.hist); got != tt.mean {
+			t.Errorf("MeanOfHistogram(%v) = %q, want %q", tt.hist, got, tt.mean)
+		}
+		if got := MedianOfHistogram(tt.hist); got != tt.med {
+			t.Errorf("MedianOfHistogram(%v) = %q, want %q", tt.hist, got, tt.med)
+		}
+	}
+}
+
+func TestCountTransaction(t *testing.T) {
+	stub := newMemStub()
+	stub.initLedger(t)
+
+	for i, tr := range []struct{ transType, amount string }{
+		{"PAYMENT", "1000"}, {"PAYOUT", "500"}, {"REFUND", "300"}, {"PREMIUM", "50"}, {"CLAIM", "200"},
+	} {
+		at := ItemTransaction{ConractId: "1000", RecType: "POSTTRAN", TransactionId: "tx" + strconv.Itoa(i), TransType: tr.transType,
+			UserId: "200", TransDate: "2016-10-20 10:00:00", TransactionAmount: tr.amount}
+		if _, err := InsertRecord(stub, "TRANSACTION", at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counters, err := GetStatCounters(stub, "VOLUME")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counters) != 1 || counters[0].Bucket != "2016-10" || counters[0].Values["Count"] != 2 || counters[0].Values["Amount"] != 1500 {
+		t.Errorf("VOLUME = %+v, want 2 payments of 1500 in 2016-10", counters)
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/events"
+	"github.com/AkshayKulkarni03/hackathon/model"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// Subcontracting
+// The worker of an IN_PROGRESS contract can post child contracts for parts of the
+// work. The worker is the owner of the children and the budget of all children
+// together cannot exceed the Amount of the parent
+// A parent cannot be closed while one of its children is still running
+// SubcontractTable links every parent to its children
+///////////////////////////////////////////////////////////////////////////////////////
+
+type SubcontractEntry struct {
+	ParentId   string
+	RecType    string // SUBCONTRACT
+	ContractId string
+	PostTime   string
+}
+
+/////////////////////////////////////////////////////////////
+// A node of the tree returned by GetContractTree
+/////////////////////////////////////////////////////////////
+
+type ContractNode struct {
+	ContractId string
+	ParentId   string
+	Type       string
+	Amount     string
+	BidPrice   string
+	Status     string
+	UserID     string
+	WorkerID   string
+	Children   []ContractNode
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// The worker of a contract posts a child contract
+// Same arguments as PostRequest followed by the ContractId of the parent
+// The owner of the child (args[9]) must be the worker of the parent
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "PostSubcontract", "Args":["1001", "400", "10", "Fixed Price", "Painting", "Paint the fence", "Fence", "Net 30", "2016-10-05", "200", "CREATECONTR", "1000"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func PostSubcontract(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 12 {
+		Log(stub).Debug("PostSubcontract(): Incorrect number of arguments. Expecting 12")
+		return nil, errcode.New(errcode.ArgCount, "PostSubcontract(): Incorrect number of arguments. Expecting 12 ")
+	}
+
+	obj, err := model.CreateContract(args[0:11])
+	if err != nil {
+		return nil, err
+	}
+	child := ContractObject(obj)
+
+	parent, err := GetContractObject(stub, args[11])
+	if err != nil {
+		return nil, err
+	}
+
+	if parent.Status != "IN_PROGRESS" {
+		Log(stub).Debug("PostSubcontract(): Parent Contract is not IN_PROGRESS", "contractId", parent.ContractId)
+		return nil, errcode.New(errcode.InvalidState, "PostSubcontract(): Parent Contract is not IN_PROGRESS : " + parent.ContractId)
+	}
+
+	if parent.WorkerID != child.UserID {
+		Log(stub).Debug("PostSubcontract(): Only the worker of the parent Contract can subcontract", "userId", child.UserID)
+		return nil, errcode.New(errcode.NotAllowed, "PostSubcontract(): Only the worker of the parent Contract can subcontract : " + child.UserID)
+	}
+
+	// The children together cannot cost more than the parent
+	amount, err := strconv.Atoi(child.Amount)
+	if err != nil || amount <= 0 {
+		return nil, errcode.New(errcode.InvalidArgument, "PostSubcontract(): Amount should be a positive integer : " + child.Amount).WithField("Amount")
+	}
+
+	budget, err := strconv.Atoi(parent.Amount)
+	if err != nil {
+		return nil, errcode.New(errcode.Internal, "PostSubcontract(): Amount of the parent Contract is not an integer : " + parent.Amount)
+	}
+
+	children, err := GetChildContracts(stub, parent.ContractId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range children {
+		if c.Status == "CANCELLED" {
+			continue
+		}
+		used, _ := strconv.Atoi(c.Amount)
+		budget -= used
+	}
+
+	if amount > budget {
+		Log(stub).Debug("PostSubcontract(): Amount exceeds the remaining budget of the parent", "amount", amount, "budget", budget)
+		return nil, errcode.Errorf(errcode.InvalidArgument, "PostSubcontract(): Amount exceeds the remaining budget of the parent Contract : %d", budget).WithField("Amount")
+	}
+
+	child.ParentId = parent.ContractId
+
+	buff, err := PostContract(stub, child)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := SubcontractEntry{parent.ContractId, "SUBCONTRACT", child.ContractId, time.Now().Format("2006-01-02 15:04:05")}
+	ebuff, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	err = UpdateLedger(stub, "SubcontractTable", []string{parent.ContractId, child.ContractId}, ebuff)
+	if err != nil {
+		Log(stub).Error("PostSubcontract(): write error while inserting record")
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.SubcontractPosted, child.UserID, ContractEventOf(child))
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Returns an error if a child of the contract is still running
+// Children are finished once they are CLOSED, CANCELLED or RESOLVED
+////////////////////////////////////////////////////////////////////////////
+func CheckChildrenFinished(stub shim.ChaincodeStubInterface, contractId string) error {
+
+	children, err := GetChildContracts(stub, contractId)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range children {
+		switch c.Status {
+		case "CLOSED", "CANCELLED", "RESOLVED":
+		default:
+			Log(stub).Debug("CheckChildrenFinished(): Child Contract is still", "status", c.Status, "contractId", c.ContractId)
+			return errcode.New(errcode.InvalidState, "CheckChildrenFinished(): Child Contract is still " + c.Status + " : " + c.ContractId)
+		}
+	}
+	return nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////
+// Get a contract with all of its children, grandchildren etc.
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetContractTree", "Args": ["1000"]}'
+/////////////////////////////////////////////////////////////////////////////////////////
+func GetContractTree(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) < 1 {
+		Log(stub).Debug("GetContractTree(): Incorrect number of arguments. Expecting 1")
+		return nil, errcode.New(errcode.ArgCount, "GetContractTree(): Incorrect number of arguments. Expecting 1 ")
+	}
+
+	contract, err := GetContractObject(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := BuildContractTree(stub, contract)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonRows, _ := json.Marshal(tree)
+	return jsonRows, nil
+}
+
+func BuildContractTree(stub shim.ChaincodeStubInterface, contract ContractObject) (ContractNode, error) {
+
+	node := ContractNode{ContractId: contract.ContractId, ParentId: contract.ParentId, Type: contract.Type,
+		Amount: contract.Amount, BidPrice: contract.BidPrice, Status: contract.Status,
+		UserID: contract.UserID, WorkerID: contract.WorkerID}
+
+	children, err := GetChildContracts(stub, contract.ContractId)
+	if err != nil {
+		return node, err
+	}
+
+	for _, c := range children {
+		child, err := BuildContractTree(stub, c)
+		if err != nil {
+			return node, err
+		}
+		node.Children = append(node.Children, child)
+	}
+
+	return node, nil
+}
+
+func GetChildContracts(stub shim.ChaincodeStubInterface, parentId string) ([]ContractObject, error) {
+
+	rows, err := GetList(stub, "SubcontractTable", []string{parentId})
+	if err != nil {
+		return nil, errcode.Wrapf(err, "GetChildContracts() operation failed. Error GetList: %s", err)
+	}
+
+	nCol := GetNumberOfKeys("SubcontractTable")
+
+	tlist := make([]ContractObject, len(rows))
+	for i := 0; i < len(rows); i++ {
+		var entry SubcontractEntry
+		if err := json.Unmarshal(rows[i].Columns[nCol].GetBytes(), &entry); err != nil {
+			Log(stub).Error("GetChildContracts(): Unmarshal error", "error", err)
+			return nil, errcode.Wrapf(err, "GetChildContracts() operation failed. %s", err)
+		}
+		tlist[i], err = GetContractObject(stub, entry.ContractId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tlist, nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/events"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// Team bids
+// A consortium of users can bid together. The bidder lists the members with their
+// percentage share of the revenue, the shares must add up to 100
+// The bidder signs by placing the bid, every other member co-signs with CoSignBid
+// A team bid can only be selected once all members have signed
+// Payments on the bid are split into one transaction per member
+///////////////////////////////////////////////////////////////////////////////////////
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// A member of a team bid co-signs the bid
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "CoSignBid", "Args":["1111", "BID", "3", "401"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func CoSignBid(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 4 {
+		Log(stub).Debug("CoSignBid(): Incorrect number of arguments. Expecting 4")
+		return nil, errcode.New(errcode.ArgCount, "CoSignBid(): Incorrect number of arguments. Expecting 4 ")
+	}
+
+	_, err := ValidateMember(stub, args[3])
+	if err != nil {
+		Log(stub).Debug("CoSignBid(): Failed Member not registered on the block-chain", "userId", args[3])
+		return nil, err
+	}
+
+	contract, err := GetContractObject(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if contract.Status != "OPEN" {
+		return nil, errcode.New(errcode.InvalidState, "CoSignBid(): Cannot sign Bid as Contract is not OPEN : " + args[0])
+	}
+
+	bid, err := GetBidObject(stub, args[0], args[2])
+	if err != nil {
+		return nil, err
+	}
+
+	signed := false
+	for i := range bid.Members {
+		if bid.Members[i].UserID != args[3] {
+			continue
+		}
+		if bid.Members[i].Signed {
+			return nil, errcode.New(errcode.AlreadyExists, "CoSignBid(): Member has already signed the Bid : " + args[3])
+		}
+		bid.Members[i].Signed = true
+		bid.Members[i].SignTime = time.Now().Format("2006-01-02 15:04:05")
+		signed = true
+	}
+
+	if !signed {
+		Log(stub).Debug("CoSignBid(): User is not a member of the team bid", "userId", args[3])
+		return nil, errcode.New(errcode.NotAllowed, "CoSignBid(): User is not a member of the team bid : " + args[3])
+	}
+
+	buff, err := ReplaceRecord(stub, "BID", bid)
+	if err != nil {
+		Log(stub).Error("CoSignBid(): write error while replacing record")
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.BidCoSigned, args[3], BidEventOf(bid))
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+//////////////////////////////////////////////////////////
+// True if every member of a team bid has signed
+// Bids from a single bidder are always signed
+//////////////////////////////////////////////////////////
+func BidFullySigned(bid Bid) bool {
+
+	for _, m := range bid.Members {
+		if !m.Signed {
+			return false
+		}
+	}
+	return true
+}
+
+//////////////////////////////////////////////////////////
+// True if the user placed the bid or is a member of the team
+//////////////////////////////////////////////////////////
+func OnBidTeam(bid Bid, userId string) bool {
+
+	if bid.UserID == userId {
+		return true
+	}
+	for _, m := range bid.Members {
+		if m.UserID == userId {
+			return true
+		}
+	}
+	return false
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Split a transaction on a team bid into one transaction per member
+// Each member gets its share of the amount, the rounding remainder goes to
+// the bidder who placed the bid. Transactions on a single bid are returned as is
+////////////////////////////////////////////////////////////////////////////
+func SplitTransaction(at ItemTransaction, bid Bid) ([]ItemTransaction, error) {
+
+	if len(bid.Members) == 0 {
+		return []ItemTransaction{at}, nil
+	}
+
+	amount, err := strconv.Atoi(at.TransactionAmount)
+	if err != nil {
+		return nil, errcode.New(errcode.InvalidArgument, "SplitTransaction(): Transaction Amount should be an integer : " + at.TransactionAmount).WithField("TransactionAmount")
+	}
+
+	trans := make([]ItemTransaction, len(bid.Members))
+	lead, rest := 0, amount
+	for i, m := range bid.Members {
+		share, _ := strconv.Atoi(m.Share)
+		part := amount * share / 100
+		rest -= part
+
+		trans[i] = at
+		trans[i].TransactionId = at.TransactionId + "-" + strconv.Itoa(i+1)
+		trans[i].UserId = m.UserID
+		trans[i].TransactionAmount = strconv.Itoa(part)
+		if m.UserID == bid.UserID {
+			lead = i
+		}
+	}
+
+	part, _ := strconv.Atoi(trans[lead].TransactionAmount)
+	trans[lead].TransactionAmount = strconv.Itoa(part + rest)
+
+	return trans, nil
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/events"
+	"github.com/AkshayKulkarni03/hackathon/model"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+///////////////////////////////////////////////////////////////////////////////////////
+// A ContractTemplate holds the parts of a contract that repeat for a job type
+// Text fields may contain placeholders such as {{site}} that are filled in
+// when a contract is created with PostRequestFromTemplate
+// Posting a template with an existing TemplateId adds a new version, older
+// versions are kept so contracts can always be checked against their template
+///////////////////////////////////////////////////////////////////////////////////////
+
+type ContractTemplate struct {
+	TemplateId             string
+	RecType                string // TEMPLATE
+	Version                string
+	UserID                 string // Author of the template
+	Type                   string
+	BusinessRule           string
+	Terms                  string
+	RequirementDescription string
+	Placeholders           []string
+	CreationDate           string
+}
+
+var placeholderExp = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Post a new Template or a new version of an existing one
+// Only the author of the first version can add versions
+// Example
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "PostTemplate", "Args":["10", "TEMPLATE", "100", "Maintenance", "Fixed price", "Work at {{site}} within {{days}} days", "Yearly check of {{site}}"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func PostTemplate(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	tmpl, err := CreateTemplate(args[0:])
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = ValidateMember(stub, tmpl.UserID)
+	if err != nil {
+		Log(stub).Debug("PostTemplate(): Failed Author not registered on the block-chain", "userId", tmpl.UserID)
+		return nil, err
+	}
+
+	versions, err := GetTemplateVersions(stub, tmpl.TemplateId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) > 0 && versions[0].UserID != tmpl.UserID {
+		Log(stub).Debug("PostTemplate(): Only the author can add versions to template", "templateId", tmpl.TemplateId)
+		return nil, errcode.New(errcode.NotAllowed, "PostTemplate(): Only the author can add versions to template : " + tmpl.TemplateId)
+	}
+	tmpl.Version = strconv.Itoa(len(versions) + 1)
+
+	buff, err := TemplatetoJSON(tmpl)
+	if err != nil {
+		Log(stub).Error("PostTemplate(): Failed Cannot create object buffer for write", "templateId", tmpl.TemplateId)
+		return nil, errcode.New(errcode.Internal, "PostTemplate(): Failed Cannot create object buffer for write : " + tmpl.TemplateId)
+	}
+
+	err = UpdateLedger(stub, "TemplateTable", []string{tmpl.TemplateId, tmpl.Version}, buff)
+	if err != nil {
+		Log(stub).Error("PostTemplate(): write error while inserting record")
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.TemplatePosted, tmpl.UserID, events.TemplateEvent{TemplateId: tmpl.TemplateId, Version: tmpl.Version, Type: tmpl.Type})
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+func CreateTemplate(args []string) (ContractTemplate, error) {
+
+	var tmpl ContractTemplate
+
+	// Check there are 7 Arguments - Version, Placeholders and CreationDate are computed
+	if len(args) != 7 {
+		logger.Debug("CreateTemplate(): Incorrect number of arguments. Expecting 7")
+		return tmpl, errcode.New(errcode.ArgCount, "CreateTemplate(): Incorrect number of arguments. Expecting 7 ")
+	}
+
+	_, err := strconv.Atoi(args[0])
+	if err != nil {
+		return tmpl, errcode.New(errcode.InvalidArgument, "CreateTemplate(): Template ID should be an integer").WithField("TemplateId")
+	}
+
+	tmpl = ContractTemplate{TemplateId: args[0], RecType: args[1], UserID: args[2], Type: args[3], BusinessRule: args[4],
+		Terms: args[5], RequirementDescription: args[6], CreationDate: time.Now().Format("2006-01-02 15:04:05")}
+
+	// Collect the distinct placeholder names used in all text fields
+	seen := map[string]bool{}
+	for _, text := range []string{tmpl.Type, tmpl.BusinessRule, tmpl.Terms, tmpl.RequirementDescription} {
+		for _, m := range placeholderExp.FindAllStringSubmatch(text, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				tmpl.Placeholders = append(tmpl.Placeholders, m[1])
+			}
+		}
+	}
+	sort.Strings(tmpl.Placeholders)
+
+	logger.Debug("CreateTemplate(): Template Object", "record", tmpl)
+	return tmpl, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+// Create a Contract from a Template
+// Version may be left empty to use the latest version of the template
+// The last argument is a JSON object with a value for every placeholder
+// Args : ContractId, RecType, TemplateId, Version, Amount, Duration, Description, CreationDate, UserID, Values
+// ./peer chaincode invoke -l golang -n mycc -c '{"Function": "PostRequestFromTemplate", "Args":["1001", "CREATECONTR", "10", "", "500", "30", "Spring check", "2016-10-01", "100", "{\"site\":\"Amsterdam\",\"days\":\"5\"}"]}'
+/////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+func PostRequestFromTemplate(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) != 10 {
+		Log(stub).Debug("PostRequestFromTemplate(): Incorrect number of arguments. Expecting 10")
+		return nil, errcode.New(errcode.ArgCount, "PostRequestFromTemplate(): Incorrect number of arguments. Expecting 10 ")
+	}
+
+	tmpl, err := GetTemplateObject(stub, args[2], args[3])
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	if err := json.Unmarshal([]byte(args[9]), &values); err != nil {
+		return nil, errcode.New(errcode.InvalidArgument, "PostRequestFromTemplate(): Placeholder values should be a JSON object : " + err.Error()).WithField("TemplateValues")
+	}
+
+	filled, err := FillTemplate(tmpl, values)
+	if err != nil {
+		return nil, err
+	}
+
+	// Same argument layout as PostRequest so the contract is validated the same way
+	obj, err := model.CreateContract([]string{args[0], args[4], args[5], filled.BusinessRule, filled.Type,
+		filled.RequirementDescription, args[6], filled.Terms, args[7], args[8], args[1]})
+	if err != nil {
+		return nil, err
+	}
+	contractObject := ContractObject(obj)
+
+	contractObject.TemplateId = tmpl.TemplateId
+	contractObject.TemplateVersion = tmpl.Version
+	contractObject.TemplateValues = args[9]
+
+	buff, err := PostContract(stub, contractObject)
+	if err != nil {
+		return nil, err
+	}
+
+	err = EmitEvent(stub, events.ContractPosted, contractObject.UserID, ContractEventOf(contractObject))
+	if err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+// Replace the placeholders of a Template with values
+// Every placeholder of the template must be given a value
+////////////////////////////////////////////////////////////////////////////
+func FillTemplate(tmpl ContractTemplate, values map[string]string) (ContractTemplate, error) {
+
+	for _, name := range tmpl.Placeholders {
+		if _, ok := values[name]; !ok {
+			return tmpl, errcode.New(errcode.InvalidArgument, "FillTemplate(): Missing value for placeholder : " + name).WithField("TemplateValues")
+		}
+	}
+
+	fill := func(text string) string {
+		return placeholderExp.ReplaceAllStringFunc(text, func(m string) string {
+			return values[placeholderExp.FindStringSubmatch(m)[1]]
+		})
+	}
+
+	tmpl.Type = fill(tmpl.Type)
+	tmpl.BusinessRule = fill(tmpl.BusinessRule)
+	tmpl.Terms = fill(tmpl.Terms)
+	tmpl.RequirementDescription = fill(tmpl.RequirementDescription)
+	return tmpl, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////
+// Check whether the terms of a Contract still match the Template it came from
+// The template version is filled in again with the values recorded on the contract
+// and compared field by field
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "VerifyContractTemplate", "Args": ["1001"]}'
+/////////////////////////////////////////////////////////////////////////////////////////
+func VerifyContractTemplate(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) < 1 {
+		Log(stub).Debug("VerifyContractTemplate(): Incorrect number of arguments. Expecting 1")
+		return nil, errcode.New(errcode.ArgCount, "VerifyContractTemplate(): Incorrect number of arguments. Expecting 1 ")
+	}
+
+	contract, err := GetContractObject(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if contract.TemplateId == "" {
+		return nil, errcode.New(errcode.InvalidState, "VerifyContractTemplate(): Contract was not created from a template : " + args[0])
+	}
+
+	tmpl, err := GetTemplateObject(stub, contract.TemplateId, contract.TemplateVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	if err := json.Unmarshal([]byte(contract.TemplateValues), &values); err != nil {
+		Log(stub).Error("VerifyContractTemplate(): Unmarshal error of the template values", "contractId", args[0], "error", err)
+		return nil, errcode.Wrapf(err, "VerifyContractTemplate(): Cannot read the template values of contract %s : %s", args[0], err)
+	}
+	filled, err := FillTemplate(tmpl, values)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	if filled.Type != contract.Type {
+		changed = append(changed, "Type")
+	}
+	if filled.BusinessRule != contract.BusinessRule {
+		changed = append(changed, "BusinessRule")
+	}
+	if filled.Terms != contract.Terms {
+		changed = append(changed, "Terms")
+	}
+	if filled.RequirementDescription != contract.RequirementDescription {
+		changed = append(changed, "RequirementDescription")
+	}
+
+	result := struct {
+		ContractId      string
+		TemplateId      string
+		TemplateVersion string
+		Changed         bool
+		ChangedFields   []string
+	}{contract.ContractId, contract.TemplateId, contract.TemplateVersion, len(changed) > 0, changed}
+
+	return json.Marshal(result)
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////
+// Retrieve a Template - all versions, or one version if it is given
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetTemplate", "Args": ["10"]}'
+// ./peer chaincode query -l golang -n mycc -c '{"Function": "GetTemplate", "Args": ["10", "2"]}'
+/////////////////////////////////////////////////////////////////////////////////////////
+func GetTemplate(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+
+	if len(args) < 1 {
+		Log(stub).Debug("GetTemplate(): Incorrect number of arguments. Expecting 1")
+		return nil, errcode.New(errcode.ArgCount, "GetTemplate(): Incorrect number of arguments. Expecting 1 ")
+	}
+
+	if len(args) > 1 {
+		tmpl, err := GetTemplateObject(stub, args[0], args[1])
+		if err != nil {
+			return nil, err
+		}
+		return TemplatetoJSON(tmpl)
+	}
+
+	versions, err := GetTemplateVersions(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	jsonRows, _ := json.Marshal(versions)
+	return jsonRows, nil
+}
+
+//////////////////////////////////////////////////////////
+// Get a version of a Template, the latest one if version is empty
+//////////////////////////////////////////////////////////
+func GetTemplateObject(stub shim.ChaincodeStubInterface, templateId string, version string) (ContractTemplate, error) {
+
+	if version == "" {
+		versions, err := GetTemplateVersions(stub, templateId)
+		if err != nil {
+			return ContractTemplate{}, err
+		}
+		if len(versions) == 0 {
+			return ContractTemplate{}, errcode.New(errcode.NotFound, "GetTemplateObject(): Cannot find Template record : " + templateId).WithField("TemplateId")
+		}
+		return versions[len(versions)-1], nil
+	}
+
+	Avalbytes, err := QueryLedger(stub, "TemplateTable", []string{templateId, version})
+	if err != nil {
+		Log(stub).Debug("GetTemplateObject(): Cannot find Template record", "templateId", templateId, "version", version)
+		return ContractTemplate{}, errcode.New(errcode.NotFound, "GetTemplateObject(): Cannot find Template record : " + templateId + " version " + version).WithField("TemplateVersion")
+	}
+
+	return JSONtoTemplate(Avalbytes)
+}
+
+//////////////////////////////////////////////////////////
+// All versions of a Template, oldest first
+//////////////////////////////////////////////////////////
+func GetTemplateVersions(stub shim.ChaincodeStubInterface, templateId string) ([]ContractTemplate, error) {
+
+	rows, err := GetList(stub, "TemplateTable", []string{templateId})
+	if err != nil {
+		return nil, errcode.Wrapf(err, "GetTemplateVersions() operation failed. Error GetList: %s", err)
+	}
+
+	nCol := GetNumberOfKeys("TemplateTable")
+
+	tlist := make([]ContractTemplate, len(rows))
+	for i := 0; i < len(rows); i++ {
+		ts := rows[i].Columns[nCol].GetBytes()
+		tmpl, err := JSONtoTemplate(ts)
+		if err != nil {
+			Log(stub).Error("GetTemplateVersions(): Unmarshal error", "error", err)
+			return nil, errcode.Wrapf(err, "GetTemplateVersions() operation failed. %s", err)
+		}
+		tlist[i] = tmpl
+	}
+
+	// Rows come in the order of the encoded keys, see CompareKeys, which is not
+	// the numeric order once versions differ in length
+	sort.Sort(byVersion(tlist))
+	return tlist, nil
+}
+
+// Templates in the numeric order of their Version
+type byVersion []ContractTemplate
+
+func (t byVersion) Len() int      { return len(t) }
+func (t byVersion) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
+func (t byVersion) Less(i, j int) bool {
+	vi, _ := strconv.Atoi(t[i].Version)
+	vj, _ := strconv.Atoi(t[j].Version)
+	return vi < vj
+}
+
+//////////////////////////////////////////////////////////
+// Converts a Template to a JSON String
+//////////////////////////////////////////////////////////
+func TemplatetoJSON(tmpl ContractTemplate) ([]byte, error) {
+
+	ajson, err := json.Marshal(tmpl)
+	if err != nil {
+		logger.Error("TemplatetoJSON(): error", "error", err)
+		return nil, err
+	}
+	return ajson, nil
+}
+
+//////////////////////////////////////////////////////////
+// Converts a JSON String to a Template
+//////////////////////////////////////////////////////////
+func JSONtoTemplate(areq []byte) (ContractTemplate, error) {
+
+	tmpl := ContractTemplate{}
+	err := json.Unmarshal(areq, &tmpl)
+	if err != nil {
+		logger.Error("JSONtoTemplate(): error", "error", err)
+		return tmpl, err
+	}
+	return tmpl, err
+}
@@ -0,0 +1,108 @@
+// Command blobgateway stores contract deliverables off-chain.
+//
+// Clients upload a deliverable here, then record the returned digest, size
+// and URI on the contract with the PostDeliverable invoke. Anyone holding a
+// copy of the blob can hash it through /verify and compare the result with
+// the VerifyDeliverable query.
+//
+//	POST /blobs          store the request body, returns the Ref as JSON
+//	GET  /blobs/{digest} download a stored blob
+//	POST /verify         hash the request body, returns the Ref as JSON
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/AkshayKulkarni03/hackathon/blobstore"
+)
+
+func main() {
+	addr := flag.String("addr", ":3001", "listen address")
+	root := flag.String("root", "./data/blobs", "directory holding the blobs")
+	maxSize := flag.Int64("max-size", 512<<20, "largest accepted upload in bytes")
+	flag.Parse()
+
+	store, err := blobstore.NewFileStore(*root)
+	if err != nil {
+		log.Fatalf("blobgateway: cannot open store: %s", err)
+	}
+
+	log.Printf("blobgateway: serving %s on %s", *root, *addr)
+	log.Fatal(http.ListenAndServe(*addr, newHandler(store, *maxSize)))
+}
+
+// newHandler serves the store. Routes are plain paths dispatched on the
+// method: without a go.mod the command builds in GOPATH mode, where
+// http.ServeMux does not understand "METHOD /path" patterns.
+func newHandler(store blobstore.Store, maxSize int64) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/blobs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			notAllowed(w, "POST")
+			return
+		}
+		ref, err := store.Put(http.MaxBytesReader(w, r.Body, maxSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusCreated, ref)
+	})
+
+	mux.HandleFunc("/blobs/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" && r.Method != "HEAD" {
+			notAllowed(w, "GET")
+			return
+		}
+		digest := strings.TrimPrefix(r.URL.Path, "/blobs/")
+		if digest == "" || strings.Contains(digest, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		rc, err := store.Get(blobstore.FileScheme + digest)
+		if errors.Is(err, blobstore.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rc.Close()
+		w.Header().Set("Content-Type", "application/octet-stream")
+		io.Copy(w, rc)
+	})
+
+	mux.HandleFunc("/verify", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			notAllowed(w, "POST")
+			return
+		}
+		digest, size, err := blobstore.Digest(http.MaxBytesReader(w, r.Body, maxSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusOK, blobstore.Ref{Digest: digest, Size: size})
+	})
+
+	return mux
+}
+
+func notAllowed(w http.ResponseWriter, method string) {
+	w.Header().Set("Allow", method)
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AkshayKulkarni03/hackathon/blobstore"
+)
+
+func TestHandler(t *testing.T) {
+	store, err := blobstore.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(newHandler(store, 8))
+	defer srv.Close()
+
+	do := func(method, path, body string) (int, string) {
+		req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(b)
+	}
+
+	status, body := do("POST", "/blobs", "hello")
+	var ref blobstore.Ref
+	if status != http.StatusCreated || json.Unmarshal([]byte(body), &ref) != nil || ref.Size != 5 {
+		t.Fatalf("POST /blobs = %d %s", status, body)
+	}
+
+	tests := []struct {
+		method, path, body string
+		wantStatus         int
+		wantBody           string
+	}{
+		{"GET", "/blobs/" + ref.Digest, "", http.StatusOK, "hello"},
+		{"GET", "/blobs/" + strings.Repeat("0", 64), "", http.StatusNotFound, ""},
+		{"GET", "/blobs/", "", http.StatusNotFound, ""},
+		{"GET", "/blobs", "", http.StatusMethodNotAllowed, ""},
+		{"DELETE", "/blobs/" + ref.Digest, "", http.StatusMethodNotAllowed, ""},
+		{"POST", "/blobs", "more than 8 bytes", http.StatusBadRequest, ""},
+		{"POST", "/verify", "hello", http.StatusOK, ref.Digest},
+		{"GET", "/verify", "", http.StatusMethodNotAllowed, ""},
+	}
+	for _, tt := range tests {
+		status, body := do(tt.method, tt.path, tt.body)
+		if status != tt.wantStatus || !strings.Contains(body, tt.wantBody) {
+			t.Errorf("%s %s = %d %q, want %d %q", tt.method, tt.path, status, body, tt.wantStatus, tt.wantBody)
+		}
+	}
+}
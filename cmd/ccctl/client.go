@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/AkshayKulkarni03/hackathon/gateway"
+	"github.com/AkshayKulkarni03/hackathon/model"
+)
+
+// client submits the commands and prints their results.
+type client struct {
+	ctx       context.Context
+	t         gateway.Transport
+	ledger    string // file of the in-memory ledger, empty on a peer
+	json      bool
+	out       io.Writer
+	requestID string // client request ID of invokes, see model.RequestIDSeparator
+}
+
+// printer prints the result of a function as a table.
+type printer func(w io.Writer, result []byte) error
+
+// openLedger switches c to an in-memory ledger kept in path. A missing file
+// is an empty ledger.
+func (c *client) openLedger(path string) error {
+	m := gateway.NewMemory()
+	f, err := os.Open(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		defer f.Close()
+		if err := m.Load(f); err != nil {
+			return fmt.Errorf("cannot read ledger %s: %s", path, err)
+		}
+	}
+	c.t, c.ledger = m, path
+	return nil
+}
+
+// saveLedger writes the in-memory ledger back to its file.
+func (c *client) saveLedger() error {
+	tmp, err := os.CreateTemp(filepath.Dir(c.ledger), ".ccctl-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := c.t.(*gateway.Memory).Save(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.ledger)
+}
+
+// invoke checks args, when check is set, then invokes function and prints
+// its result.
+func (c *client) invoke(function string, args []string, check func([]string) error, show printer) error {
+	if check != nil {
+		if err := check(args); err != nil {
+			return err
+		}
+	}
+
+	result, err := c.t.Invoke(c.ctx, model.WithRequestID(function, c.requestID), args)
+	if err != nil {
+		return err
+	}
+	if c.ledger != "" {
+		if err := c.saveLedger(); err != nil {
+			return fmt.Errorf("cannot save ledger %s: %s", c.ledger, err)
+		}
+	} else {
+		show = printTx
+	}
+	return c.print(result, show)
+}
+
+// query runs function and prints its result.
+func (c *client) query(function string, args []string, show printer) error {
+	result, err := c.t.Query(c.ctx, function, args)
+	if err != nil {
+		return err
+	}
+	return c.print(result, show)
+}
+
+func (c *client) print(result []byte, show printer) error {
+	if c.json {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, result, "", "  "); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		_, err := buf.WriteTo(c.out)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	if err := show(tw, result); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
+
+func jsonString(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
@@ -0,0 +1,394 @@
+// Command ccctl runs the everyday chaincode operations from a terminal.
+//
+//	ccctl [flags] user add      -id 100 -name "Ashley Hart" -type TR
+//	ccctl [flags] contract post -id 1000 -amount 5000 -duration 30 -type IT -owner 100 ...
+//	ccctl [flags] contract list [-open] [-type IT] [-period 2016-10]
+//	ccctl [flags] bid place     -contract 1000 -bid 1 -user 200 -price 4500 [-members 200:60,300:40]
+//	ccctl [flags] bid list      -contract 1000
+//	ccctl [flags] offer counter -contract 1000 -bid 1 -user 100 -price 4000 [-duration 20] [-terms ...]
+//	ccctl [flags] offer accept  -contract 1000 -bid 1 -user 200
+//	ccctl [flags] offer reject  -contract 1000 -bid 1 -user 200
+//	ccctl [flags] offer list    -contract 1000 -bid 1
+//	ccctl [flags] award         -contract 1000 -bid 1 -owner 100
+//	ccctl [flags] settle        -contract 1000 -tx 1 -payee 200 -amount 4500 -bid 1
+//	ccctl [flags] close         -contract 1000 -owner 100
+//	ccctl [flags] batch         -file ops.json
+//
+// Every command builds the argument list of a chaincode function and checks
+// it with the constructor the chaincode runs on it, see package model, so
+// malformed requests fail before they are submitted. Results are printed as
+// tables, or as the JSON returned by the chaincode with -json.
+//
+// The chaincode only awards a bid whose negotiation ended with an accepted
+// offer: before award, the owner or the bidder accepts the bid, or the last
+// counter offer of the other party, with offer accept.
+//
+// Commands go to the chaincode deployed on -peer. With -ledger they run
+// against an embedded in-memory ledger instead, loaded from and saved to
+// the given file, which is handy for demos without a network. A peer only
+// acknowledges an invoke with its transaction ID; the in-memory ledger
+// returns the record written.
+//
+// An invoke run with -request-id can be rerun after a timeout: the
+// chaincode runs it once and returns the first result to the retry.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/gateway"
+	"github.com/AkshayKulkarni03/hackathon/model"
+)
+
+const usage = `usage: ccctl [flags] command [command flags]
+
+commands:
+  user add        register a user (PostUser)
+  contract post   post a contract (PostRequest)
+  contract list   list contracts (ListContracts, GetListOfOpenContracts)
+  bid place       bid on an OPEN contract (PostBid)
+  bid list        list the bids on a contract (GetListOfBids)
+  offer counter   counter the last offer on a bid (CounterOffer)
+  offer accept    accept the last offer on a bid (AcceptOffer)
+  offer reject    reject the last offer on a bid (RejectOffer)
+  offer list      list the offers of the negotiation on a bid (GetNegotiation)
+  award           select the winning bid, its offer must be accepted (SelectBidder)
+  settle          post a payment on the selected bid (PostTransaction)
+  close           close a contract once the work is done (CloseContract)
+  batch           run a JSON array of {function, args} in one transaction (Batch)
+
+Run ccctl command -h for the flags of a command.
+
+flags:
+`
+
+// command is one ccctl command.
+type command struct {
+	name string
+	run  func(c *client, fs *flag.FlagSet, args []string) error
+}
+
+var commands = []command{
+	{"user add", userAdd},
+	{"contract post", contractPost},
+	{"contract list", contractList},
+	{"bid place", bidPlace},
+	{"bid list", bidList},
+	{"offer counter", offerCounter},
+	{"offer accept", offerAccept},
+	{"offer reject", offerReject},
+	{"offer list", offerList},
+	{"award", award},
+	{"settle", settle},
+	{"close", closeContract},
+	{"batch", batch},
+}
+
+func main() {
+	peer := flag.String("peer", "http://localhost:7050", "REST endpoint of the peer")
+	chaincode := flag.String("chaincode", "mycc", "name of the deployed chaincode")
+	user := flag.String("user", "emma1", "enrolled user the requests run as")
+	ledger := flag.String("ledger", "", "use an in-memory ledger saved to this file instead of the peer")
+	jsonOut := flag.Bool("json", false, "print the JSON returned by the chaincode")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout of a request to the peer")
+	requestID := flag.String("request-id", "", "client request ID of an invoke, rerun with the same ID to retry it safely")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	cmd, args, ok := lookup(flag.Args())
+	if !ok {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	c := &client{ctx: ctx, json: *jsonOut, out: os.Stdout, requestID: *requestID}
+	if *ledger != "" {
+		if err := c.openLedger(*ledger); err != nil {
+			fatal(err)
+		}
+	} else {
+		c.t = &gateway.PeerTransport{URL: *peer, Chaincode: *chaincode, User: *user}
+	}
+
+	fs := flag.NewFlagSet("ccctl "+cmd.name, flag.ExitOnError)
+	if err := cmd.run(c, fs, args); err != nil {
+		fatal(err)
+	}
+}
+
+// lookup finds the command named by the first one or two words of args.
+func lookup(args []string) (command, []string, bool) {
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) >= len(words) && strings.Join(args[:len(words)], " ") == cmd.name {
+			return cmd, args[len(words):], true
+		}
+	}
+	return command{}, nil, false
+}
+
+// fatal prints err, with its code when the chaincode sent one, and exits.
+func fatal(err error) {
+	var e *errcode.Error
+	if errors.As(err, &e) {
+		fmt.Fprintf(os.Stderr, "ccctl: %s (%s)\n", err, e.Code)
+	} else {
+		fmt.Fprintln(os.Stderr, "ccctl:", err)
+	}
+	os.Exit(1)
+}
+
+// required fails unless every named flag of fs has been given a value.
+func required(fs *flag.FlagSet, names ...string) error {
+	var missing []string
+	for _, name := range names {
+		if fs.Lookup(name).Value.String() == "" {
+			missing = append(missing, "-"+name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// check returns a check of an argument list by a model constructor.
+func check[T any](create func([]string) (T, error)) func([]string) error {
+	return func(args []string) error {
+		_, err := create(args)
+		return err
+	}
+}
+
+func userAdd(c *client, fs *flag.FlagSet, args []string) error {
+	id := fs.String("id", "", "integer ID of the user")
+	name := fs.String("name", "", "name")
+	userType := fs.String("type", "", "AH, TR, AP, IN, BK or SH")
+	address := fs.String("address", "", "address")
+	phone := fs.String("phone", "", "phone number")
+	email := fs.String("email", "", "email address")
+	bank := fs.String("bank", "", "bank")
+	account := fs.String("account", "", "bank account number")
+	fs.Parse(args)
+	if err := required(fs, "id", "name", "type"); err != nil {
+		return err
+	}
+
+	return c.invoke("PostUser", []string{*id, "USER", *name, *userType, *address, *phone, *email, *bank, *account},
+		check(model.CreateUser), printUser)
+}
+
+func contractPost(c *client, fs *flag.FlagSet, args []string) error {
+	id := fs.String("id", "", "integer ID of the contract")
+	amount := fs.String("amount", "", "budget of the contract")
+	duration := fs.String("duration", "", "duration in days")
+	rule := fs.String("rule", "", "business rule")
+	contractType := fs.String("type", "", "type of work")
+	requirement := fs.String("requirement", "", "requirement description")
+	description := fs.String("description", "", "description")
+	terms := fs.String("terms", "", "terms")
+	date := fs.String("date", time.Now().Format("2006-01-02 15:04:05"), "creation date")
+	owner := fs.String("owner", "", "UserID of the owner")
+	fs.Parse(args)
+	if err := required(fs, "id", "amount", "duration", "type", "owner"); err != nil {
+		return err
+	}
+
+	return c.invoke("PostRequest", []string{*id, *amount, *duration, *rule, *contractType, *requirement,
+		*description, *terms, *date, *owner, "CREATECONTR"}, check(model.CreateContract), printContract)
+}
+
+func contractList(c *client, fs *flag.FlagSet, args []string) error {
+	open := fs.Bool("open", false, "only contracts OPEN for bids")
+	contractType := fs.String("type", "", "only contracts of this type")
+	period := fs.String("period", "", "only contracts created in this year or month, e.g. 2016-10")
+	pageSize, token := pageFlags(fs)
+	fs.Parse(args)
+
+	switch {
+	case *open:
+		return c.query("GetListOfOpenContracts", []string{*pageSize, *token}, printContracts)
+	case *contractType != "":
+		return c.query("ListContracts", []string{"TYPE", *contractType, *pageSize, *token}, printContracts)
+	case *period != "":
+		return c.query("ListContracts", []string{"PERIOD", *period, *pageSize, *token}, printContracts)
+	}
+	return c.query("ListContracts", []string{"ALL", "", *pageSize, *token}, printContracts)
+}
+
+func bidPlace(c *client, fs *flag.FlagSet, args []string) error {
+	contract := fs.String("contract", "", "ContractId")
+	bidNo := fs.String("bid", "", "integer number of the bid")
+	user := fs.String("user", "", "UserID of the bidder")
+	price := fs.String("price", "", "integer bid price")
+	members := fs.String("members", "", "team members and their share, e.g. 200:60,300:40, or the JSON array")
+	fs.Parse(args)
+	if err := required(fs, "contract", "bid", "user", "price"); err != nil {
+		return err
+	}
+
+	bidArgs := []string{*contract, "BID", *bidNo, "", *user, *price}
+	if *members != "" {
+		m, err := parseMembers(*members)
+		if err != nil {
+			return err
+		}
+		bidArgs = append(bidArgs, m)
+	}
+	return c.invoke("PostBid", bidArgs, check(model.CreateBidObject), printBid)
+}
+
+// parseMembers turns the user:share list of -members into the JSON array
+// expected by PostBid. A JSON array is passed through.
+func parseMembers(s string) (string, error) {
+	if strings.HasPrefix(strings.TrimSpace(s), "[") {
+		return s, nil
+	}
+	var members []model.BidMember
+	for _, m := range strings.Split(s, ",") {
+		user, share, ok := strings.Cut(strings.TrimSpace(m), ":")
+		if !ok {
+			return "", fmt.Errorf("members should be a list of user:share: %s", s)
+		}
+		members = append(members, model.BidMember{UserID: user, Share: share})
+	}
+	return jsonString(members)
+}
+
+func bidList(c *client, fs *flag.FlagSet, args []string) error {
+	contract := fs.String("contract", "", "ContractId")
+	pageSize, token := pageFlags(fs)
+	fs.Parse(args)
+	if err := required(fs, "contract"); err != nil {
+		return err
+	}
+
+	return c.query("GetListOfBids", []string{*contract, *pageSize, *token}, printBids)
+}
+
+func offerCounter(c *client, fs *flag.FlagSet, args []string) error {
+	contract := fs.String("contract", "", "ContractId")
+	bidNo := fs.String("bid", "", "number of the bid")
+	user := fs.String("user", "", "UserID of the owner of the contract or of the bidder")
+	price := fs.String("price", "", "integer price")
+	duration := fs.String("duration", "", "duration in days, that of the last offer by default")
+	terms := fs.String("terms", "", "terms, those of the last offer by default")
+	fs.Parse(args)
+	if err := required(fs, "contract", "bid", "user", "price"); err != nil {
+		return err
+	}
+
+	return c.invoke("CounterOffer", []string{*contract, "OFFER", *bidNo, *user, *price, *duration, *terms}, nil, printOffer)
+}
+
+func offerAccept(c *client, fs *flag.FlagSet, args []string) error {
+	return answerOffer(c, fs, args, "AcceptOffer")
+}
+
+func offerReject(c *client, fs *flag.FlagSet, args []string) error {
+	return answerOffer(c, fs, args, "RejectOffer")
+}
+
+// answerOffer accepts or rejects the last offer on a bid with function.
+func answerOffer(c *client, fs *flag.FlagSet, args []string, function string) error {
+	contract := fs.String("contract", "", "ContractId")
+	bidNo := fs.String("bid", "", "number of the bid")
+	user := fs.String("user", "", "UserID of the owner of the contract or of the bidder")
+	fs.Parse(args)
+	if err := required(fs, "contract", "bid", "user"); err != nil {
+		return err
+	}
+
+	return c.invoke(function, []string{*contract, "OFFER", *bidNo, *user}, nil, printOffer)
+}
+
+func offerList(c *client, fs *flag.FlagSet, args []string) error {
+	contract := fs.String("contract", "", "ContractId")
+	bidNo := fs.String("bid", "", "number of the bid")
+	pageSize, token := pageFlags(fs)
+	fs.Parse(args)
+	if err := required(fs, "contract", "bid"); err != nil {
+		return err
+	}
+
+	return c.query("GetNegotiation", []string{*contract, *bidNo, *pageSize, *token}, printOffers)
+}
+
+func award(c *client, fs *flag.FlagSet, args []string) error {
+	contract := fs.String("contract", "", "ContractId")
+	bidNo := fs.String("bid", "", "number of the winning bid, with an accepted offer")
+	owner := fs.String("owner", "", "UserID of the owner of the contract")
+	fs.Parse(args)
+	if err := required(fs, "contract", "bid", "owner"); err != nil {
+		return err
+	}
+
+	return c.invoke("SelectBidder", []string{*contract, "BID", *bidNo, *owner}, nil, printContract)
+}
+
+func settle(c *client, fs *flag.FlagSet, args []string) error {
+	contract := fs.String("contract", "", "ContractId")
+	tx := fs.String("tx", "", "TransactionId")
+	transType := fs.String("type", "PAYMENT", "type of the transaction")
+	payee := fs.String("payee", "", "UserID of the payee")
+	date := fs.String("date", time.Now().Format("2006-01-02"), "date of settlement")
+	amount := fs.String("amount", "", "integer amount")
+	bidNo := fs.String("bid", "", "selected bid of the contract")
+	fs.Parse(args)
+	if err := required(fs, "contract", "tx", "payee", "amount", "bid"); err != nil {
+		return err
+	}
+
+	return c.invoke("PostTransaction", []string{*contract, "POSTTRAN", *tx, *transType, *payee, *date, *amount, *bidNo},
+		check(model.CreateTransactionRequest), printTransactions)
+}
+
+func closeContract(c *client, fs *flag.FlagSet, args []string) error {
+	contract := fs.String("contract", "", "ContractId")
+	owner := fs.String("owner", "", "UserID of the owner of the contract")
+	fs.Parse(args)
+	if err := required(fs, "contract", "owner"); err != nil {
+		return err
+	}
+
+	return c.invoke("CloseContract", []string{*contract, "CLOSECONTRACT", *owner}, nil, printContract)
+}
+
+func batch(c *client, fs *flag.FlagSet, args []string) error {
+	file := fs.String("file", "", "JSON array of {function, args}, - for stdin")
+	fs.Parse(args)
+	if err := required(fs, "file"); err != nil {
+		return err
+	}
+
+	var ops []byte
+	var err error
+	if *file == "-" {
+		ops, err = io.ReadAll(os.Stdin)
+	} else {
+		ops, err = os.ReadFile(*file)
+	}
+	if err != nil {
+		return err
+	}
+	return c.invoke("Batch", []string{"BATCH", string(ops)}, check(model.CreateBatch), printBatch)
+}
+
+func pageFlags(fs *flag.FlagSet) (pageSize, token *string) {
+	return fs.String("page-size", "50", "items per page, 500 at most"),
+		fs.String("token", "", "next token printed with the previous page")
+}
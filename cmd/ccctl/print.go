@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/AkshayKulkarni03/hackathon/gateway"
+)
+
+// page is a page of a list as returned by the list queries.
+type page[T any] struct {
+	Items     []T    `json:"items"`
+	NextToken string `json:"nextToken"`
+}
+
+func printTx(w io.Writer, result []byte) error {
+	var tx gateway.TxResult
+	if err := json.Unmarshal(result, &tx); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "submitted transaction %s\n", tx.TxID)
+	return err
+}
+
+// table prints a header line and one line per row, then the token of the
+// next page if there is one.
+func table(w io.Writer, header []string, rows [][]string, next string) error {
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	if next != "" {
+		fmt.Fprintf(w, "\nmore with -token %s\n", next)
+	}
+	return nil
+}
+
+var contractHeader = []string{"ID", "TYPE", "AMOUNT", "DAYS", "STATUS", "OWNER", "WORKER", "PRICE", "CREATED"}
+
+func contractRow(c gateway.Contract) []string {
+	return []string{c.ContractId, c.Type, c.Amount, c.Duration, c.Status, c.UserID, c.WorkerID, c.BidPrice, c.CreationDate}
+}
+
+func printContract(w io.Writer, result []byte) error {
+	var c gateway.Contract
+	if err := json.Unmarshal(result, &c); err != nil {
+		return err
+	}
+	return table(w, contractHeader, [][]string{contractRow(c)}, "")
+}
+
+func printContracts(w io.Writer, result []byte) error {
+	var p page[gateway.Contract]
+	if err := json.Unmarshal(result, &p); err != nil {
+		return err
+	}
+	rows := make([][]string, len(p.Items))
+	for i, c := range p.Items {
+		rows[i] = contractRow(c)
+	}
+	return table(w, contractHeader, rows, p.NextToken)
+}
+
+var bidHeader = []string{"CONTRACT", "BID", "BIDDER", "PRICE", "TIME", "MEMBERS"}
+
+func bidRow(b gateway.Bid) []string {
+	members := make([]string, len(b.Members))
+	for i, m := range b.Members {
+		members[i] = m.UserID + ":" + m.Share
+		if !m.Signed {
+			members[i] += " (unsigned)"
+		}
+	}
+	return []string{b.ContractId, b.BidNo, b.UserID, b.BidPrice, b.BidTime, strings.Join(members, ", ")}
+}
+
+func printBid(w io.Writer, result []byte) error {
+	var b gateway.Bid
+	if err := json.Unmarshal(result, &b); err != nil {
+		return err
+	}
+	return table(w, bidHeader, [][]string{bidRow(b)}, "")
+}
+
+func printBids(w io.Writer, result []byte) error {
+	var p page[gateway.Bid]
+	if err := json.Unmarshal(result, &p); err != nil {
+		return err
+	}
+	rows := make([][]string, len(p.Items))
+	for i, b := range p.Items {
+		rows[i] = bidRow(b)
+	}
+	return table(w, bidHeader, rows, p.NextToken)
+}
+
+var offerHeader = []string{"CONTRACT", "BID", "SEQ", "BY", "ROLE", "ACTION", "PRICE", "DURATION", "TIME"}
+
+func offerRow(o gateway.Offer) []string {
+	return []string{o.ContractId, o.BidNo, o.Seq, o.UserID, o.Role, o.Action, o.Price, o.Duration, o.OfferTime}
+}
+
+func printOffer(w io.Writer, result []byte) error {
+	var o gateway.Offer
+	if err := json.Unmarshal(result, &o); err != nil {
+		return err
+	}
+	return table(w, offerHeader, [][]string{offerRow(o)}, "")
+}
+
+func printOffers(w io.Writer, result []byte) error {
+	var p page[gateway.Offer]
+	if err := json.Unmarshal(result, &p); err != nil {
+		return err
+	}
+	rows := make([][]string, len(p.Items))
+	for i, o := range p.Items {
+		rows[i] = offerRow(o)
+	}
+	return table(w, offerHeader, rows, p.NextToken)
+}
+
+func printUser(w io.Writer, result []byte) error {
+	var u gateway.User
+	if err := json.Unmarshal(result, &u); err != nil {
+		return err
+	}
+	return table(w, []string{"ID", "NAME", "TYPE", "EMAIL", "RATING"},
+		[][]string{{u.UserID, u.Name, u.UserType, u.Email, u.Rating}}, "")
+}
+
+func printTransactions(w io.Writer, result []byte) error {
+	var trans []gateway.Transaction
+	if err := json.Unmarshal(result, &trans); err != nil {
+		return err
+	}
+	rows := make([][]string, len(trans))
+	for i, at := range trans {
+		rows[i] = []string{at.ConractId, at.TransactionId, at.TransType, at.UserId, at.TransactionAmount, at.TransDate, at.BidNo}
+	}
+	return table(w, []string{"CONTRACT", "TRANSACTION", "TYPE", "PAYEE", "AMOUNT", "DATE", "BID"}, rows, "")
+}
+
+func printBatch(w io.Writer, result []byte) error {
+	var results []gateway.BatchResult
+	if err := json.Unmarshal(result, &results); err != nil {
+		return err
+	}
+	rows := make([][]string, len(results))
+	for i, r := range results {
+		rows[i] = []string{fmt.Sprint(i), r.Function, string(r.Result)}
+	}
+	return table(w, []string{"#", "FUNCTION", "RESULT"}, rows, "")
+}
@@ -0,0 +1,273 @@
+// Command ccledger exports the tables of the chaincode to a JSON Lines file
+// and imports such a file again, for disaster recovery, test fixtures and
+// moving to a new network.
+//
+//	ccledger [flags] export -user 100 [-o ledger.jsonl]
+//	ccledger [flags] import -user 100 ledger.jsonl
+//	ccledger [flags] import -ledger ledger.json ledger.jsonl
+//
+// export pages through the ExportTables query of the chaincode on -peer and
+// writes every row, one model.ExportLine per line. import sends the rows to
+// the ImportRows invoke of a freshly deployed chaincode, in transactions of
+// at most model.MaxBatchBytes, in the order of the file. With -ledger it
+// builds instead a new in-memory ledger for ccctl -ledger and the gateway;
+// rows of the tables that ledger does not model are skipped.
+//
+// Both run as an Auction House user. The first import into a ledger without
+// users must be run by one of the users of the file.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/gateway"
+	"github.com/AkshayKulkarni03/hackathon/model"
+)
+
+const usage = `usage: ccledger [flags] command [command flags]
+
+commands:
+  export   write every table to a JSON Lines file (ExportTables)
+  import   load a JSON Lines file into a fresh ledger (ImportRows)
+
+Run ccledger command -h for the flags of a command.
+
+flags:
+`
+
+func main() {
+	peer := flag.String("peer", "http://localhost:7050", "REST endpoint of the peer")
+	chaincode := flag.String("chaincode", "mycc", "name of the deployed chaincode")
+	user := flag.String("user", "emma1", "enrolled user the requests run as")
+	timeout := flag.Duration("timeout", 10*time.Minute, "timeout of the whole export or import")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	t := &gateway.PeerTransport{URL: *peer, Chaincode: *chaincode, User: *user}
+	fs := flag.NewFlagSet("ccledger "+flag.Arg(0), flag.ExitOnError)
+	var err error
+	switch flag.Arg(0) {
+	case "export":
+		err = export(ctx, t, fs, flag.Args()[1:])
+	case "import":
+		err = importFile(ctx, t, fs, flag.Args()[1:])
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+// fatal prints err, with its code when the chaincode sent one, and exits.
+func fatal(err error) {
+	var e *errcode.Error
+	if errors.As(err, &e) {
+		fmt.Fprintf(os.Stderr, "ccledger: %s (%s)\n", err, e.Code)
+	} else {
+		fmt.Fprintln(os.Stderr, "ccledger:", err)
+	}
+	os.Exit(1)
+}
+
+func export(ctx context.Context, t gateway.Transport, fs *flag.FlagSet, args []string) error {
+	userID := fs.String("user", "", "UserID of the Auction House user running the export")
+	out := fs.String("o", "-", "file to write, - for stdout")
+	pageSize := fs.String("page-size", "500", "rows per query, 500 at most")
+	fs.Parse(args)
+	if *userID == "" {
+		return errors.New("missing -user")
+	}
+
+	w := io.Writer(os.Stdout)
+	var f *os.File
+	if *out != "-" {
+		var err error
+		if f, err = os.CreateTemp(filepath.Dir(*out), ".ccledger-*"); err != nil {
+			return err
+		}
+		defer os.Remove(f.Name())
+		w = f
+	}
+
+	rows, err := exportRows(ctx, t, w, *userID, *pageSize)
+	if err != nil {
+		return err
+	}
+	if f != nil {
+		if err := f.Close(); err != nil {
+			return err
+		}
+		if err := os.Rename(f.Name(), *out); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(os.Stderr, "exported %d rows\n", rows)
+	return nil
+}
+
+// exportRows writes the rows of every page of ExportTables to w, without
+// the next page tokens, and returns their number.
+func exportRows(ctx context.Context, t gateway.Transport, w io.Writer, userID, pageSize string) (int, error) {
+	rows, token := 0, ""
+	for {
+		page, err := t.Query(ctx, "ExportTables", []string{userID, pageSize, token})
+		if err != nil {
+			return rows, err
+		}
+
+		token = ""
+		for _, line := range bytes.SplitAfter(page, []byte("\n")) {
+			var l model.ExportLine
+			if len(bytes.TrimSpace(line)) == 0 || json.Unmarshal(line, &l) != nil {
+				continue
+			}
+			if l.Table == "" {
+				token = l.NextToken
+				continue
+			}
+			if _, err := w.Write(line); err != nil {
+				return rows, err
+			}
+			rows++
+		}
+		if token == "" {
+			return rows, nil
+		}
+	}
+}
+
+func importFile(ctx context.Context, t gateway.Transport, fs *flag.FlagSet, args []string) error {
+	userID := fs.String("user", "", "UserID of the Auction House user running the import")
+	ledger := fs.String("ledger", "", "write a new in-memory ledger to this file instead of importing on the peer")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("expecting the file to import")
+	}
+
+	in, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if *ledger != "" {
+		return importMemory(in, *ledger)
+	}
+	if *userID == "" {
+		return errors.New("missing -user")
+	}
+	return importPeer(ctx, t, in, *userID)
+}
+
+// importPeer sends the rows of r to ImportRows, as many per transaction as
+// fit in model.MaxBatchBytes.
+func importPeer(ctx context.Context, t gateway.Transport, r io.Reader, userID string) error {
+	var chunk strings.Builder
+	sent, pending, txs := 0, 0, 0
+	flush := func() error {
+		if pending == 0 {
+			return nil
+		}
+		if _, err := t.Invoke(ctx, "ImportRows", []string{"IMPORT", userID, chunk.String()}); err != nil {
+			return errcode.Wrapf(err, "after %d rows: %s", sent, err)
+		}
+		chunk.Reset()
+		sent, pending = sent+pending, 0
+		txs++
+		return nil
+	}
+
+	err := model.ReadExport(r, func(line model.ExportLine) error {
+		b, err := json.Marshal(line)
+		if err != nil {
+			return err
+		}
+		if len(b)+1 > model.MaxBatchBytes {
+			return errcode.Errorf(errcode.InvalidArgument, "row of %s exceeds %d bytes", line.Table, model.MaxBatchBytes)
+		}
+		if chunk.Len()+len(b)+1 > model.MaxBatchBytes {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		chunk.Write(b)
+		chunk.WriteByte('\n')
+		pending++
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "imported %d rows in %d transactions\n", sent, txs)
+	return nil
+}
+
+// importMemory builds a new in-memory ledger from the rows of r and saves
+// it to path, which must not exist.
+func importMemory(r io.Reader, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s exists, import into a new ledger", path)
+	}
+
+	m := gateway.NewMemory()
+	imported, skipped := 0, map[string]int{}
+	err := model.ReadExport(r, func(line model.ExportLine) error {
+		ok, err := m.ImportRow(line.Table, model.ImportDetails(line))
+		if err != nil {
+			return errcode.Errorf(errcode.InvalidArgument, "row of %s: %s", line.Table, err)
+		}
+		if ok {
+			imported++
+		} else {
+			skipped[line.Table]++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := m.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "imported %d rows\n", imported)
+	for table, n := range skipped {
+		fmt.Fprintf(os.Stderr, "skipped %d rows of %s\n", n, table)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Command eventrelay forwards chaincode events to HTTP webhooks.
+//
+// The webhooks are listed in a JSON file:
+//
+//	[
+//	  {"URL": "https://example.com/hooks/bids", "Secret": "s3cr3t", "Events": ["BidderSelected"]},
+//	  {"URL": "http://localhost:4000/all", "Secret": "other"}
+//	]
+//
+// Every delivery is a POST of the event payload with the event name, a
+// delivery ID and the HMAC-SHA256 signature of the body in the
+// X-Contract-Event, X-Contract-Delivery and X-Contract-Signature headers.
+// Deliveries that still fail after the retries are appended to the
+// dead-letter file.
+//
+// Events are read from -source, a file of event payloads one per line.
+// With -follow the file is tailed for events appended to it.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/AkshayKulkarni03/hackathon/relay"
+)
+
+func main() {
+	source := flag.String("source", "./data/events.jsonl", "file of event payloads, one per line")
+	follow := flag.Bool("follow", false, "keep reading events appended to the source")
+	hooks := flag.String("hooks", "./hooks.json", "JSON file listing the webhooks")
+	deadLetter := flag.String("dead-letter", "./data/dead-letter.jsonl", "file receiving deliveries given up")
+	attempts := flag.Int("attempts", 5, "deliveries tried per event and webhook")
+	backoff := flag.Duration("backoff", time.Second, "wait before the first retry, doubled per retry")
+	maxBackoff := flag.Duration("max-backoff", time.Minute, "longest wait between retries")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout of a single delivery")
+	flag.Parse()
+
+	webhooks, err := readHooks(*hooks)
+	if err != nil {
+		log.Fatalf("eventrelay: cannot read webhooks: %s", err)
+	}
+
+	src, err := relay.OpenFileSource(*source)
+	if err != nil {
+		log.Fatalf("eventrelay: cannot open source: %s", err)
+	}
+	defer src.Close()
+	src.Follow = *follow
+
+	dl, err := relay.OpenFileDeadLetter(*deadLetter)
+	if err != nil {
+		log.Fatalf("eventrelay: cannot open dead-letter log: %s", err)
+	}
+	defer dl.Close()
+
+	d := &relay.Dispatcher{
+		Hooks:       webhooks,
+		Client:      &http.Client{Timeout: *timeout},
+		MaxAttempts: *attempts,
+		Backoff:     *backoff,
+		MaxBackoff:  *maxBackoff,
+		DeadLetter:  dl,
+		Logf:        log.Printf,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	log.Printf("eventrelay: relaying %s to %d webhooks", *source, len(webhooks))
+	if err := d.Run(ctx, src); err != nil && err != context.Canceled {
+		log.Fatalf("eventrelay: %s", err)
+	}
+}
+
+func readHooks(path string) ([]relay.Webhook, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var hooks []relay.Webhook
+	if err := json.Unmarshal(b, &hooks); err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
@@ -0,0 +1,69 @@
+// Command gateway serves the chaincode as a REST/JSON API, see package
+// gateway for the routes.
+//
+// By default requests go to the chaincode deployed on a peer. With -memory
+// they are served by an in-memory backend seeded with the users of -users,
+// a JSON array of users, for testing clients offline. -openapi prints the
+// OpenAPI document and exits.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/AkshayKulkarni03/hackathon/gateway"
+)
+
+func main() {
+	addr := flag.String("addr", ":3002", "listen address")
+	peer := flag.String("peer", "http://localhost:7050", "REST endpoint of the peer")
+	chaincode := flag.String("chaincode", "mycc", "name of the deployed chaincode")
+	user := flag.String("user", "emma1", "enrolled user the requests run as")
+	memory := flag.Bool("memory", false, "serve from an in-memory backend instead of a peer")
+	users := flag.String("users", "", "JSON file of users to seed the in-memory backend with")
+	openapi := flag.Bool("openapi", false, "print the OpenAPI document and exit")
+	flag.Parse()
+
+	if *openapi {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(gateway.OpenAPI()); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var t gateway.Transport = &gateway.PeerTransport{URL: *peer, Chaincode: *chaincode, User: *user}
+	if *memory {
+		m := gateway.NewMemory()
+		if *users != "" {
+			if err := seedUsers(m, *users); err != nil {
+				log.Fatalf("gateway: cannot read users: %s", err)
+			}
+		}
+		t = m
+		log.Printf("gateway: serving the in-memory backend on %s", *addr)
+	} else {
+		log.Printf("gateway: serving chaincode %s on %s as %s on %s", *chaincode, *peer, *user, *addr)
+	}
+
+	log.Fatal(http.ListenAndServe(*addr, gateway.New(t)))
+}
+
+func seedUsers(m *gateway.Memory, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var users []gateway.User
+	if err := json.Unmarshal(b, &users); err != nil {
+		return err
+	}
+	for _, u := range users {
+		m.AddUser(u)
+	}
+	return nil
+}
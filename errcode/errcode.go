@@ -0,0 +1,150 @@
+// Package errcode defines the errors returned by the contract marketplace
+// chaincode.
+//
+// Every error carries a stable Code clients can branch on, a human readable
+// Message, the offending Field when one argument is to blame and an
+// HTTP-like Class. Inside the chaincode an *Error reads like any other
+// error; Invoke and Query hand it to the peer with Encode, which turns it
+// into the JSON clients get back:
+//
+//	{"code":"NOT_FOUND","message":"GetContractObject(): Cannot find Contract record : 1000","field":"ContractId","class":404}
+//
+// Clients recover it with Parse.
+package errcode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Code identifies the kind of error. Codes are never renamed.
+type Code string
+
+// Codes.
+const (
+	ArgCount        Code = "ARG_COUNT"         // wrong number of arguments
+	InvalidArgument Code = "INVALID_ARGUMENT"  // malformed argument
+	InvalidRecType  Code = "INVALID_REC_TYPE"  // no known RecType among the arguments
+	UnknownFunction Code = "UNKNOWN_FUNCTION"  // no such invoke or query
+	NotFound        Code = "NOT_FOUND"         // the record does not exist
+	NotRegistered   Code = "NOT_REGISTERED"    // the user is not registered
+	NotAllowed      Code = "NOT_ALLOWED"       // the user may not do this
+	AlreadyExists   Code = "ALREADY_EXISTS"    // the record exists already
+	InvalidState    Code = "INVALID_STATE"     // the record is not in a state allowing this
+	RequestIDReused Code = "REQUEST_ID_REUSED" // the client request ID was used for another request
+	Internal        Code = "INTERNAL"          // ledger or encoding failure
+)
+
+// Class is the HTTP status an error maps to.
+type Class int
+
+// Classes.
+const (
+	BadRequest Class = 400
+	Forbidden  Class = 403
+	Missing    Class = 404
+	Conflict   Class = 409
+	Failure    Class = 500
+)
+
+var classes = map[Code]Class{
+	ArgCount:        BadRequest,
+	InvalidArgument: BadRequest,
+	InvalidRecType:  BadRequest,
+	UnknownFunction: BadRequest,
+	NotFound:        Missing,
+	NotRegistered:   Forbidden,
+	NotAllowed:      Forbidden,
+	AlreadyExists:   Conflict,
+	InvalidState:    Conflict,
+	RequestIDReused: Conflict,
+	Internal:        Failure,
+}
+
+// ClassOf returns the class of code, Failure for unknown codes.
+func ClassOf(code Code) Class {
+	if c, ok := classes[code]; ok {
+		return c
+	}
+	return Failure
+}
+
+// Error is a chaincode error.
+type Error struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"` // record field or argument to blame, e.g. ContractId or pageSize
+	Class   Class  `json:"class"`
+}
+
+// New returns an error with code and message.
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message, Class: ClassOf(code)}
+}
+
+// Errorf returns an error with code and a formatted message.
+func Errorf(code Code, format string, a ...interface{}) *Error {
+	return New(code, fmt.Sprintf(format, a...))
+}
+
+// Wrapf returns an error with a formatted message, usually quoting err,
+// and the code and field of err. Errors that are not an *Error are
+// Internal.
+func Wrapf(err error, format string, a ...interface{}) *Error {
+	e := From(err)
+	return &Error{Code: e.Code, Message: fmt.Sprintf(format, a...), Field: e.Field, Class: e.Class}
+}
+
+// WithField returns a copy of e blaming field.
+func (e *Error) WithField(field string) *Error {
+	c := *e
+	c.Field = field
+	return &c
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// From returns err, or the first *Error in its Unwrap chain, as an *Error.
+// An error that is not one is Internal. The chain is walked by hand as
+// errors.As is newer than the Go of the chaincode container.
+func From(err error) *Error {
+	for cause := err; cause != nil; {
+		if e, ok := cause.(*Error); ok {
+			return e
+		}
+		u, ok := cause.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		cause = u.Unwrap()
+	}
+	return New(Internal, err.Error())
+}
+
+// Encode returns err in the form handed to the peer: an error whose text is
+// the JSON encoding of From(err).
+func Encode(err error) error {
+	if err == nil {
+		return nil
+	}
+	b, _ := json.Marshal(From(err))
+	return errors.New(string(b))
+}
+
+// Parse recovers an error encoded by Encode from the error text returned
+// by a peer, which may wrap it in text of its own.
+func Parse(s string) (*Error, bool) {
+	i := strings.Index(s, `{"code":`)
+	if i < 0 {
+		return nil, false
+	}
+	var e Error
+	if err := json.NewDecoder(strings.NewReader(s[i:])).Decode(&e); err != nil || e.Code == "" {
+		return nil, false
+	}
+	return &e, true
+}
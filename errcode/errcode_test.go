@@ -0,0 +1,89 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeParse(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Error
+	}{
+		{
+			name: "coded",
+			err:  New(NotFound, "GetContractObject(): Cannot find Contract record : 1000").WithField("ContractId"),
+			want: Error{Code: NotFound, Message: "GetContractObject(): Cannot find Contract record : 1000", Field: "ContractId", Class: Missing},
+		},
+		{
+			name: "wrapped",
+			err:  Wrapf(New(AlreadyExists, "exists").WithField("UserID"), "PostUser(): %s", "exists"),
+			want: Error{Code: AlreadyExists, Message: "PostUser(): exists", Field: "UserID", Class: Conflict},
+		},
+		{
+			name: "in a chain",
+			err:  fmt.Errorf("gateway: %w", New(NotFound, "missing")),
+			want: Error{Code: NotFound, Message: "missing", Class: Missing},
+		},
+		{
+			name: "plain",
+			err:  errors.New("ledger unavailable"),
+			want: Error{Code: Internal, Message: "ledger unavailable", Class: Failure},
+		},
+		{
+			name: "quotes",
+			err:  Errorf(InvalidArgument, "bad value %q", `{"x":1}`),
+			want: Error{Code: InvalidArgument, Message: `bad value "{\"x\":1}"`, Class: BadRequest},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc := Encode(tt.err)
+			for _, text := range []string{enc.Error(), "Error when invoking chaincode: " + enc.Error() + " (exit 1)"} {
+				got, ok := Parse(text)
+				if !ok {
+					t.Fatalf("Parse(%q) failed", text)
+				}
+				if !reflect.DeepEqual(*got, tt.want) {
+					t.Errorf("Parse(%q) = %+v, want %+v", text, *got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeNil(t *testing.T) {
+	if err := Encode(nil); err != nil {
+		t.Errorf("Encode(nil) = %v, want nil", err)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for _, s := range []string{"", "plain failure", `{"message":"no code"}`, `{"code":""}`, `{"code":`} {
+		if e, ok := Parse(s); ok {
+			t.Errorf("Parse(%q) = %+v, want no error", s, e)
+		}
+	}
+}
+
+func TestClassOf(t *testing.T) {
+	tests := []struct {
+		code Code
+		want Class
+	}{
+		{ArgCount, BadRequest},
+		{NotRegistered, Forbidden},
+		{NotFound, Missing},
+		{InvalidState, Conflict},
+		{Internal, Failure},
+		{"NO_SUCH_CODE", Failure},
+	}
+	for _, tt := range tests {
+		if got := ClassOf(tt.code); got != tt.want {
+			t.Errorf("ClassOf(%s) = %d, want %d", tt.code, got, tt.want)
+		}
+	}
+}
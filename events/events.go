@@ -0,0 +1,256 @@
+// Package events defines the chaincode events emitted by the contract
+// marketplace chaincode.
+//
+// Every invoke that changes the ledger sets one chaincode event. The event
+// name is one of the constants below and the payload is the JSON encoding of
+// an Envelope whose Payload holds the event specific type listed next to the
+// name. Fabric keeps a single event per transaction, so an invoke that causes
+// several changes (closing a contract that spawns its next cycle, a ruling
+// that posts payments) reports them all in its one event, and a batch of
+// invokes reports the events of its operations in one BatchApplied event.
+//
+// Clients decode events with Decode:
+//
+//	env, payload, err := events.Decode(data)
+//	if bid, ok := payload.(*events.BidEvent); ok { ... }
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Version of the envelope and payload schema. It is increased whenever a
+// field changes meaning or is removed; adding fields keeps the version.
+const Version = 1
+
+// Event names.
+const (
+	UserRegistered       = "UserRegistered"       // UserEvent
+	ContractPosted       = "ContractPosted"       // ContractEvent
+	ContractCancelled    = "ContractCancelled"    // ContractEvent
+	ContractClosed       = "ContractClosed"       // ContractEvent
+	ContractUpdated      = "ContractUpdated"      // ContractEvent
+	SubcontractPosted    = "SubcontractPosted"    // ContractEvent
+	CycleReopened        = "CycleReopened"        // ContractEvent
+	BidPlaced            = "BidPlaced"            // BidEvent
+	BidCoSigned          = "BidCoSigned"          // BidEvent
+	BidderSelected       = "BidderSelected"       // ContractEvent
+	OfferMade            = "OfferMade"            // OfferEvent
+	PaymentPosted        = "PaymentPosted"        // PaymentEvent
+	DeliverablePosted    = "DeliverablePosted"    // DeliverableEvent
+	ReviewPosted         = "ReviewPosted"         // ReviewEvent
+	DisputeOpened        = "DisputeOpened"        // DisputeEvent
+	DisputeUpdated       = "DisputeUpdated"       // DisputeEvent
+	DisputeRuled         = "DisputeRuled"         // DisputeEvent
+	BondOffered          = "BondOffered"          // BondEvent
+	BondAccepted         = "BondAccepted"         // BondEvent
+	TemplatePosted       = "TemplatePosted"       // TemplateEvent
+	SkillPosted          = "SkillPosted"          // SkillEvent
+	SkillDeclared        = "SkillDeclared"        // SkillEvent
+	SkillEndorsed        = "SkillEndorsed"        // SkillEvent
+	ContractKeysMigrated = "ContractKeysMigrated" // MigrationEvent
+	BatchApplied         = "BatchApplied"         // BatchEvent
+)
+
+// Envelope is the payload of every chaincode event.
+type Envelope struct {
+	Version int
+	Name    string
+	TxID    string
+	Time    string // 2006-01-02 15:04:05
+	Actor   string // UserID of the user who invoked the change
+	Seq     int    `json:",omitempty"` // Position, from 1, of an operation event in its BatchEvent
+	Payload json.RawMessage
+}
+
+// UserEvent describes a newly registered user.
+type UserEvent struct {
+	UserID   string
+	Name     string
+	UserType string
+}
+
+// ContractEvent describes a contract after the change.
+type ContractEvent struct {
+	ContractId     string
+	Type           string
+	Amount         string
+	Status         string
+	OwnerID        string
+	WorkerID       string `json:",omitempty"`
+	BidNo          string `json:",omitempty"`
+	BidPrice       string `json:",omitempty"`
+	ParentId       string `json:",omitempty"`
+	SeriesId       string `json:",omitempty"`
+	Cycle          string `json:",omitempty"`
+	NextContractId string `json:",omitempty"` // Cycle created when a recurring contract closes
+}
+
+// BidEvent describes a bid and, for team bids, its members.
+type BidEvent struct {
+	ContractId string
+	BidNo      string
+	BidderID   string
+	Price      string
+	Members    []Member `json:",omitempty"`
+}
+
+// Member of a team bid.
+type Member struct {
+	UserID string
+	Share  string // Percentage of the revenue
+	Signed bool
+}
+
+// OfferEvent is one step of the negotiation on a bid.
+type OfferEvent struct {
+	ContractId string
+	BidNo      string
+	Seq        string
+	Role       string // OWNER / BIDDER
+	Action     string // COUNTER / ACCEPT / REJECT
+	Price      string
+	Duration   string
+}
+
+// PaymentEvent lists the transactions written to the ledger, one per payee.
+type PaymentEvent struct {
+	ContractId string
+	BidNo      string
+	Payments   []Payment
+}
+
+// Payment is one transaction row.
+type Payment struct {
+	TransactionId string
+	TransType     string
+	UserID        string
+	Amount        string
+}
+
+// DeliverableEvent describes a deliverable recorded on a contract.
+type DeliverableEvent struct {
+	ContractId string
+	Digest     string
+	Size       string
+	URI        string
+}
+
+// ReviewEvent describes a review and the new rating of the reviewee.
+type ReviewEvent struct {
+	ContractId string
+	ReviewerID string
+	RevieweeID string
+	Score      string
+	Rating     string
+}
+
+// DisputeEvent describes a dispute after the change. Payments are set when
+// a ruling settles the contract.
+type DisputeEvent struct {
+	ContractId   string
+	Status       string // OPEN / ASSIGNED / RULED
+	RaisedBy     string
+	ArbitratorID string    `json:",omitempty"`
+	Ruling       string    `json:",omitempty"`
+	WorkerShare  string    `json:",omitempty"`
+	Payments     []Payment `json:",omitempty"`
+}
+
+// BondEvent describes a bond after the change.
+type BondEvent struct {
+	ContractId string
+	InsurerID  string
+	InsuredID  string `json:",omitempty"`
+	Premium    string
+	Coverage   string
+	Status     string
+}
+
+// TemplateEvent describes a new template version.
+type TemplateEvent struct {
+	TemplateId string
+	Version    string
+	Type       string
+}
+
+// SkillEvent describes a change of the skill taxonomy or of a user's skills.
+type SkillEvent struct {
+	SkillId    string
+	UserID     string `json:",omitempty"`
+	EndorserID string `json:",omitempty"`
+}
+
+// MigrationEvent lists the contracts moved by a ledger migration.
+type MigrationEvent struct {
+	Contracts []string
+}
+
+// BatchEvent lists, in order, the events of the operations of a batch
+// invoke, which share its transaction. Their Seq tells them apart.
+type BatchEvent struct {
+	Events []Envelope
+}
+
+// payloads maps event names to their payload types.
+var payloads = map[string]func() interface{}{
+	UserRegistered:       func() interface{} { return new(UserEvent) },
+	ContractPosted:       func() interface{} { return new(ContractEvent) },
+	ContractCancelled:    func() interface{} { return new(ContractEvent) },
+	ContractClosed:       func() interface{} { return new(ContractEvent) },
+	ContractUpdated:      func() interface{} { return new(ContractEvent) },
+	SubcontractPosted:    func() interface{} { return new(ContractEvent) },
+	CycleReopened:        func() interface{} { return new(ContractEvent) },
+	BidPlaced:            func() interface{} { return new(BidEvent) },
+	BidCoSigned:          func() interface{} { return new(BidEvent) },
+	BidderSelected:       func() interface{} { return new(ContractEvent) },
+	OfferMade:            func() interface{} { return new(OfferEvent) },
+	PaymentPosted:        func() interface{} { return new(PaymentEvent) },
+	DeliverablePosted:    func() interface{} { return new(DeliverableEvent) },
+	ReviewPosted:         func() interface{} { return new(ReviewEvent) },
+	DisputeOpened:        func() interface{} { return new(DisputeEvent) },
+	DisputeUpdated:       func() interface{} { return new(DisputeEvent) },
+	DisputeRuled:         func() interface{} { return new(DisputeEvent) },
+	BondOffered:          func() interface{} { return new(BondEvent) },
+	BondAccepted:         func() interface{} { return new(BondEvent) },
+	TemplatePosted:       func() interface{} { return new(TemplateEvent) },
+	SkillPosted:          func() interface{} { return new(SkillEvent) },
+	SkillDeclared:        func() interface{} { return new(SkillEvent) },
+	SkillEndorsed:        func() interface{} { return new(SkillEvent) },
+	ContractKeysMigrated: func() interface{} { return new(MigrationEvent) },
+	BatchApplied:         func() interface{} { return new(BatchEvent) },
+}
+
+// Encode builds the JSON envelope of an event.
+func Encode(name, txID, time, actor string, payload interface{}) ([]byte, error) {
+	if _, ok := payloads[name]; !ok {
+		return nil, fmt.Errorf("events: unknown event %q", name)
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(Envelope{Version: Version, Name: name, TxID: txID, Time: time, Actor: actor, Payload: raw})
+}
+
+// Decode parses an event payload and returns the envelope together with a
+// pointer to the typed payload of the event.
+func Decode(data []byte) (Envelope, interface{}, error) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return env, nil, err
+	}
+	if env.Version > Version {
+		return env, nil, fmt.Errorf("events: unsupported version %d", env.Version)
+	}
+	newPayload, ok := payloads[env.Name]
+	if !ok {
+		return env, nil, fmt.Errorf("events: unknown event %q", env.Name)
+	}
+	payload := newPayload()
+	if err := json.Unmarshal(env.Payload, payload); err != nil {
+		return env, nil, err
+	}
+	return env, payload, nil
+}
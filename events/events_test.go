@@ -0,0 +1,70 @@
+package events
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload interface{}
+	}{
+		{UserRegistered, &UserEvent{UserID: "100", Name: "Ashley Hart", UserType: "TR"}},
+		{ContractClosed, &ContractEvent{ContractId: "1000", Type: "IT", Amount: "5000", Status: "CLOSED", OwnerID: "100",
+			WorkerID: "200", SeriesId: "1000", Cycle: "1", NextContractId: "1000002"}},
+		{BidPlaced, &BidEvent{ContractId: "1000", BidNo: "1", BidderID: "400", Price: "2500",
+			Members: []Member{{UserID: "400", Share: "60", Signed: true}, {UserID: "401", Share: "40"}}}},
+		{OfferMade, &OfferEvent{ContractId: "1000", BidNo: "1", Seq: "2", Role: "BIDDER", Action: "ACCEPT", Price: "900", Duration: "20"}},
+		{DisputeRuled, &DisputeEvent{ContractId: "1000", Status: "RULED", RaisedBy: "100", ArbitratorID: "300",
+			Payments: []Payment{{TransactionId: "t1", TransType: "PAYMENT", UserID: "200", Amount: "450"}}}},
+		{ContractKeysMigrated, &MigrationEvent{Contracts: []string{"1000", "1001"}}},
+		{BatchApplied, &BatchEvent{Events: []Envelope{{Version: Version, Name: BidPlaced, TxID: "tx1",
+			Payload: json.RawMessage(`{"ContractId":"1000","BidNo":"1","BidderID":"200","Price":"1200"}`)}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Encode(tt.name, "tx1", "2016-10-18 10:00:00", "100", tt.payload)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			env, payload, err := Decode(data)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if env.Version != Version || env.Name != tt.name || env.TxID != "tx1" ||
+				env.Time != "2016-10-18 10:00:00" || env.Actor != "100" {
+				t.Errorf("envelope = %+v", env)
+			}
+			if !reflect.DeepEqual(payload, tt.payload) {
+				t.Errorf("payload = %+v, want %+v", payload, tt.payload)
+			}
+		})
+	}
+}
+
+func TestEncodeUnknown(t *testing.T) {
+	if _, err := Encode("NoSuchEvent", "tx1", "", "", struct{}{}); err == nil {
+		t.Error("Encode of an unknown event succeeded")
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not json", `BidPlaced`},
+		{"newer version", `{"Version":99,"Name":"BidPlaced","Payload":{}}`},
+		{"unknown event", `{"Version":1,"Name":"NoSuchEvent","Payload":{}}`},
+		{"bad payload", `{"Version":1,"Name":"BidPlaced","Payload":[1,2]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Decode([]byte(tt.data)); err == nil {
+				t.Errorf("Decode(%s) succeeded", tt.data)
+			}
+		})
+	}
+}
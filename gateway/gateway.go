@@ -0,0 +1,189 @@
+// Package gateway exposes the chaincode as a REST/JSON API.
+//
+// Every route in Routes maps an HTTP operation onto one chaincode function
+// and builds its argument list from the path, the query string and the JSON
+// body of the request. The chaincode is reached through a Transport: a peer
+// in production, the in-memory Memory backend for offline testing. Errors
+// are answered with an Error body and the HTTP status of the class of the
+// error code sent by the chaincode, see package errcode.
+//
+//	GET  /contracts                           ListContracts
+//	POST /contracts                           PostRequest
+//	POST /contracts/search                    ViewContracts
+//	GET  /contracts/{id}                      GetContract
+//	POST /contracts/{id}/cancel               CancelContract
+//	POST /contracts/{id}/close                CloseContract
+//	GET  /contracts/{id}/bids                 GetListOfBids
+//	POST /contracts/{id}/bids                 PostBid
+//	GET  /contracts/{id}/bids/{bidNo}/offers  GetNegotiation
+//	POST /contracts/{id}/bids/{bidNo}/offers  CounterOffer
+//	POST /contracts/{id}/bids/{bidNo}/accept  AcceptOffer
+//	POST /contracts/{id}/bids/{bidNo}/reject  RejectOffer
+//	POST /contracts/{id}/bids/{bidNo}/select  SelectBidder
+//	POST /contracts/{id}/transactions         PostTransaction
+//	POST /users                               PostUser
+//	GET  /users/{id}                          GetUser
+//	POST /batch                               Batch
+//	GET  /openapi.json                        the OpenAPI document
+package gateway
+
+//go:generate sh -c "go run ../cmd/gateway -openapi > openapi.json"
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/model"
+)
+
+// MaxBodySize is the largest request body accepted.
+const MaxBodySize = 1 << 20
+
+// IdempotencyKey is the request header carrying the client request ID of
+// an invoke. A retry with the same key and body returns the result of the
+// first request, reusing the key for another request fails with 409.
+const IdempotencyKey = "Idempotency-Key"
+
+// New returns the handler serving Routes on top of t.
+func New(t Transport) http.Handler {
+	mux := &router{}
+	for _, r := range Routes {
+		mux.handle(r.Method, r.Path, handler(t, r))
+	}
+
+	doc, err := json.MarshalIndent(OpenAPI(), "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	mux.handle("GET", "/openapi.json", func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(doc)
+	})
+
+	return mux
+}
+
+func handler(t Transport, route Route) func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	return func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		args, err := BuildArgs(route, r, params)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		call, function := t.Query, route.Function
+		if route.Invoke {
+			call = t.Invoke
+			function = model.WithRequestID(function, r.Header.Get(IdempotencyKey))
+		}
+		out, err := call(r.Context(), function, args)
+
+		var ce *ChaincodeError
+		switch {
+		case errors.As(err, &ce) && ce.Err != nil:
+			writeError(w, int(ce.Err.Class), err)
+		case errors.As(err, &ce) && route.Invoke:
+			writeError(w, http.StatusBadRequest, err)
+		case errors.As(err, &ce):
+			// A chaincode without error codes reports every query
+			// failure as object not found
+			writeError(w, http.StatusNotFound, err)
+		case err != nil:
+			writeError(w, http.StatusBadGateway, err)
+		default:
+			status := http.StatusOK
+			if route.Invoke {
+				status = http.StatusCreated
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			w.Write(out)
+		}
+	}
+}
+
+// BuildArgs returns the argument list of the chaincode function of route
+// for the request, params holding the values of the path segments.
+func BuildArgs(route Route, r *http.Request, params map[string]string) ([]string, error) {
+	var body []byte
+	fields := map[string]json.RawMessage{}
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(r.Body, MaxBodySize))
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.TrimSpace(body)
+	}
+	if len(body) > 0 && hasBodyFields(route) {
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return nil, errcode.Errorf(errcode.InvalidArgument, "request body should be a JSON object: %s", err)
+		}
+	}
+
+	args := make([]string, len(route.Args))
+	for i, a := range route.Args {
+		var v string
+		switch a.In {
+		case "path":
+			v = params[a.Name]
+		case "query":
+			v = r.URL.Query().Get(a.Name)
+		case "const":
+			v = a.Value
+		case "body":
+			raw, ok := fields[a.Name]
+			if !ok || string(raw) == "null" {
+				break
+			}
+			// Strings are passed as is, anything else as its JSON text
+			if err := json.Unmarshal(raw, &v); err != nil {
+				v = string(raw)
+			}
+		case "":
+			v = string(body)
+		}
+
+		if v == "" && a.Default != nil {
+			v = a.Default()
+		}
+		if v == "" && a.Required && a.In == "" {
+			return nil, errcode.New(errcode.InvalidArgument, "request body is required").WithField(a.Name)
+		}
+		if v == "" && a.Required {
+			return nil, errcode.Errorf(errcode.InvalidArgument, "%s %q is required", a.In, a.Name).WithField(a.Name)
+		}
+		args[i] = v
+	}
+
+	// Drop trailing optional arguments that were not given
+	n := len(args)
+	for n > 0 && route.Args[n-1].Optional && args[n-1] == "" {
+		n--
+	}
+	return args[:n], nil
+}
+
+func hasBodyFields(route Route) bool {
+	for _, a := range route.Args {
+		if a.In == "body" {
+			return true
+		}
+	}
+	return false
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	body := Error{Error: err.Error()}
+	var e *errcode.Error
+	if errors.As(err, &e) {
+		body.Code, body.Field, body.Class = e.Code, e.Field, e.Class
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
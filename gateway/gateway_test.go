@@ -0,0 +1,196 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+)
+
+// step is one request to the gateway and the response expected.
+type step struct {
+	method, path, body string
+	header             map[string]string
+	wantStatus         int
+	wantCode           errcode.Code // of an error response
+	wantBody           string       // found in a successful response
+}
+
+func run(t *testing.T, h http.Handler, steps []step) {
+	t.Helper()
+	for i, s := range steps {
+		req := httptest.NewRequest(s.method, s.path, strings.NewReader(s.body))
+		for k, v := range s.header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != s.wantStatus {
+			t.Fatalf("step %d %s %s = %d %s, want %d", i, s.method, s.path, rec.Code, rec.Body, s.wantStatus)
+		}
+		if s.wantCode != "" {
+			var e Error
+			if err := json.Unmarshal(rec.Body.Bytes(), &e); err != nil || e.Code != s.wantCode {
+				t.Fatalf("step %d %s %s = %s, want code %s", i, s.method, s.path, rec.Body, s.wantCode)
+			}
+		}
+		if !strings.Contains(rec.Body.String(), s.wantBody) {
+			t.Fatalf("step %d %s %s = %s, want %s", i, s.method, s.path, rec.Body, s.wantBody)
+		}
+	}
+}
+
+func seeded() *Memory {
+	m := NewMemory()
+	m.AddUser(User{UserID: "100", UserType: "TR"})
+	m.AddUser(User{UserID: "200", UserType: "TR"})
+	m.AddUser(User{UserID: "300", UserType: "TR"})
+	return m
+}
+
+const postContract = `{"contractId":"1000","amount":"5000","duration":"30","businessRule":"Fixed price","type":"IT",
+	"requirementDescription":"Web site","description":"Build a web shop","terms":"Net 30","userId":"100"}`
+
+func TestContractLifecycle(t *testing.T) {
+	run(t, New(seeded()), []step{
+		{method: "POST", path: "/contracts", body: postContract, wantStatus: 201, wantBody: `"Status":"OPEN"`},
+		{method: "POST", path: "/contracts", body: postContract, wantStatus: 409, wantCode: errcode.AlreadyExists},
+		{method: "GET", path: "/contracts/1000", wantStatus: 200, wantBody: `"ContractId":"1000"`},
+		{method: "GET", path: "/contracts/2000", wantStatus: 404, wantCode: errcode.NotFound},
+		{method: "POST", path: "/contracts/1000/bids", body: `{"bidNo":"1","userId":"200","price":"4500"}`, wantStatus: 201, wantBody: `"BidPrice":"4500"`},
+		{method: "POST", path: "/contracts/1000/bids", body: `{"bidNo":"2","userId":"900","price":"4000"}`, wantStatus: 403, wantCode: errcode.NotRegistered},
+		{method: "GET", path: "/contracts/1000/bids", wantStatus: 200, wantBody: `"BidNo":"1"`},
+
+		// Negotiation: owner counters, bidder accepts, then the owner awards
+		{method: "POST", path: "/contracts/1000/bids/1/select", body: `{"userId":"100"}`, wantStatus: 409, wantCode: errcode.InvalidState},
+		{method: "POST", path: "/contracts/1000/bids/1/accept", body: `{"userId":"300"}`, wantStatus: 403, wantCode: errcode.NotAllowed},
+		{method: "POST", path: "/contracts/1000/bids/1/offers", body: `{"userId":"100","price":"4000","duration":"20"}`, wantStatus: 201, wantBody: `"Role":"OWNER"`},
+		{method: "POST", path: "/contracts/1000/bids/1/offers", body: `{"userId":"100","price":"3900"}`, wantStatus: 409, wantCode: errcode.InvalidState},
+		{method: "POST", path: "/contracts/1000/bids/1/accept", body: `{"userId":"200"}`, wantStatus: 201, wantBody: `"Action":"ACCEPT"`},
+		{method: "GET", path: "/contracts/1000/bids/1/offers?pageSize=1", wantStatus: 200, wantBody: `"nextToken":"0000"`},
+		{method: "GET", path: "/contracts/1000/bids/1/offers?pageSize=1&token=0000", wantStatus: 200, wantBody: `"Seq":"2"`},
+		{method: "POST", path: "/contracts/1000/bids/1/select", body: `{"userId":"200"}`, wantStatus: 403, wantCode: errcode.NotAllowed},
+		{method: "POST", path: "/contracts/1000/bids/1/select", body: `{"userId":"100"}`, wantStatus: 201, wantBody: `"BidPrice":"4000"`},
+		{method: "GET", path: "/contracts/1000", wantStatus: 200, wantBody: `"Duration":"20"`},
+
+		{method: "POST", path: "/contracts/1000/transactions", body: `{"transactionId":"1","transType":"PAYMENT","userId":"200","amount":"4000","bidNo":"2"}`,
+			wantStatus: 409, wantCode: errcode.InvalidState},
+		{method: "POST", path: "/contracts/1000/transactions", body: `{"transactionId":"1","transType":"PAYMENT","userId":"200","amount":"4000","bidNo":"1"}`,
+			wantStatus: 201, wantBody: `"TransactionAmount":"4000"`},
+		{method: "POST", path: "/contracts/1000/cancel", body: `{"userId":"100"}`, wantStatus: 409, wantCode: errcode.InvalidState},
+		{method: "POST", path: "/contracts/1000/close", body: `{"userId":"100"}`, wantStatus: 201, wantBody: `"Status":"CLOSED"`},
+		{method: "POST", path: "/contracts/1000/transactions", body: `{"transactionId":"2","transType":"PAYMENT","userId":"200","amount":"100","bidNo":"1"}`,
+			wantStatus: 409, wantCode: errcode.InvalidState},
+	})
+}
+
+func TestRejectedNegotiation(t *testing.T) {
+	run(t, New(seeded()), []step{
+		{method: "POST", path: "/contracts", body: postContract, wantStatus: 201},
+		{method: "POST", path: "/contracts/1000/bids", body: `{"bidNo":"1","userId":"200","price":"4500"}`, wantStatus: 201},
+		{method: "POST", path: "/contracts/1000/bids/1/reject", body: `{"userId":"100"}`, wantStatus: 201, wantBody: `"Action":"REJECT"`},
+		{method: "POST", path: "/contracts/1000/bids/1/accept", body: `{"userId":"200"}`, wantStatus: 409, wantCode: errcode.InvalidState},
+		{method: "POST", path: "/contracts/1000/bids/1/select", body: `{"userId":"100"}`, wantStatus: 409, wantCode: errcode.InvalidState},
+	})
+}
+
+func TestArguments(t *testing.T) {
+	run(t, New(seeded()), []step{
+		{method: "POST", path: "/contracts", body: `{"contractId":"1000"}`, wantStatus: 400, wantCode: errcode.InvalidArgument, wantBody: `"field":"amount"`},
+		{method: "POST", path: "/contracts", body: `[1]`, wantStatus: 400, wantCode: errcode.InvalidArgument},
+		{method: "POST", path: "/contracts/search", body: `[1]`, wantStatus: 400, wantCode: errcode.InvalidArgument, wantBody: `"field":"filter"`},
+		{method: "GET", path: "/contracts?pageSize=0", wantStatus: 400, wantCode: errcode.InvalidArgument},
+		{method: "GET", path: "/contracts?scope=OWNER", wantStatus: 400, wantCode: errcode.InvalidArgument},
+		{method: "POST", path: "/users", body: `{"userId":"400","name":"Jo","userType":"XX"}`, wantStatus: 400, wantCode: errcode.InvalidArgument},
+		{method: "GET", path: "/openapi.json", wantStatus: 200, wantBody: `"/contracts/{id}/bids/{bidNo}/offers"`},
+	})
+}
+
+func TestRouting(t *testing.T) {
+	h := New(seeded())
+	run(t, h, []step{
+		{method: "POST", path: "/contracts", body: postContract, wantStatus: 201},
+		{method: "GET", path: "/contracts/1000/close", wantStatus: 405},
+		{method: "DELETE", path: "/contracts", wantStatus: 405},
+		{method: "GET", path: "/contracts/", wantStatus: 404},
+		{method: "GET", path: "/contracts/1000/bids/", wantStatus: 404},
+		{method: "GET", path: "/orders", wantStatus: 404},
+		{method: "GET", path: "/contracts/%31000", wantStatus: 200, wantBody: `"ContractId":"1000"`},
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/contracts/1000/close", nil))
+	if allow := rec.Header().Get("Allow"); allow != "POST" {
+		t.Errorf("GET /contracts/1000/close Allow = %q, want POST", allow)
+	}
+}
+
+func TestSearchAndPages(t *testing.T) {
+	m := seeded()
+	steps := []step{}
+	for _, id := range []string{"1001", "1002", "1003"} {
+		steps = append(steps, step{method: "POST", path: "/contracts", wantStatus: 201,
+			body: strings.Replace(postContract, `"1000"`, `"`+id+`"`, 1)})
+	}
+	steps = append(steps,
+		step{method: "GET", path: "/contracts?pageSize=2", wantStatus: 200, wantBody: `"nextToken":"1002"`},
+		step{method: "GET", path: "/contracts?pageSize=2&token=1002", wantStatus: 200, wantBody: `"ContractId":"1003"`},
+		step{method: "POST", path: "/contracts/search", body: `{"Text":"web shop","MinAmount":"4000"}`, wantStatus: 200, wantBody: `"ContractId":"1001"`},
+		step{method: "POST", path: "/contracts/search", body: `{"Type":"LEGAL"}`, wantStatus: 200, wantBody: `"items":[]`},
+	)
+	run(t, New(m), steps)
+}
+
+func TestIdempotency(t *testing.T) {
+	key := map[string]string{IdempotencyKey: "7f3c9a"}
+	run(t, New(seeded()), []step{
+		{method: "POST", path: "/contracts", body: postContract, header: key, wantStatus: 201},
+		{method: "POST", path: "/contracts", body: postContract, header: key, wantStatus: 201, wantBody: `"ContractId":"1000"`},
+		{method: "POST", path: "/contracts", body: strings.Replace(postContract, `"1000"`, `"1001"`, 1), header: key,
+			wantStatus: 409, wantCode: errcode.RequestIDReused},
+	})
+}
+
+func TestBatch(t *testing.T) {
+	h := New(seeded())
+	run(t, h, []step{
+		{method: "POST", path: "/batch", wantStatus: 400, wantCode: errcode.InvalidArgument},
+		{method: "POST", path: "/batch", wantStatus: 409, wantCode: errcode.InvalidState, body: `[
+			{"function":"PostRequest","args":["1000","5000","30","","IT","","","","2016-10-18 10:00:00","100","CREATECONTR"]},
+			{"function":"CloseContract","args":["1000","CLOSECONTRACT","100"]}]`,
+			wantBody: `"field":"operations[1]"`},
+		// The failed batch left nothing behind
+		{method: "GET", path: "/contracts/1000", wantStatus: 404, wantCode: errcode.NotFound},
+		{method: "POST", path: "/batch", wantStatus: 201, body: `[
+			{"function":"PostRequest","args":["1000","5000","30","","IT","","","","2016-10-18 10:00:00","100","CREATECONTR"]},
+			{"function":"PostBid","args":["1000","BID","1","","200","4500"]}]`,
+			wantBody: `"function":"PostBid"`},
+		{method: "GET", path: "/contracts/1000/bids", wantStatus: 200, wantBody: `"BidNo":"1"`},
+	})
+}
+
+func TestSaveLoad(t *testing.T) {
+	m := seeded()
+	run(t, New(m), []step{
+		{method: "POST", path: "/contracts", body: postContract, wantStatus: 201},
+		{method: "POST", path: "/contracts/1000/bids", body: `{"bidNo":"1","userId":"200","price":"4500"}`, wantStatus: 201},
+		{method: "POST", path: "/contracts/1000/bids/1/offers", body: `{"userId":"100","price":"4000"}`, wantStatus: 201},
+	})
+
+	var b strings.Builder
+	if err := m.Save(&b); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewMemory()
+	if err := loaded.Load(strings.NewReader(b.String())); err != nil {
+		t.Fatal(err)
+	}
+	run(t, New(loaded), []step{
+		{method: "POST", path: "/contracts/1000/bids/1/accept", body: `{"userId":"200"}`, wantStatus: 201, wantBody: `"Seq":"2"`},
+		{method: "POST", path: "/contracts/1000/bids/1/select", body: `{"userId":"100"}`, wantStatus: 201, wantBody: `"BidPrice":"4000"`},
+	})
+}
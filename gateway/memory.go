@@ -0,0 +1,768 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/model"
+)
+
+// Memory is an in-memory Transport for testing clients of the gateway
+// without a peer. It implements the functions behind Routes with the same
+// arguments, checks them with the constructors of package model like the
+// chaincode does and applies the main rules of the chaincode: registered users only,
+// bids on OPEN contracts, owner only status changes, negotiations taken in
+// turns, selection of fully co-signed bids with an accepted offer only and
+// payments on the selected bid. Co-signing is not modelled: a team bid can
+// only be selected once fully co-signed, which only bids restored by Load or
+// ImportRow can be, and payments are not split.
+type Memory struct {
+	mu        sync.Mutex
+	users     map[string]User
+	contracts map[string]Contract
+	bids      map[string]map[string]Bid // ContractId -> BidNo -> Bid
+	offers    map[string][]Offer        // offerKey -> negotiation of the bid
+	trans     []Transaction
+	requests  map[string]memoryRequest // client request ID -> first result
+}
+
+// memoryRequest is an invoke made with a client request ID, see
+// model.RequestIDSeparator.
+type memoryRequest struct {
+	RequestID string
+	Digest    string
+	Result    json.RawMessage
+}
+
+// NewMemory returns an empty backend.
+func NewMemory() *Memory {
+	return &Memory{
+		users:     map[string]User{},
+		contracts: map[string]Contract{},
+		bids:      map[string]map[string]Bid{},
+		offers:    map[string][]Offer{},
+		requests:  map[string]memoryRequest{},
+	}
+}
+
+// AddUser registers a user without checking it, for seeding the backend.
+func (m *Memory) AddUser(u User) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u.RecType = "USER"
+	m.users[u.UserID] = u
+}
+
+type memoryFunc func(m *Memory, args []string) (interface{}, error)
+
+var memoryInvokes = map[string]memoryFunc{
+	"PostUser":        (*Memory).postUser,
+	"PostRequest":     (*Memory).postRequest,
+	"CancelContract":  (*Memory).cancelContract,
+	"CloseContract":   (*Memory).closeContract,
+	"PostBid":         (*Memory).postBid,
+	"CounterOffer":    (*Memory).counterOffer,
+	"AcceptOffer":     (*Memory).acceptOffer,
+	"RejectOffer":     (*Memory).rejectOffer,
+	"SelectBidder":    (*Memory).selectBidder,
+	"PostTransaction": (*Memory).postTransaction,
+}
+
+var memoryQueries = map[string]memoryFunc{
+	"GetContract":            (*Memory).getContract,
+	"ListContracts":          (*Memory).listContracts,
+	"ViewContracts":          (*Memory).viewContracts,
+	"GetListOfBids":          (*Memory).getListOfBids,
+	"GetNegotiation":         (*Memory).getNegotiation,
+	"GetUser":                (*Memory).getUser,
+	"GetListOfOpenContracts": (*Memory).getListOfOpenContracts,
+}
+
+func init() {
+	// Registered here as batch runs the other memoryInvokes
+	memoryInvokes["Batch"] = (*Memory).batch
+}
+
+// Invoke runs an invoke function. Like the chaincode, it returns the first
+// result again for a retry with the same client request ID.
+func (m *Memory) Invoke(ctx context.Context, function string, args []string) ([]byte, error) {
+	id, function := model.SplitRequestID(function)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id == "" {
+		return m.call(memoryInvokes, function, args)
+	}
+
+	digest := model.RequestDigest(function, args)
+	if req, ok := m.requests[id]; ok {
+		if req.Digest != digest {
+			return nil, chaincodeError(function, errcode.New(errcode.RequestIDReused,
+				"request ID already used for another request: "+id).WithField("requestId"))
+		}
+		return req.Result, nil
+	}
+	out, err := m.call(memoryInvokes, function, args)
+	if err == nil {
+		m.requests[id] = memoryRequest{RequestID: id, Digest: digest, Result: out}
+	}
+	return out, err
+}
+
+// Query runs a query function.
+func (m *Memory) Query(ctx context.Context, function string, args []string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.call(memoryQueries, function, args)
+}
+
+// call runs function with m.mu held.
+func (m *Memory) call(funcs map[string]memoryFunc, function string, args []string) ([]byte, error) {
+	f, ok := funcs[function]
+	if !ok {
+		return nil, chaincodeError(function, errcode.New(errcode.UnknownFunction, "unknown function").WithField("function"))
+	}
+
+	out, err := f(m, args)
+	if err != nil {
+		return nil, chaincodeError(function, err)
+	}
+	return json.Marshal(out)
+}
+
+// batch runs the operations of a Batch in order. If one fails, the state
+// before the batch is restored, as the chaincode fails the transaction.
+func (m *Memory) batch(args []string) (interface{}, error) {
+	ops, err := model.CreateBatch(args)
+	if err != nil {
+		return nil, err
+	}
+	for i, op := range ops {
+		if _, ok := memoryInvokes[op.Function]; !ok {
+			return nil, errcode.Errorf(errcode.UnknownFunction, "unknown function in operation %d: %s", i, op.Function).
+				WithField("operations[" + strconv.Itoa(i) + "].function")
+		}
+	}
+
+	saved := m.snapshot()
+	results := make([]BatchResult, len(ops))
+	for i, op := range ops {
+		out, err := memoryInvokes[op.Function](m, op.Args)
+		if err == nil {
+			results[i].Result, err = json.Marshal(out)
+		}
+		if err != nil {
+			m.restore(saved)
+			return nil, model.BatchError(i, op.Function, err)
+		}
+		results[i].Function = op.Function
+	}
+	return results, nil
+}
+
+// memorySnapshot is the state of a Memory before a batch.
+type memorySnapshot struct {
+	users     map[string]User
+	contracts map[string]Contract
+	bids      map[string]map[string]Bid
+	offers    map[string][]Offer
+	ntrans    int
+}
+
+func (m *Memory) snapshot() memorySnapshot {
+	s := memorySnapshot{
+		users:     maps.Clone(m.users),
+		contracts: maps.Clone(m.contracts),
+		bids:      make(map[string]map[string]Bid, len(m.bids)),
+		offers:    maps.Clone(m.offers),
+		ntrans:    len(m.trans),
+	}
+	for id, bids := range m.bids {
+		s.bids[id] = maps.Clone(bids)
+	}
+	return s
+}
+
+func (m *Memory) restore(s memorySnapshot) {
+	m.users, m.contracts, m.bids, m.offers = s.users, s.contracts, s.bids, s.offers
+	m.trans = m.trans[:s.ntrans]
+}
+
+func expectArgs(args []string, n ...int) error {
+	for _, k := range n {
+		if len(args) == k {
+			return nil
+		}
+	}
+	return errcode.Errorf(errcode.ArgCount, "incorrect number of arguments, expecting %d", n[0])
+}
+
+func (m *Memory) member(id string) error {
+	if _, ok := m.users[id]; !ok {
+		return errcode.Errorf(errcode.NotRegistered, "user not registered: %s", id).WithField("UserID")
+	}
+	return nil
+}
+
+func (m *Memory) contract(id string) (Contract, error) {
+	c, ok := m.contracts[id]
+	if !ok {
+		return c, errcode.Errorf(errcode.NotFound, "cannot find contract: %s", id).WithField("ContractId")
+	}
+	return c, nil
+}
+
+func (m *Memory) postRequest(args []string) (interface{}, error) {
+	obj, err := model.CreateContract(args)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := m.contracts[obj.ContractId]; ok {
+		return nil, errcode.Errorf(errcode.AlreadyExists, "contract already exists: %s", obj.ContractId).WithField("ContractId")
+	}
+	if err := m.member(obj.UserID); err != nil {
+		return nil, err
+	}
+
+	c := Contract{ContractObject: obj}
+	m.contracts[c.ContractId] = c
+	return c, nil
+}
+
+func (m *Memory) postUser(args []string) (interface{}, error) {
+	u, err := model.CreateUser(args)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := m.users[u.UserID]; ok {
+		return nil, errcode.Errorf(errcode.AlreadyExists, "user already exists: %s", u.UserID).WithField("UserID")
+	}
+	m.users[u.UserID] = u
+	return u, nil
+}
+
+// setStatus moves a contract owned by args[2] from status from to status to.
+func (m *Memory) setStatus(args []string, from, to string) (interface{}, error) {
+	if err := expectArgs(args, 3); err != nil {
+		return nil, err
+	}
+	c, err := m.contract(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if c.UserID != args[2] {
+		return nil, errcode.Errorf(errcode.NotAllowed, "only the owner of the contract can change it: %s", args[2]).WithField("UserID")
+	}
+	if c.Status != from {
+		return nil, errcode.Errorf(errcode.InvalidState, "contract is not %s: %s", from, args[0])
+	}
+	c.Status = to
+	m.contracts[c.ContractId] = c
+	return c, nil
+}
+
+func (m *Memory) cancelContract(args []string) (interface{}, error) {
+	return m.setStatus(args, "OPEN", "CANCELLED")
+}
+
+func (m *Memory) closeContract(args []string) (interface{}, error) {
+	return m.setStatus(args, "IN_PROGRESS", "CLOSED")
+}
+
+func (m *Memory) postBid(args []string) (interface{}, error) {
+	bid, err := model.CreateBidObject(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.member(bid.UserID); err != nil {
+		return nil, err
+	}
+	for _, mb := range bid.Members {
+		if err := m.member(mb.UserID); err != nil {
+			return nil, err
+		}
+	}
+	c, err := m.contract(bid.ContractId)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status != "OPEN" {
+		return nil, errcode.Errorf(errcode.InvalidState, "cannot accept bid as contract is not OPEN: %s", bid.ContractId)
+	}
+	if _, ok := m.bids[bid.ContractId][bid.BidNo]; ok {
+		return nil, errcode.Errorf(errcode.AlreadyExists, "bid already exists: %s", bid.BidNo).WithField("BidNo")
+	}
+
+	if m.bids[bid.ContractId] == nil {
+		m.bids[bid.ContractId] = map[string]Bid{}
+	}
+	m.bids[bid.ContractId][bid.BidNo] = bid
+	return bid, nil
+}
+
+func (m *Memory) selectBidder(args []string) (interface{}, error) {
+	if err := expectArgs(args, 4); err != nil {
+		return nil, err
+	}
+	c, err := m.contract(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if c.UserID != args[3] {
+		return nil, errcode.Errorf(errcode.NotAllowed, "only the owner of the contract can select a bidder: %s", args[3]).WithField("UserID")
+	}
+	if c.Status != "OPEN" {
+		return nil, errcode.Errorf(errcode.InvalidState, "cannot select a bidder as contract is not OPEN: %s", args[0])
+	}
+	bid, ok := m.bids[args[0]][args[2]]
+	if !ok {
+		return nil, errcode.Errorf(errcode.NotFound, "cannot find bid: %s", args[2]).WithField("BidNo")
+	}
+	for _, mb := range bid.Members {
+		if !mb.Signed {
+			return nil, errcode.Errorf(errcode.InvalidState, "team bid has not been co-signed by all members: %s", args[2])
+		}
+	}
+	thread := m.offers[offerKey(args[0], args[2])]
+	if len(thread) == 0 || thread[len(thread)-1].Action != "ACCEPT" {
+		return nil, errcode.Errorf(errcode.InvalidState, "bid has no accepted offer: %s", args[2])
+	}
+	offer := thread[len(thread)-1]
+
+	c.WorkerID, c.BidNo, c.Status = bid.UserID, bid.BidNo, "IN_PROGRESS"
+	c.BidPrice, c.Duration, c.Terms = offer.Price, offer.Duration, offer.Terms
+	m.contracts[c.ContractId] = c
+	return c, nil
+}
+
+func offerKey(contractID, bidNo string) string {
+	return contractID + "/" + bidNo
+}
+
+func (m *Memory) counterOffer(args []string) (interface{}, error) {
+	if err := expectArgs(args, 7); err != nil {
+		return nil, err
+	}
+	if _, err := strconv.Atoi(args[4]); err != nil {
+		return nil, errcode.New(errcode.InvalidArgument, "price should be an integer").WithField("Price")
+	}
+	offer, err := m.nextOffer(args[0], args[2], args[3], "COUNTER")
+	if err != nil {
+		return nil, err
+	}
+	offer.Price = args[4]
+	if args[5] != "" {
+		offer.Duration = args[5]
+	}
+	if args[6] != "" {
+		offer.Terms = args[6]
+	}
+	return m.postOffer(offer), nil
+}
+
+func (m *Memory) acceptOffer(args []string) (interface{}, error) {
+	return m.answerOffer(args, "ACCEPT")
+}
+
+func (m *Memory) rejectOffer(args []string) (interface{}, error) {
+	return m.answerOffer(args, "REJECT")
+}
+
+func (m *Memory) answerOffer(args []string, action string) (interface{}, error) {
+	if err := expectArgs(args, 4); err != nil {
+		return nil, err
+	}
+	offer, err := m.nextOffer(args[0], args[2], args[3], action)
+	if err != nil {
+		return nil, err
+	}
+	return m.postOffer(offer), nil
+}
+
+// nextOffer returns the next step of the negotiation on a bid for userID,
+// a copy of the offer it answers. The bid is the opening offer.
+func (m *Memory) nextOffer(contractID, bidNo, userID, action string) (Offer, error) {
+	c, err := m.contract(contractID)
+	if err != nil {
+		return Offer{}, err
+	}
+	if c.Status != "OPEN" {
+		return Offer{}, errcode.Errorf(errcode.InvalidState, "cannot negotiate as contract is not OPEN: %s", contractID)
+	}
+	bid, ok := m.bids[contractID][bidNo]
+	if !ok {
+		return Offer{}, errcode.Errorf(errcode.NotFound, "cannot find bid: %s", bidNo).WithField("BidNo")
+	}
+
+	var role string
+	switch userID {
+	case c.UserID:
+		role = "OWNER"
+	case bid.UserID:
+		role = "BIDDER"
+	default:
+		return Offer{}, errcode.Errorf(errcode.NotAllowed, "user is not the owner or the bidder: %s", userID)
+	}
+
+	last := Offer{ContractId: contractID, RecType: "OFFER", BidNo: bidNo, Seq: "0", UserID: bid.UserID, Role: "BIDDER",
+		Action: "COUNTER", Price: bid.BidPrice, Duration: c.Duration, Terms: c.Terms, OfferTime: bid.BidTime}
+	if thread := m.offers[offerKey(contractID, bidNo)]; len(thread) > 0 {
+		last = thread[len(thread)-1]
+	}
+	if last.Action != "COUNTER" {
+		return Offer{}, errcode.Errorf(errcode.InvalidState, "negotiation has already ended with %s", last.Action)
+	}
+	if last.Role == role {
+		return Offer{}, errcode.New(errcode.InvalidState, "waiting for the other party to answer the last offer")
+	}
+
+	seq, _ := strconv.Atoi(last.Seq)
+	next := last
+	next.Seq = strconv.Itoa(seq + 1)
+	next.UserID, next.Role, next.Action, next.OfferTime = userID, role, action, now()
+	return next, nil
+}
+
+func (m *Memory) postOffer(offer Offer) Offer {
+	key := offerKey(offer.ContractId, offer.BidNo)
+	// Copy the thread, a snapshot may share it
+	m.offers[key] = append(slices.Clip(m.offers[key]), offer)
+	return offer
+}
+
+func (m *Memory) postTransaction(args []string) (interface{}, error) {
+	at, err := model.CreateTransactionRequest(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.member(at.UserId); err != nil {
+		return nil, err
+	}
+	c, err := m.contract(at.ConractId)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status != "IN_PROGRESS" {
+		return nil, errcode.Errorf(errcode.InvalidState, "payments are only accepted on an IN_PROGRESS contract: %s", at.ConractId)
+	}
+	if c.BidNo == "" || c.BidNo != at.BidNo {
+		return nil, errcode.Errorf(errcode.InvalidState, "bid is not the selected bid of the contract: %s", at.BidNo).WithField("BidNo")
+	}
+
+	m.trans = append(m.trans, at)
+	return []Transaction{at}, nil
+}
+
+func (m *Memory) getContract(args []string) (interface{}, error) {
+	if err := expectArgs(args, 1); err != nil {
+		return nil, err
+	}
+	return m.contract(args[0])
+}
+
+func (m *Memory) getUser(args []string) (interface{}, error) {
+	if err := expectArgs(args, 1); err != nil {
+		return nil, err
+	}
+	u, ok := m.users[args[0]]
+	if !ok {
+		return nil, errcode.Errorf(errcode.NotFound, "cannot find user: %s", args[0]).WithField("UserID")
+	}
+	return u, nil
+}
+
+func (m *Memory) listContracts(args []string) (interface{}, error) {
+	if len(args) < 2 {
+		return nil, errcode.New(errcode.ArgCount, "incorrect number of arguments, expecting 2")
+	}
+	var match func(Contract) bool
+	switch args[0] {
+	case "ALL":
+		match = func(Contract) bool { return true }
+	case "TYPE":
+		match = func(c Contract) bool { return c.Type == args[1] }
+	case "PERIOD":
+		match = func(c Contract) bool { return strings.HasPrefix(c.CreationDate, args[1]) }
+	default:
+		return nil, errcode.Errorf(errcode.InvalidArgument, "scope should be ALL, TYPE or PERIOD: %s", args[0]).WithField("scope")
+	}
+	return m.contractPage(match, args[2:])
+}
+
+func (m *Memory) getListOfOpenContracts(args []string) (interface{}, error) {
+	return m.contractPage(func(c Contract) bool { return c.Status == "OPEN" }, args)
+}
+
+func (m *Memory) viewContracts(args []string) (interface{}, error) {
+	if len(args) < 1 {
+		return nil, errcode.New(errcode.ArgCount, "incorrect number of arguments, expecting 1")
+	}
+	var f ContractFilter
+	if args[0] != "" {
+		if err := json.Unmarshal([]byte(args[0]), &f); err != nil {
+			return nil, errcode.Errorf(errcode.InvalidArgument, "filter should be a JSON object: %s", args[0]).WithField("filter")
+		}
+	}
+	minAmount, _ := strconv.Atoi(f.MinAmount)
+	maxAmount, _ := strconv.Atoi(f.MaxAmount)
+	text := strings.ToLower(f.Text)
+
+	return m.contractPage(func(c Contract) bool {
+		amount, _ := strconv.Atoi(c.Amount)
+		desc := strings.ToLower(c.Description + " " + c.RequirementDescription)
+		return (f.Type == "" || c.Type == f.Type) &&
+			(f.Status == "" || c.Status == f.Status) &&
+			(f.UserID == "" || c.UserID == f.UserID) &&
+			(f.MinAmount == "" || amount >= minAmount) &&
+			(f.MaxAmount == "" || amount <= maxAmount) &&
+			(f.FromDate == "" || c.CreationDate >= f.FromDate) &&
+			(f.ToDate == "" || c.CreationDate[:min(len(c.CreationDate), 10)] <= f.ToDate) &&
+			(text == "" || strings.Contains(desc, text))
+	}, args[1:])
+}
+
+func (m *Memory) contractPage(match func(Contract) bool, pageArgs []string) (interface{}, error) {
+	var keys []string
+	for id, c := range m.contracts {
+		if match(c) {
+			keys = append(keys, id)
+		}
+	}
+	return memoryPage(keys, pageArgs, func(id string) interface{} { return m.contracts[id] })
+}
+
+func (m *Memory) getNegotiation(args []string) (interface{}, error) {
+	if len(args) < 2 {
+		return nil, errcode.New(errcode.ArgCount, "incorrect number of arguments, expecting 2")
+	}
+	thread := m.offers[offerKey(args[0], args[1])]
+	keys := make([]string, len(thread))
+	for i := range thread {
+		// Zero padded like the Seq keys of the chaincode
+		keys[i] = fmt.Sprintf("%04d", i)
+	}
+	return memoryPage(keys, args[2:], func(k string) interface{} {
+		i, _ := strconv.Atoi(k)
+		return thread[i]
+	})
+}
+
+func (m *Memory) getListOfBids(args []string) (interface{}, error) {
+	if len(args) < 1 {
+		return nil, errcode.New(errcode.ArgCount, "incorrect number of arguments, expecting 1")
+	}
+	var keys []string
+	for no := range m.bids[args[0]] {
+		keys = append(keys, no)
+	}
+	return memoryPage(keys, args[1:], func(no string) interface{} { return m.bids[args[0]][no] })
+}
+
+// memoryPage returns the page of keys, in key order, after the token.
+// The token is the last key of the previous page.
+func memoryPage(keys []string, pageArgs []string, item func(string) interface{}) (interface{}, error) {
+	pageSize, token := 50, ""
+	if len(pageArgs) > 0 && pageArgs[0] != "" {
+		n, err := strconv.Atoi(pageArgs[0])
+		if err != nil || n < 1 || n > 500 {
+			return nil, errcode.Errorf(errcode.InvalidArgument, "page size should be an integer between 1 and 500: %s", pageArgs[0]).WithField("pageSize")
+		}
+		pageSize = n
+	}
+	if len(pageArgs) > 1 {
+		token = pageArgs[1]
+	}
+
+	sort.Strings(keys)
+	start := sort.SearchStrings(keys, token)
+	if start < len(keys) && token != "" && keys[start] == token {
+		start++
+	}
+	end := start + pageSize
+	next := ""
+	if end < len(keys) {
+		next = keys[end-1]
+	} else {
+		end = len(keys)
+	}
+
+	items := make([]interface{}, 0, end-start)
+	for _, k := range keys[start:end] {
+		items = append(items, item(k))
+	}
+	return Page{Items: items, NextToken: next}, nil
+}
+
+// memoryState is the JSON encoding of a Memory, see Save and Load.
+type memoryState struct {
+	Users        []User
+	Contracts    []Contract
+	Bids         []Bid
+	Offers       []Offer
+	Transactions []Transaction
+	Requests     []memoryRequest
+}
+
+// Save writes the state of the backend as JSON.
+func (m *Memory) Save(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var st memoryState
+	for _, u := range m.users {
+		st.Users = append(st.Users, u)
+	}
+	for _, c := range m.contracts {
+		st.Contracts = append(st.Contracts, c)
+	}
+	for _, bids := range m.bids {
+		for _, b := range bids {
+			st.Bids = append(st.Bids, b)
+		}
+	}
+	for _, thread := range m.offers {
+		st.Offers = append(st.Offers, thread...)
+	}
+	st.Transactions = m.trans
+	for _, req := range m.requests {
+		st.Requests = append(st.Requests, req)
+	}
+
+	sort.Slice(st.Users, func(i, j int) bool { return st.Users[i].UserID < st.Users[j].UserID })
+	sort.Slice(st.Contracts, func(i, j int) bool { return st.Contracts[i].ContractId < st.Contracts[j].ContractId })
+	sort.Slice(st.Bids, func(i, j int) bool {
+		if st.Bids[i].ContractId != st.Bids[j].ContractId {
+			return st.Bids[i].ContractId < st.Bids[j].ContractId
+		}
+		return st.Bids[i].BidNo < st.Bids[j].BidNo
+	})
+	sort.Slice(st.Offers, func(i, j int) bool {
+		a, b := st.Offers[i], st.Offers[j]
+		if a.ContractId != b.ContractId || a.BidNo != b.BidNo {
+			return offerKey(a.ContractId, a.BidNo) < offerKey(b.ContractId, b.BidNo)
+		}
+		sa, _ := strconv.Atoi(a.Seq)
+		sb, _ := strconv.Atoi(b.Seq)
+		return sa < sb
+	})
+	sort.Slice(st.Requests, func(i, j int) bool { return st.Requests[i].RequestID < st.Requests[j].RequestID })
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(st)
+}
+
+// Load replaces the state of the backend with one written by Save.
+func (m *Memory) Load(r io.Reader) error {
+	var st memoryState
+	if err := json.NewDecoder(r).Decode(&st); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.users = map[string]User{}
+	m.contracts = map[string]Contract{}
+	m.bids = map[string]map[string]Bid{}
+	m.offers = map[string][]Offer{}
+	m.trans = st.Transactions
+	m.requests = map[string]memoryRequest{}
+	for _, req := range st.Requests {
+		// Save indents the results, return them as first returned
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, req.Result); err == nil {
+			req.Result = buf.Bytes()
+		}
+		m.requests[req.RequestID] = req
+	}
+	for _, u := range st.Users {
+		m.users[u.UserID] = u
+	}
+	for _, c := range st.Contracts {
+		m.contracts[c.ContractId] = c
+	}
+	for _, b := range st.Bids {
+		if m.bids[b.ContractId] == nil {
+			m.bids[b.ContractId] = map[string]Bid{}
+		}
+		m.bids[b.ContractId][b.BidNo] = b
+	}
+	for _, o := range st.Offers {
+		key := offerKey(o.ContractId, o.BidNo)
+		m.offers[key] = append(m.offers[key], o)
+	}
+	return nil
+}
+
+// ImportRow adds a row of the chaincode table named table, as exported by
+// the ExportTables query, to the backend. It reports false for the tables
+// the backend does not model, such as indexes and deliverables.
+func (m *Memory) ImportRow(table string, details []byte) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch table {
+	case "UserTable":
+		var u User
+		if err := json.Unmarshal(details, &u); err != nil {
+			return false, err
+		}
+		m.users[u.UserID] = u
+	case "ContractTable":
+		var c Contract
+		if err := json.Unmarshal(details, &c.ContractObject); err != nil {
+			return false, err
+		}
+		m.contracts[c.ContractId] = c
+	case "BidTable":
+		var b Bid
+		if err := json.Unmarshal(details, &b); err != nil {
+			return false, err
+		}
+		if m.bids[b.ContractId] == nil {
+			m.bids[b.ContractId] = map[string]Bid{}
+		}
+		m.bids[b.ContractId][b.BidNo] = b
+	case "NegotiationTable":
+		// Rows are exported in Seq order
+		var o Offer
+		if err := json.Unmarshal(details, &o); err != nil {
+			return false, err
+		}
+		key := offerKey(o.ContractId, o.BidNo)
+		m.offers[key] = append(m.offers[key], o)
+	case "TransTable":
+		var t Transaction
+		if err := json.Unmarshal(details, &t); err != nil {
+			return false, err
+		}
+		m.trans = append(m.trans, t)
+	case "RequestTable":
+		// A ClientRequest of the chaincode
+		var req struct {
+			RequestId string
+			Digest    string
+			Result    []byte
+		}
+		if err := json.Unmarshal(details, &req); err != nil {
+			return false, err
+		}
+		if !json.Valid(req.Result) {
+			return false, nil
+		}
+		m.requests[req.RequestId] = memoryRequest{RequestID: req.RequestId, Digest: req.Digest, Result: req.Result}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
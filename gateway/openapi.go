@@ -0,0 +1,200 @@
+package gateway
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Title and Version of the OpenAPI document.
+const (
+	Title   = "Contract marketplace"
+	Version = "1.0"
+)
+
+// OpenAPI generates the OpenAPI 3 document of Routes. Schemas are derived
+// from the sample values of the routes, so the document cannot drift from
+// what the gateway actually does.
+func OpenAPI() map[string]interface{} {
+	g := &generator{schemas: map[string]interface{}{}}
+	g.schema(reflect.TypeOf(Error{}))
+
+	paths := map[string]map[string]interface{}{}
+	for _, r := range Routes {
+		if paths[r.Path] == nil {
+			paths[r.Path] = map[string]interface{}{}
+		}
+		paths[r.Path][strings.ToLower(r.Method)] = g.operation(r)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]interface{}{"title": Title, "version": Version},
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": g.schemas,
+		},
+	}
+}
+
+type generator struct {
+	schemas map[string]interface{}
+}
+
+func (g *generator) operation(r Route) map[string]interface{} {
+	var params []interface{}
+	props := map[string]interface{}{}
+	var required []string
+	var whole interface{}
+	var wholeRequired bool
+
+	for _, a := range r.Args {
+		schema := map[string]interface{}{"type": "string"}
+		if a.Schema != nil {
+			schema = g.schema(reflect.TypeOf(a.Schema))
+		}
+		if a.Doc != "" && a.Schema == nil {
+			schema["description"] = a.Doc
+		}
+
+		switch a.In {
+		case "path", "query":
+			params = append(params, map[string]interface{}{
+				"name": a.Name, "in": a.In, "required": a.In == "path",
+				"description": a.Doc, "schema": schema,
+			})
+		case "body":
+			props[a.Name] = schema
+			if a.Required {
+				required = append(required, a.Name)
+			}
+		case "":
+			whole, wholeRequired = schema, a.Required
+		}
+	}
+
+	response := map[string]interface{}{}
+	if p, ok := r.Response.(page); ok {
+		response = g.pageSchema(p.item)
+	} else if r.Response != nil {
+		response = g.schema(reflect.TypeOf(r.Response))
+	}
+
+	status := "200"
+	if r.Invoke {
+		status = "201"
+	}
+	errorResponse := func(desc string) interface{} {
+		return map[string]interface{}{"description": desc, "content": jsonContent(ref("Error"))}
+	}
+
+	op := map[string]interface{}{
+		"operationId": r.Function,
+		"summary":     r.Summary,
+		"responses": map[string]interface{}{
+			status: map[string]interface{}{
+				"description": "Result of " + r.Function + ", {\"txid\"} when invoked on a peer",
+				"content":     jsonContent(response),
+			},
+			"400": errorResponse("Invalid request or rejected by the chaincode"),
+			"403": errorResponse("User not registered or not allowed"),
+			"404": errorResponse("Not found"),
+			"409": errorResponse("Already exists or not in a state allowing this"),
+			"500": errorResponse("Chaincode failure"),
+			"502": errorResponse("Chaincode unreachable"),
+		},
+	}
+	if r.Invoke {
+		params = append(params, map[string]interface{}{
+			"name": IdempotencyKey, "in": "header", "required": false,
+			"description": "Client request ID making retries safe",
+			"schema":      map[string]interface{}{"type": "string"},
+		})
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	switch {
+	case whole != nil:
+		op["requestBody"] = map[string]interface{}{"required": wholeRequired, "content": jsonContent(whole)}
+	case len(props) > 0:
+		body := map[string]interface{}{"type": "object", "properties": props}
+		if len(required) > 0 {
+			body["required"] = required
+		}
+		op["requestBody"] = map[string]interface{}{"required": true, "content": jsonContent(body)}
+	}
+	return op
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// schema returns the JSON schema of t, named structs become components.
+func (g *generator) schema(t reflect.Type) map[string]interface{} {
+	switch {
+	case t == rawMessageType:
+		return map[string]interface{}{}
+	case t.Kind() == reflect.Ptr:
+		return g.schema(t.Elem())
+	case t.Kind() == reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case t.Kind() != reflect.Struct:
+		return map[string]interface{}{}
+	}
+
+	if _, ok := g.schemas[t.Name()]; !ok {
+		props := map[string]interface{}{}
+		g.schemas[t.Name()] = map[string]interface{}{"type": "object", "properties": props}
+		g.properties(t, props)
+	}
+	return ref(t.Name())
+}
+
+// properties adds the fields of struct t to props, the fields of embedded
+// structs included as encoding/json does.
+func (g *generator) properties(t reflect.Type, props map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && tag == "" {
+			g.properties(f.Type, props)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag != "" {
+			name = tag
+		}
+		props[name] = g.schema(f.Type)
+	}
+}
+
+// pageSchema describes a Page of items shaped like sample.
+func (g *generator) pageSchema(sample interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"items":     map[string]interface{}{"type": "array", "items": g.schema(reflect.TypeOf(sample))},
+			"nextToken": map[string]interface{}{"type": "string", "description": "Empty on the last page"},
+		},
+	}
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func jsonContent(schema interface{}) map[string]interface{} {
+	return map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+}
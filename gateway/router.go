@@ -0,0 +1,96 @@
+package gateway
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// router dispatches requests on their method and path. Paths are matched
+// segment by segment, a {name} segment matches any non-empty segment and
+// its value is passed to the handler. It does not use the "METHOD /path"
+// patterns of http.ServeMux: without a go.mod the repository builds in
+// GOPATH mode, where ServeMux keeps its pre Go 1.22 behaviour.
+type router struct {
+	entries []entry
+}
+
+type entry struct {
+	method   string
+	segments []string
+	handle   func(w http.ResponseWriter, r *http.Request, params map[string]string)
+}
+
+// handle registers h for the requests matching method and pattern.
+func (rt *router) handle(method, pattern string, h func(w http.ResponseWriter, r *http.Request, params map[string]string)) {
+	rt.entries = append(rt.entries, entry{method, strings.Split(pattern, "/"), h})
+
+	// Literal segments take precedence over wildcards, /contracts/search
+	// is not the contract "search"
+	sort.SliceStable(rt.entries, func(i, j int) bool {
+		return wildcards(rt.entries[i].segments) < wildcards(rt.entries[j].segments)
+	})
+}
+
+func (rt *router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(r.URL.EscapedPath(), "/")
+
+	var allow []string
+	for _, e := range rt.entries {
+		params, ok := match(e.segments, segments)
+		if !ok {
+			continue
+		}
+		if e.method == r.Method || (e.method == "GET" && r.Method == "HEAD") {
+			e.handle(w, r, params)
+			return
+		}
+		allow = append(allow, e.method)
+	}
+
+	if len(allow) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Allow", strings.Join(allow, ", "))
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+// match returns the values of the {name} segments of pattern if the
+// segments of a request path match it.
+func match(pattern, segments []string) (map[string]string, bool) {
+	if len(pattern) != len(segments) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	for i, p := range pattern {
+		if !isWildcard(p) {
+			if p != segments[i] {
+				return nil, false
+			}
+			continue
+		}
+		v, err := url.PathUnescape(segments[i])
+		if err != nil || v == "" {
+			return nil, false
+		}
+		params[p[1:len(p)-1]] = v
+	}
+	return params, true
+}
+
+func isWildcard(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func wildcards(segments []string) int {
+	n := 0
+	for _, s := range segments {
+		if isWildcard(s) {
+			n++
+		}
+	}
+	return n
+}
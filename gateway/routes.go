@@ -0,0 +1,211 @@
+package gateway
+
+import "time"
+
+// Arg is one argument of a chaincode function and where the gateway takes
+// it from.
+type Arg struct {
+	In       string // path, query, body, const or the whole request body for ""
+	Name     string // parameter or body field name
+	Value    string // value of a const argument
+	Doc      string
+	Required bool          // the request is rejected without a value
+	Optional bool          // dropped when empty and every following argument is too
+	Default  func() string // value used when the request has none
+	Schema   interface{}   // sample value describing a body argument, a string if nil
+}
+
+// Route maps an HTTP operation onto a chaincode function.
+type Route struct {
+	Method   string
+	Path     string
+	Function string
+	Invoke   bool // Invoke or Query
+	Summary  string
+	Args     []Arg
+	Response interface{} // sample value describing the response
+}
+
+func path(name string) Arg {
+	return Arg{In: "path", Name: name, Required: true}
+}
+
+func lit(value string) Arg {
+	return Arg{In: "const", Value: value}
+}
+
+func field(name, doc string) Arg {
+	return Arg{In: "body", Name: name, Doc: doc, Required: true}
+}
+
+func pageArgs() []Arg {
+	return []Arg{
+		{In: "query", Name: "pageSize", Doc: "Items per page, 50 by default and 500 at most", Optional: true},
+		{In: "query", Name: "token", Doc: "nextToken of the previous page", Optional: true},
+	}
+}
+
+func now() string   { return time.Now().Format("2006-01-02 15:04:05") }
+func today() string { return time.Now().Format("2006-01-02") }
+
+// Routes is the API of the gateway. The OpenAPI document is generated from
+// the same table, see OpenAPI.
+var Routes = []Route{
+	{
+		Method: "GET", Path: "/contracts", Function: "ListContracts",
+		Summary: "List contracts of every type, of one type or created in a period",
+		Args: append([]Arg{
+			{In: "query", Name: "scope", Doc: "ALL, TYPE or PERIOD", Default: func() string { return "ALL" }},
+			{In: "query", Name: "value", Doc: "Type for TYPE, 2016 or 2016-10 for PERIOD"},
+		}, pageArgs()...),
+		Response: page{Contract{}},
+	},
+	{
+		Method: "POST", Path: "/contracts", Function: "PostRequest", Invoke: true,
+		Summary: "Post a new contract, OPEN for bids",
+		Args: []Arg{
+			field("contractId", "Integer ID of the contract"),
+			field("amount", "Budget of the contract"),
+			field("duration", "Duration in days"),
+			field("businessRule", ""),
+			field("type", ""),
+			field("requirementDescription", ""),
+			field("description", ""),
+			field("terms", ""),
+			{In: "body", Name: "creationDate", Doc: "2006-01-02 15:04:05, now by default", Default: now},
+			field("userId", "Owner of the contract"),
+			lit("CREATECONTR"),
+		},
+		Response: Contract{},
+	},
+	{
+		Method: "POST", Path: "/contracts/search", Function: "ViewContracts",
+		Summary:  "Search contracts with a filter",
+		Args:     append([]Arg{{In: "", Doc: "Filter, every field is optional", Schema: ContractFilter{}}}, pageArgs()...),
+		Response: page{Contract{}},
+	},
+	{
+		Method: "GET", Path: "/contracts/{id}", Function: "GetContract",
+		Summary:  "Get a contract and the bond covering it",
+		Args:     []Arg{path("id")},
+		Response: Contract{},
+	},
+	{
+		Method: "POST", Path: "/contracts/{id}/cancel", Function: "CancelContract", Invoke: true,
+		Summary:  "The owner cancels an OPEN contract",
+		Args:     []Arg{path("id"), lit("CANCELCONTRACT"), field("userId", "Owner of the contract")},
+		Response: Contract{},
+	},
+	{
+		Method: "POST", Path: "/contracts/{id}/close", Function: "CloseContract", Invoke: true,
+		Summary:  "The owner closes an IN_PROGRESS contract once the work is done",
+		Args:     []Arg{path("id"), lit("CLOSECONTRACT"), field("userId", "Owner of the contract")},
+		Response: Contract{},
+	},
+	{
+		Method: "GET", Path: "/contracts/{id}/bids", Function: "GetListOfBids",
+		Summary:  "List the bids on a contract",
+		Args:     append([]Arg{path("id")}, pageArgs()...),
+		Response: page{Bid{}},
+	},
+	{
+		Method: "POST", Path: "/contracts/{id}/bids", Function: "PostBid", Invoke: true,
+		Summary: "Bid on an OPEN contract, alone or as a team",
+		Args: []Arg{
+			path("id"),
+			lit("BID"),
+			field("bidNo", "Integer number of the bid"),
+			lit(""), // not used by the chaincode
+			field("userId", "Bidder"),
+			field("price", "Integer bid price"),
+			{In: "body", Name: "members", Doc: "Team members and their percentage share, the bidder included",
+				Optional: true, Schema: []BidMember{}},
+		},
+		Response: Bid{},
+	},
+	{
+		Method: "GET", Path: "/contracts/{id}/bids/{bidNo}/offers", Function: "GetNegotiation",
+		Summary:  "List the offers of the negotiation on a bid, in order",
+		Args:     append([]Arg{path("id"), path("bidNo")}, pageArgs()...),
+		Response: page{Offer{}},
+	},
+	{
+		Method: "POST", Path: "/contracts/{id}/bids/{bidNo}/offers", Function: "CounterOffer", Invoke: true,
+		Summary: "The owner or the bidder counters the last offer on a bid",
+		Args: []Arg{
+			path("id"),
+			lit("OFFER"),
+			path("bidNo"),
+			field("userId", "Owner of the contract or bidder"),
+			field("price", "Integer price"),
+			{In: "body", Name: "duration", Doc: "Duration in days, that of the last offer by default"},
+			{In: "body", Name: "terms", Doc: "Terms, those of the last offer by default"},
+		},
+		Response: Offer{},
+	},
+	{
+		Method: "POST", Path: "/contracts/{id}/bids/{bidNo}/accept", Function: "AcceptOffer", Invoke: true,
+		Summary:  "The owner or the bidder accepts the last offer on a bid",
+		Args:     []Arg{path("id"), lit("OFFER"), path("bidNo"), field("userId", "Owner of the contract or bidder")},
+		Response: Offer{},
+	},
+	{
+		Method: "POST", Path: "/contracts/{id}/bids/{bidNo}/reject", Function: "RejectOffer", Invoke: true,
+		Summary:  "The owner or the bidder rejects the last offer on a bid, ending the negotiation",
+		Args:     []Arg{path("id"), lit("OFFER"), path("bidNo"), field("userId", "Owner of the contract or bidder")},
+		Response: Offer{},
+	},
+	{
+		Method: "POST", Path: "/contracts/{id}/bids/{bidNo}/select", Function: "SelectBidder", Invoke: true,
+		Summary:  "The owner selects a bid whose negotiation ended with an accepted offer",
+		Args:     []Arg{path("id"), lit("BID"), path("bidNo"), field("userId", "Owner of the contract")},
+		Response: Contract{},
+	},
+	{
+		Method: "POST", Path: "/contracts/{id}/transactions", Function: "PostTransaction", Invoke: true,
+		Summary: "Settle a payment on the selected bid of a contract",
+		Args: []Arg{
+			path("id"),
+			lit("POSTTRAN"),
+			field("transactionId", ""),
+			field("transType", "e.g. PAYMENT"),
+			field("userId", "Payee"),
+			{In: "body", Name: "transDate", Doc: "2006-01-02, today by default", Default: today},
+			field("amount", "Integer amount"),
+			field("bidNo", "Selected bid of the contract"),
+		},
+		Response: []Transaction{},
+	},
+	{
+		Method: "POST", Path: "/users", Function: "PostUser", Invoke: true,
+		Summary: "Register a user",
+		Args: []Arg{
+			field("userId", "Integer ID of the user"),
+			lit("USER"),
+			field("name", ""),
+			field("userType", "AH, TR, AP, IN, BK or SH"),
+			{In: "body", Name: "address"},
+			{In: "body", Name: "phone"},
+			{In: "body", Name: "email"},
+			{In: "body", Name: "bank"},
+			{In: "body", Name: "accountNo"},
+		},
+		Response: User{},
+	},
+	{
+		Method: "GET", Path: "/users/{id}", Function: "GetUser",
+		Summary:  "Get a registered user",
+		Args:     []Arg{path("id")},
+		Response: User{},
+	},
+	{
+		Method: "POST", Path: "/batch", Function: "Batch", Invoke: true,
+		Summary: "Run invokes in one transaction, all or nothing",
+		Args: []Arg{
+			lit("BATCH"),
+			{In: "", Name: "operations", Doc: "Invokes, run in order",
+				Required: true, Schema: []BatchOperation{}},
+		},
+		Response: []BatchResult{},
+	},
+}
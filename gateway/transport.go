@@ -0,0 +1,163 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+)
+
+// Transport calls the chaincode. Function and args are passed exactly as
+// the peer CLI would pass them, see InvokeFunction and QueryFunction in the
+// chaincode for the functions and their arguments.
+type Transport interface {
+	Invoke(ctx context.Context, function string, args []string) ([]byte, error)
+	Query(ctx context.Context, function string, args []string) ([]byte, error)
+}
+
+// ChaincodeError is an error returned by the chaincode itself, as opposed
+// to a failure to reach it.
+type ChaincodeError struct {
+	Function string
+	Message  string
+	Err      *errcode.Error // the error sent by the chaincode, nil if it sent none
+}
+
+// parseChaincodeError returns the ChaincodeError for the error text msg
+// returned by function.
+func parseChaincodeError(function, msg string) *ChaincodeError {
+	e, ok := errcode.Parse(msg)
+	if !ok {
+		return &ChaincodeError{Function: function, Message: msg}
+	}
+	return chaincodeError(function, e)
+}
+
+// chaincodeError returns err as returned by function.
+func chaincodeError(function string, err error) *ChaincodeError {
+	e := errcode.From(err)
+	return &ChaincodeError{Function: function, Message: e.Message, Err: e}
+}
+
+// Unwrap returns the error sent by the chaincode, so errors.As finds it.
+func (e *ChaincodeError) Unwrap() error {
+	if e.Err == nil {
+		return nil
+	}
+	return e.Err
+}
+
+func (e *ChaincodeError) Error() string {
+	return e.Function + ": " + e.Message
+}
+
+// PeerTransport calls the chaincode through the REST API of a peer.
+//
+// The peer only acknowledges an invoke with its transaction ID, the result
+// of the invoke is on the ledger once the transaction is committed.
+// Invoke therefore returns {"txid": "..."}.
+type PeerTransport struct {
+	URL       string // e.g. http://localhost:7050
+	Chaincode string // name of the deployed chaincode
+	User      string // enrolled user the requests run as, e.g. emma1
+	Client    *http.Client
+	requestID int64
+}
+
+// Invoke submits an invoke transaction.
+func (p *PeerTransport) Invoke(ctx context.Context, function string, args []string) ([]byte, error) {
+	msg, err := p.call(ctx, "invoke", function, args)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(TxResult{TxID: msg})
+}
+
+// Query runs a query and returns its result.
+func (p *PeerTransport) Query(ctx context.Context, function string, args []string) ([]byte, error) {
+	msg, err := p.call(ctx, "query", function, args)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(msg), nil
+}
+
+type rpcRequest struct {
+	JSONRPC string    `json:"jsonrpc"`
+	Method  string    `json:"method"`
+	Params  rpcParams `json:"params"`
+	ID      int64     `json:"id"`
+}
+
+type rpcParams struct {
+	Type          int               `json:"type"`
+	ChaincodeID   map[string]string `json:"chaincodeID"`
+	CtorMsg       rpcCtorMsg        `json:"ctorMsg"`
+	SecureContext string            `json:"secureContext,omitempty"`
+}
+
+type rpcCtorMsg struct {
+	Function string   `json:"function"`
+	Args     []string `json:"args"`
+}
+
+type rpcResponse struct {
+	Result *struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	} `json:"result"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    string `json:"data"`
+	} `json:"error"`
+}
+
+func (p *PeerTransport) call(ctx context.Context, method, function string, args []string) (string, error) {
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params: rpcParams{
+			Type:          1, // GOLANG
+			ChaincodeID:   map[string]string{"name": p.Chaincode},
+			CtorMsg:       rpcCtorMsg{function, args},
+			SecureContext: p.User,
+		},
+		ID: atomic.AddInt64(&p.requestID, 1),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL+"/chaincode", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var r rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return "", fmt.Errorf("gateway: peer returned %s: %s", resp.Status, err)
+	}
+	if r.Error != nil {
+		return "", parseChaincodeError(function, r.Error.Data)
+	}
+	if r.Result == nil {
+		return "", fmt.Errorf("gateway: peer returned %s without a result", resp.Status)
+	}
+	return r.Result.Message, nil
+}
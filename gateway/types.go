@@ -0,0 +1,77 @@
+package gateway
+
+import (
+	"encoding/json"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+	"github.com/AkshayKulkarni03/hackathon/model"
+)
+
+// The records below are the records written by the chaincode. They describe
+// the API in the OpenAPI document and hold the state of the Memory backend.
+
+// Contract is a contract as returned by GetContract.
+type Contract struct {
+	model.ContractObject
+	Bond json.RawMessage `json:",omitempty"`
+}
+
+// Bid is a bid on a contract.
+type Bid = model.Bid
+
+// BidMember is a member of a team bid.
+type BidMember = model.BidMember
+
+// Offer is a step of the negotiation on a bid.
+type Offer = model.Offer
+
+// User is a registered user.
+type User = model.UserObject
+
+// Transaction is a payment settled on a contract.
+type Transaction = model.ItemTransaction
+
+// BatchOperation is one invoke of a batch.
+type BatchOperation = model.BatchOperation
+
+// BatchResult is the result of one operation of a batch.
+type BatchResult = model.BatchResult
+
+// ContractFilter is the filter of a contract search.
+type ContractFilter struct {
+	Type      string
+	Status    string
+	UserID    string
+	MinAmount string
+	MaxAmount string
+	FromDate  string
+	ToDate    string
+	Text      string
+	SortBy    string // Amount, CreationDate or Deadline
+	Desc      bool
+}
+
+// Page is one page of a list.
+type Page struct {
+	Items     interface{} `json:"items"`
+	NextToken string      `json:"nextToken"`
+}
+
+// page describes a Page of the sample item in the OpenAPI document.
+type page struct {
+	item interface{}
+}
+
+// TxResult is returned by an invoke on a peer, see PeerTransport.
+type TxResult struct {
+	TxID string `json:"txid"`
+}
+
+// Error is the body of every error response. Code, Field and Class are
+// those of the errcode.Error sent by the chaincode, if it sent one.
+type Error struct {
+	Error string        `json:"error"`
+	Code  errcode.Code  `json:"code,omitempty"`
+	Field string        `json:"field,omitempty"`
+	Class errcode.Class `json:"class,omitempty"`
+}
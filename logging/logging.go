@@ -0,0 +1,237 @@
+// Package logging is the leveled, structured logger of the chaincode.
+//
+// Every line carries the level, the message and key/value fields, the
+// fields of the Logger first:
+//
+//	time=2016-10-18T10:00:00Z level=INFO msg="PostBid(): Bid posted" function=PostBid txID=5a1c... contractId=1000 userId=200
+//
+// Values of fields named in Secrets, at the top level or anywhere inside a
+// record logged as a value, are written as REDACTED.
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level is the severity of a line.
+type Level int32
+
+// Levels, from the most verbose.
+const (
+	Debug Level = iota
+	Info
+	Warning
+	Error
+)
+
+var levelNames = []string{"DEBUG", "INFO", "WARNING", "ERROR"}
+
+func (l Level) String() string {
+	if l < Debug || l > Error {
+		return "Level(" + strconv.Itoa(int(l)) + ")"
+	}
+	return levelNames[l]
+}
+
+// ParseLevel returns the level named s, e.g. DEBUG or warning. WARN is
+// accepted for WARNING.
+func ParseLevel(s string) (Level, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "WARN" {
+		return Warning, nil
+	}
+	for i, name := range levelNames {
+		if s == name {
+			return Level(i), nil
+		}
+	}
+	return Info, fmt.Errorf("logging: unknown level %q, expecting DEBUG, INFO, WARNING or ERROR", s)
+}
+
+// Secrets are the field names whose values are never written. Names match
+// regardless of case, underscores and dashes, so AES_Key matches aeskey.
+var Secrets = []string{"aeskey", "secretkey", "secret", "password", "privatekey", "accountno"}
+
+// Redacted replaces the value of a secret field.
+const Redacted = "REDACTED"
+
+// IsSecret reports whether the value of the field named key is secret.
+func IsSecret(key string) bool {
+	k := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	for _, s := range Secrets {
+		if k == s {
+			return true
+		}
+	}
+	return false
+}
+
+// output is shared by a Logger and the loggers derived from it.
+type output struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level int32 // Level, accessed atomically
+}
+
+// Logger writes lines at or above its level. Derived loggers share the
+// writer and the level of their parent.
+type Logger struct {
+	out    *output
+	fields []interface{}
+}
+
+// New returns a logger writing lines at or above level to w.
+func New(w io.Writer, level Level) *Logger {
+	l := &Logger{out: &output{w: w}}
+	l.SetLevel(level)
+	return l
+}
+
+// SetLevel sets the level of l and of every logger sharing its output.
+func (l *Logger) SetLevel(level Level) {
+	atomic.StoreInt32(&l.out.level, int32(level))
+}
+
+// Level returns the level of l.
+func (l *Logger) Level() Level {
+	return Level(atomic.LoadInt32(&l.out.level))
+}
+
+// Enabled reports whether lines of level are written.
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.Level()
+}
+
+// With returns a logger adding the key/value pairs kv to every line.
+func (l *Logger) With(kv ...interface{}) *Logger {
+	fields := make([]interface{}, 0, len(l.fields)+len(kv))
+	return &Logger{out: l.out, fields: append(append(fields, l.fields...), kv...)}
+}
+
+// Debug writes msg with the key/value pairs kv at level Debug.
+func (l *Logger) Debug(msg string, kv ...interface{}) { l.log(Debug, msg, kv) }
+
+// Info writes msg with the key/value pairs kv at level Info.
+func (l *Logger) Info(msg string, kv ...interface{}) { l.log(Info, msg, kv) }
+
+// Warning writes msg with the key/value pairs kv at level Warning.
+func (l *Logger) Warning(msg string, kv ...interface{}) { l.log(Warning, msg, kv) }
+
+// Error writes msg with the key/value pairs kv at level Error.
+func (l *Logger) Error(msg string, kv ...interface{}) { l.log(Error, msg, kv) }
+
+func (l *Logger) log(level Level, msg string, kv []interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	var b bytes.Buffer
+	b.WriteString("time=")
+	b.WriteString(time.Now().UTC().Format(time.RFC3339))
+	b.WriteString(" level=")
+	b.WriteString(level.String())
+	b.WriteString(" msg=")
+	b.WriteString(quote(strings.TrimSpace(msg)))
+	writeFields(&b, l.fields)
+	writeFields(&b, kv)
+	b.WriteByte('\n')
+
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	l.out.w.Write(b.Bytes())
+}
+
+func writeFields(b *bytes.Buffer, kv []interface{}) {
+	for i := 0; i < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok || i+1 == len(kv) {
+			// A value without a key
+			key, i = "!BADKEY", i-1
+		}
+		b.WriteByte(' ')
+		b.WriteString(key)
+		b.WriteByte('=')
+		if IsSecret(key) {
+			b.WriteString(Redacted)
+		} else {
+			b.WriteString(quote(format(kv[i+1])))
+		}
+	}
+}
+
+// format returns the text of a value. Records are written as their JSON
+// with secret fields redacted.
+func format(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	case bool, int, int32, int64, uint, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	case []byte:
+		// Unmarshal checks the whole input, like json.Valid of newer Go
+		if json.Unmarshal(v, new(json.RawMessage)) == nil {
+			return redactJSON(v)
+		}
+		return fmt.Sprintf("[%d bytes]", len(v))
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%+v", v)
+	}
+	return redactJSON(b)
+}
+
+func redactJSON(b []byte) string {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return string(b)
+	}
+	out, err := json.Marshal(redact(v))
+	if err != nil {
+		return string(b)
+	}
+	return string(out)
+}
+
+func redact(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if IsSecret(k) {
+				v[k] = Redacted
+			} else {
+				v[k] = redact(e)
+			}
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = redact(e)
+		}
+	}
+	return v
+}
+
+// quote quotes s unless it is a single word.
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\t\n\r") {
+		return strconv.Quote(s)
+	}
+	return s
+}
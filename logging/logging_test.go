@@ -0,0 +1,118 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type account struct {
+	Bank      string
+	AccountNo string
+}
+
+type user struct {
+	UserID   string
+	Accounts []account
+	Profile  map[string]interface{}
+}
+
+func TestRedaction(t *testing.T) {
+	tests := []struct {
+		name    string
+		kv      []interface{}
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "top level",
+			kv:   []interface{}{"userId", "100", "password", "hunter2", "AES_Key", "0123"},
+			want: []string{"userId=100", "password=REDACTED", "AES_Key=REDACTED"},
+		},
+		{
+			name: "nested record",
+			kv: []interface{}{"record", user{UserID: "100",
+				Accounts: []account{{Bank: "Bank of America", AccountNo: "0001234"}},
+				Profile:  map[string]interface{}{"login": map[string]interface{}{"Secret-Key": "s3cr3t", "name": "ahart"}}}},
+			// Values are quoted, so the JSON quotes are escaped
+			want:    []string{`\"AccountNo\":\"REDACTED\"`, `\"Secret-Key\":\"REDACTED\"`, `\"name\":\"ahart\"`, `\"UserID\":\"100\"`},
+			notWant: []string{"0001234", "s3cr3t"},
+		},
+		{
+			name:    "JSON bytes",
+			kv:      []interface{}{"buff", []byte(`[{"privateKey":"k1","n":12345678901234567890}]`)},
+			want:    []string{`\"privateKey\":\"REDACTED\"`, `\"n\":12345678901234567890`},
+			notWant: []string{"k1"},
+		},
+		{
+			name: "binary bytes",
+			kv:   []interface{}{"image", []byte{0xff, 0x00, 0x01}},
+			want: []string{`image="[3 bytes]"`},
+		},
+		{
+			name: "bad key",
+			kv:   []interface{}{"userId", "100", errors.New("dangling")},
+			want: []string{"userId=100", "!BADKEY=dangling"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			New(&b, Debug).Info("PostUser(): User posted", tt.kv...)
+			line := b.String()
+			for _, w := range tt.want {
+				if !strings.Contains(line, w) {
+					t.Errorf("line %q lacks %q", line, w)
+				}
+			}
+			for _, w := range tt.notWant {
+				if strings.Contains(line, w) {
+					t.Errorf("line %q leaks %q", line, w)
+				}
+			}
+		})
+	}
+}
+
+func TestWithFieldsRedacted(t *testing.T) {
+	var b bytes.Buffer
+	New(&b, Debug).With("function", "PostUser", "secret", "x1").Debug("msg")
+	if line := b.String(); !strings.Contains(line, "function=PostUser secret=REDACTED") || strings.Contains(line, "x1") {
+		t.Errorf("line = %q", line)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	var b bytes.Buffer
+	l := New(&b, Warning)
+	child := l.With("txID", "tx1")
+	child.Info("dropped")
+	child.Warning("kept")
+	l.SetLevel(Error)
+	child.Warning("dropped")
+	if got := strings.Count(b.String(), "\n"); got != 1 || !strings.Contains(b.String(), "level=WARNING msg=kept txID=tx1") {
+		t.Errorf("output = %q", b.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Level
+		wantErr bool
+	}{
+		{"DEBUG", Debug, false},
+		{" info ", Info, false},
+		{"warn", Warning, false},
+		{"Warning", Warning, false},
+		{"error", Error, false},
+		{"verbose", Info, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
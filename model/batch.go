@@ -0,0 +1,71 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+)
+
+// Limits of a Batch invoke, whose operations all run in one transaction.
+const (
+	MaxBatchOps   = 200
+	MaxBatchBytes = 512 << 10
+)
+
+// BatchOperation is one invoke of a Batch.
+type BatchOperation struct {
+	Function string   `json:"function"`
+	Args     []string `json:"args"`
+}
+
+// BatchResult is the result of one operation of a Batch, in the order of
+// the operations.
+type BatchResult struct {
+	Function string          `json:"function"`
+	Result   json.RawMessage `json:"result"`
+}
+
+// CreateBatch checks the arguments of a Batch invoke, ["BATCH", operations]
+// with operations a JSON array of BatchOperation, and returns the
+// operations. A Batch cannot contain another Batch, and client request IDs
+// go on the Batch, not on its operations.
+func CreateBatch(args []string) ([]BatchOperation, error) {
+	if len(args) != 2 {
+		return nil, errcode.New(errcode.ArgCount, "CreateBatch(): Incorrect number of arguments. Expecting 2")
+	}
+	if len(args[1]) > MaxBatchBytes {
+		return nil, errcode.Errorf(errcode.InvalidArgument, "CreateBatch(): Operations exceed %d bytes", MaxBatchBytes).WithField("operations")
+	}
+
+	var ops []BatchOperation
+	if err := json.Unmarshal([]byte(args[1]), &ops); err != nil {
+		return nil, errcode.New(errcode.InvalidArgument, "CreateBatch(): Operations should be a JSON array of {function, args} : "+err.Error()).WithField("operations")
+	}
+	if len(ops) == 0 || len(ops) > MaxBatchOps {
+		return nil, errcode.Errorf(errcode.InvalidArgument, "CreateBatch(): Expecting 1 to %d operations, got %d", MaxBatchOps, len(ops)).WithField("operations")
+	}
+
+	for i, op := range ops {
+		field := "operations[" + strconv.Itoa(i) + "]"
+		if op.Function == "" || op.Function == "Batch" {
+			return nil, errcode.Errorf(errcode.UnknownFunction, "CreateBatch(): Invalid function in operation %d : %q", i, op.Function).WithField(field + ".function")
+		}
+		if id, _ := SplitRequestID(op.Function); id != "" {
+			return nil, errcode.Errorf(errcode.InvalidArgument, "CreateBatch(): Operation %d has a request ID, set it on the Batch", i).WithField(field + ".function")
+		}
+	}
+	return ops, nil
+}
+
+// BatchError returns the error of a Batch whose operation i, an invoke of
+// function, failed with err. The field of err is qualified with the
+// operation.
+func BatchError(i int, function string, err error) *errcode.Error {
+	e := errcode.From(err)
+	field := "operations[" + strconv.Itoa(i) + "]"
+	if e.Field != "" {
+		field += "." + e.Field
+	}
+	return errcode.Wrapf(err, "Batch(): Operation %d (%s) failed, no operation was applied : %s", i, function, e.Message).WithField(field)
+}
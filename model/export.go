@@ -0,0 +1,69 @@
+package model
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+)
+
+// ExportLine is one line of the JSON Lines written by the ExportTables
+// query: a row of a chaincode table, its key columns and its decoded
+// Details record. The last line of a page that is not the last holds only
+// the token of the next page.
+type ExportLine struct {
+	Table     string          `json:"table,omitempty"`
+	Keys      []string        `json:"keys,omitempty"`
+	Details   json.RawMessage `json:"details,omitempty"`
+	NextToken string          `json:"nextToken,omitempty"`
+}
+
+// MaxExportLineBytes is the longest line ReadExport accepts.
+const MaxExportLineBytes = 4 << 20
+
+// ExportDetails returns the Details column of a row as written on an
+// ExportLine. Records are JSON and are written as is, anything else as a
+// JSON string.
+func ExportDetails(details []byte) json.RawMessage {
+	// Unmarshal validates the whole input, json.Valid needs Go 1.9
+	if json.Unmarshal(details, new(json.RawMessage)) == nil {
+		return json.RawMessage(details)
+	}
+	b, _ := json.Marshal(string(details))
+	return b
+}
+
+// ImportDetails returns the Details column of the row of line, reversing
+// ExportDetails.
+func ImportDetails(line ExportLine) []byte {
+	var s string
+	if json.Unmarshal(line.Details, &s) == nil {
+		return []byte(s)
+	}
+	return line.Details
+}
+
+// ReadExport calls fn with every row of the JSON Lines in r, skipping
+// blank lines and next page tokens, and stops at the first error. Errors
+// name the line and keep the code of the error of fn.
+func ReadExport(r io.Reader, fn func(ExportLine) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, MaxExportLineBytes)
+	for n := 1; sc.Scan(); n++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var line ExportLine
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			return errcode.Errorf(errcode.InvalidArgument, "ReadExport(): Line %d is not an export line : %s", n, err)
+		}
+		if line.Table == "" {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return errcode.Wrapf(err, "Line %d : %s", n, err)
+		}
+	}
+	return sc.Err()
+}
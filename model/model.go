@@ -0,0 +1,274 @@
+// Package model holds the ledger records of the contract marketplace and
+// the constructors that build them from chaincode arguments.
+//
+// The chaincode and its clients share this package, so a client can check
+// an argument list with the very constructor the chaincode will run on it
+// before submitting the transaction.
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+)
+
+// ContractObject is a contract posted for bids.
+type ContractObject struct {
+	ContractId             string
+	Amount                 string
+	Duration               string
+	BusinessRule           string
+	Type                   string
+	RequirementDescription string
+	Description            string
+	Terms                  string
+	CreationDate           string
+	UserID                 string
+	Status                 string // OPEN/IN_PROGRESS/CLOSED/CANCELLED, DISPUTED/RESOLVED when a dispute is raised
+	RecType                string
+	WorkerID               string   // User whose bid was selected by SelectBidder
+	BidNo                  string   // Number of the selected bid
+	BidPrice               string   // Price of the selected bid
+	TemplateId             string   // Set when created by PostRequestFromTemplate
+	TemplateVersion        string   //
+	TemplateValues         string   // JSON object of the placeholder values used
+	SeriesId               string   // ContractId of the first cycle of a recurring contract
+	Cycle                  string   //
+	Recurrence             string   // WEEKLY/MONTHLY/QUARTERLY/YEARLY, see SetRecurrence
+	Cycles                 string   //
+	ParentId               string   // Set on child contracts posted by PostSubcontract
+	Skills                 []string // SkillIds required, see SetContractSkills
+}
+
+// UserObject is a registered user.
+type UserObject struct {
+	UserID      string
+	RecType     string // Type = USER
+	Name        string
+	UserType    string // Auction House (AH), Bank (BK), Buyer or Seller (TR), Shipper (SH), Appraiser (AP)
+	Address     string
+	Phone       string
+	Email       string
+	Bank        string
+	AccountNo   string
+	Rating      string // Recency weighted review score, recomputed by PostReview
+	ReviewCount string
+	ReviewMean  string
+}
+
+// UserTypes are the valid values of UserObject.UserType, IN is Insurance.
+var UserTypes = []string{"AH", "TR", "AP", "IN", "BK", "SH"}
+
+// Bid is a bid on an OPEN contract.
+type Bid struct {
+	ContractId  string
+	RecType     string      // BID
+	BidNo       string      //
+	UserID      string      // ID Of Buyer - to be verified against the Item CurrentOwnerId
+	BidPrice    string      // BidPrice
+	BidTime     string      // Time the bid was received
+	Members     []BidMember // Set for team bids, see ParseBidMembers
+	CarriedFrom string      // Previous cycle of a bid carried over to the next cycle of a series
+}
+
+// BidMember is a member of a team bid.
+type BidMember struct {
+	UserID   string
+	Share    string // Percentage of the revenue
+	Signed   bool
+	SignTime string
+}
+
+// Offer is a step of the negotiation on a bid between the owner of the
+// contract and the bidder. The bid is the opening offer, SelectBidder awards
+// a bid whose last offer is an ACCEPT.
+type Offer struct {
+	ContractId string
+	RecType    string // OFFER
+	BidNo      string
+	Seq        string
+	UserID     string
+	Role       string // OWNER or BIDDER
+	Action     string // COUNTER, ACCEPT or REJECT
+	Price      string
+	Duration   string
+	Terms      string
+	OfferTime  string
+}
+
+// ItemTransaction is a payment settled on a contract.
+type ItemTransaction struct {
+	ConractId         string
+	RecType           string // POSTTRAN
+	TransactionId     string
+	TransType         string // Sale, Buy, Commission
+	UserId            string // Buyer or Seller ID
+	TransDate         string // Date of Settlement (Buyer or Seller)
+	TransactionAmount string // Time of hammer strike - SOLD
+	BidNo             string
+}
+
+// CreateContract builds a new OPEN contract from the arguments of PostRequest:
+//
+//	ContractId, Amount, Duration, BusinessRule, Type, RequirementDescription,
+//	Description, Terms, CreationDate, UserID, RecType
+func CreateContract(args []string) (ContractObject, error) {
+
+	var myItem ContractObject
+
+	// Check there are 11 Arguments provided as per the the struct - two are computed
+	if len(args) != 11 {
+		return myItem, errcode.New(errcode.ArgCount, "CreateContract(): Incorrect number of arguments. Expecting 11 ")
+	}
+
+	if _, err := strconv.Atoi(args[0]); err != nil {
+		return myItem, errcode.New(errcode.InvalidArgument, "CreateContract(): contract ID should be an integer create failed!").WithField("ContractId")
+	}
+
+	// A new contract is OPEN for bids and has no worker yet
+	myItem = ContractObject{ContractId: args[0], Amount: args[1], Duration: args[2], BusinessRule: args[3], Type: args[4],
+		RequirementDescription: args[5], Description: args[6], Terms: args[7], CreationDate: args[8], UserID: args[9],
+		Status: "OPEN", RecType: args[10]}
+
+	return myItem, nil
+}
+
+// CreateUser builds a user from the arguments of PostUser:
+//
+//	UserID, RecType, Name, UserType, Address, Phone, Email, Bank, AccountNo
+func CreateUser(args []string) (UserObject, error) {
+
+	var user UserObject
+
+	if len(args) != 9 {
+		return user, errcode.New(errcode.ArgCount, "CreateUser(): Incorrect number of arguments. Expecting 9 ")
+	}
+
+	if _, err := strconv.Atoi(args[0]); err != nil {
+		return user, errcode.New(errcode.InvalidArgument, "CreateUser(): User ID should be an integer : "+args[0]).WithField("UserID")
+	}
+
+	valid := false
+	for _, t := range UserTypes {
+		valid = valid || t == args[3]
+	}
+	if !valid {
+		return user, errcode.New(errcode.InvalidArgument, "CreateUser(): User Type should be one of AH, TR, AP, IN, BK or SH : "+args[3]).WithField("UserType")
+	}
+
+	user = UserObject{UserID: args[0], RecType: args[1], Name: args[2], UserType: args[3], Address: args[4],
+		Phone: args[5], Email: args[6], Bank: args[7], AccountNo: args[8]}
+
+	return user, nil
+}
+
+// CreateBidObject builds a bid from the arguments of PostBid:
+//
+//	ContractId, RecType, BidNo, (unused), UserID, BidPrice[, Members]
+//
+// Members is the JSON array of a team bid, see ParseBidMembers.
+func CreateBidObject(args []string) (Bid, error) {
+	var err error
+	var aBid Bid
+
+	// Check there are 6 Arguments, 7 for a team bid
+	// args[3] is not used - it held the Item ID in the auction version of this chaincode
+	if len(args) != 6 && len(args) != 7 {
+		return aBid, errcode.New(errcode.ArgCount, "CreateBidObject() : Incorrect number of arguments. Expecting 6 or 7 ")
+	}
+
+	if _, err = strconv.Atoi(args[0]); err != nil {
+		return aBid, errcode.New(errcode.InvalidArgument, "CreateBidObject() : Contract ID should be an integer").WithField("ContractId")
+	}
+
+	if _, err = strconv.Atoi(args[2]); err != nil {
+		return aBid, errcode.New(errcode.InvalidArgument, "CreateBidObject() : Bid ID should be an integer").WithField("BidNo")
+	}
+
+	if _, err = strconv.Atoi(args[5]); err != nil {
+		return aBid, errcode.New(errcode.InvalidArgument, "CreateBidObject() : Bid price should be an integer").WithField("BidPrice")
+	}
+
+	bidTime := time.Now().Format("2006-01-02 15:04:05")
+
+	aBid = Bid{ContractId: args[0], RecType: args[1], BidNo: args[2], UserID: args[4], BidPrice: args[5], BidTime: bidTime}
+
+	if len(args) == 7 {
+		aBid.Members, err = ParseBidMembers(aBid.UserID, args[6])
+		if err != nil {
+			return aBid, err
+		}
+	}
+
+	return aBid, nil
+}
+
+// ParseBidMembers parses and checks the members of a team bid. The bidder
+// must be one of the members and has signed by placing the bid.
+func ParseBidMembers(bidderId string, membersJSON string) ([]BidMember, error) {
+
+	var members []BidMember
+	err := json.Unmarshal([]byte(membersJSON), &members)
+	if err != nil {
+		return nil, errcode.New(errcode.InvalidArgument, "ParseBidMembers(): Members should be a JSON array : "+membersJSON).WithField("Members")
+	}
+
+	if len(members) < 2 {
+		return nil, errcode.New(errcode.InvalidArgument, "ParseBidMembers(): A team bid needs at least 2 members").WithField("Members")
+	}
+
+	total := 0
+	seen := make(map[string]bool)
+	for i := range members {
+		share, err := strconv.Atoi(members[i].Share)
+		if err != nil || share <= 0 {
+			return nil, errcode.New(errcode.InvalidArgument, "ParseBidMembers(): Share should be a positive integer : "+members[i].UserID).WithField("Members")
+		}
+		if seen[members[i].UserID] {
+			return nil, errcode.New(errcode.InvalidArgument, "ParseBidMembers(): Member listed twice : "+members[i].UserID).WithField("Members")
+		}
+		seen[members[i].UserID] = true
+		total += share
+
+		members[i].Signed = false
+		members[i].SignTime = ""
+		if members[i].UserID == bidderId {
+			members[i].Signed = true
+			members[i].SignTime = time.Now().Format("2006-01-02 15:04:05")
+		}
+	}
+
+	if total != 100 {
+		return nil, errcode.Errorf(errcode.InvalidArgument, "ParseBidMembers(): Shares should add up to 100, got %d", total).WithField("Members")
+	}
+
+	if !seen[bidderId] {
+		return nil, errcode.New(errcode.InvalidArgument, "ParseBidMembers(): Bidder is not a member of the team : "+bidderId).WithField("Members")
+	}
+
+	return members, nil
+}
+
+// CreateTransactionRequest builds a payment from the arguments of
+// PostTransaction:
+//
+//	ContractId, RecType, TransactionId, TransType, UserId, TransDate, Amount, BidNo
+func CreateTransactionRequest(args []string) (ItemTransaction, error) {
+
+	var at ItemTransaction
+
+	// Check there are 8 Arguments
+	if len(args) != 8 {
+		return at, errcode.New(errcode.ArgCount, "CreateTransactionRequest() : Incorrect number of arguments. Expecting 8 ")
+	}
+
+	if _, err := strconv.Atoi(args[6]); err != nil {
+		return at, errcode.New(errcode.InvalidArgument, "CreateTransactionRequest() : Transaction Amount should be an integer").WithField("TransactionAmount")
+	}
+
+	at = ItemTransaction{args[0], args[1], args[2], args[3], args[4], args[5], args[6], args[7]}
+
+	return at, nil
+}
@@ -0,0 +1,212 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AkshayKulkarni03/hackathon/errcode"
+)
+
+// code returns the code of err, empty for nil.
+func code(err error) errcode.Code {
+	if err == nil {
+		return ""
+	}
+	return errcode.From(err).Code
+}
+
+func TestCreateContract(t *testing.T) {
+	args := []string{"1000", "5000", "30", "Fixed price", "IT", "Responsive web site", "Build a web shop", "Net 30",
+		"2016-10-18 10:00:00", "100", "CREATECONTR"}
+	tests := []struct {
+		name      string
+		args      []string
+		wantCode  errcode.Code
+		wantField string
+	}{
+		{"valid", args, "", ""},
+		{"too few", args[:10], errcode.ArgCount, ""},
+		{"bad id", append([]string{"x1000"}, args[1:]...), errcode.InvalidArgument, "ContractId"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := CreateContract(tt.args)
+			if code(err) != tt.wantCode || (err != nil && errcode.From(err).Field != tt.wantField) {
+				t.Fatalf("CreateContract() error = %v, want code %q field %q", err, tt.wantCode, tt.wantField)
+			}
+			if err != nil {
+				return
+			}
+			want := ContractObject{ContractId: "1000", Amount: "5000", Duration: "30", BusinessRule: "Fixed price", Type: "IT",
+				RequirementDescription: "Responsive web site", Description: "Build a web shop", Terms: "Net 30",
+				CreationDate: "2016-10-18 10:00:00", UserID: "100", Status: "OPEN", RecType: "CREATECONTR"}
+			if !reflect.DeepEqual(c, want) {
+				t.Errorf("CreateContract() = %+v, want %+v", c, want)
+			}
+		})
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	args := func(id, userType string) []string {
+		return []string{id, "USER", "Ashley Hart", userType, "One Market Street", "9161234567", "ahart@example.com", "Bank of America", "0001234"}
+	}
+	tests := []struct {
+		name      string
+		args      []string
+		wantCode  errcode.Code
+		wantField string
+	}{
+		{"valid", args("100", "TR"), "", ""},
+		{"auction house", args("100", "AH"), "", ""},
+		{"too few", args("100", "TR")[:8], errcode.ArgCount, ""},
+		{"bad id", args("ah", "TR"), errcode.InvalidArgument, "UserID"},
+		{"bad type", args("100", "XX"), errcode.InvalidArgument, "UserType"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := CreateUser(tt.args)
+			if code(err) != tt.wantCode || (err != nil && errcode.From(err).Field != tt.wantField) {
+				t.Fatalf("CreateUser() error = %v, want code %q field %q", err, tt.wantCode, tt.wantField)
+			}
+			if err == nil && (u.UserID != tt.args[0] || u.UserType != tt.args[3] || u.AccountNo != "0001234") {
+				t.Errorf("CreateUser() = %+v", u)
+			}
+		})
+	}
+}
+
+func TestCreateBidObject(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantCode    errcode.Code
+		wantField   string
+		wantMembers int
+	}{
+		{"single", []string{"1000", "BID", "1", "", "200", "1200"}, "", "", 0},
+		{"team", []string{"1000", "BID", "3", "", "400", "2500", `[{"UserID":"400","Share":"60"},{"UserID":"401","Share":"40"}]`}, "", "", 2},
+		{"too few", []string{"1000", "BID", "1", "", "200"}, errcode.ArgCount, "", 0},
+		{"bad contract", []string{"c", "BID", "1", "", "200", "1200"}, errcode.InvalidArgument, "ContractId", 0},
+		{"bad bid", []string{"1000", "BID", "one", "", "200", "1200"}, errcode.InvalidArgument, "BidNo", 0},
+		{"bad price", []string{"1000", "BID", "1", "", "200", "12.50"}, errcode.InvalidArgument, "BidPrice", 0},
+		{"bad team", []string{"1000", "BID", "3", "", "400", "2500", `[]`}, errcode.InvalidArgument, "Members", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bid, err := CreateBidObject(tt.args)
+			if code(err) != tt.wantCode || (err != nil && errcode.From(err).Field != tt.wantField) {
+				t.Fatalf("CreateBidObject() error = %v, want code %q field %q", err, tt.wantCode, tt.wantField)
+			}
+			if err != nil {
+				return
+			}
+			if bid.ContractId != tt.args[0] || bid.BidNo != tt.args[2] || bid.UserID != tt.args[4] || bid.BidPrice != tt.args[5] ||
+				bid.BidTime == "" || len(bid.Members) != tt.wantMembers {
+				t.Errorf("CreateBidObject() = %+v", bid)
+			}
+		})
+	}
+}
+
+func TestParseBidMembers(t *testing.T) {
+	tests := []struct {
+		name    string
+		bidder  string
+		members string
+		wantErr string
+	}{
+		{"valid", "400", `[{"UserID":"400","Share":"60"},{"UserID":"401","Share":"40"}]`, ""},
+		{"signatures reset", "400", `[{"UserID":"400","Share":"50"},{"UserID":"401","Share":"50","Signed":true,"SignTime":"2016-10-18 10:00:00"}]`, ""},
+		{"not json", "400", `400:60,401:40`, "JSON array"},
+		{"one member", "400", `[{"UserID":"400","Share":"100"}]`, "at least 2"},
+		{"zero share", "400", `[{"UserID":"400","Share":"100"},{"UserID":"401","Share":"0"}]`, "positive integer"},
+		{"listed twice", "400", `[{"UserID":"400","Share":"50"},{"UserID":"400","Share":"50"}]`, "listed twice"},
+		{"total", "400", `[{"UserID":"400","Share":"60"},{"UserID":"401","Share":"30"}]`, "add up to 100, got 90"},
+		{"bidder missing", "402", `[{"UserID":"400","Share":"60"},{"UserID":"401","Share":"40"}]`, "not a member"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			members, err := ParseBidMembers(tt.bidder, tt.members)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) || errcode.From(err).Field != "Members" {
+					t.Fatalf("ParseBidMembers() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseBidMembers() error = %v", err)
+			}
+			// Only the bidder has signed, by placing the bid
+			for _, m := range members {
+				if signed := m.UserID == tt.bidder; m.Signed != signed || (m.SignTime != "") != signed {
+					t.Errorf("member %+v, want signed %v", m, signed)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateTransactionRequest(t *testing.T) {
+	args := []string{"1000", "POSTTRAN", "5000", "PAYMENT", "200", "2016-10-01", "900", "1"}
+	at, err := CreateTransactionRequest(args)
+	want := ItemTransaction{ConractId: "1000", RecType: "POSTTRAN", TransactionId: "5000", TransType: "PAYMENT",
+		UserId: "200", TransDate: "2016-10-01", TransactionAmount: "900", BidNo: "1"}
+	if err != nil || at != want {
+		t.Errorf("CreateTransactionRequest() = %+v, %v, want %+v", at, err, want)
+	}
+
+	bad := append([]string(nil), args...)
+	bad[6] = "nine hundred"
+	if _, err := CreateTransactionRequest(bad); code(err) != errcode.InvalidArgument || errcode.From(err).Field != "TransactionAmount" {
+		t.Errorf("CreateTransactionRequest(bad amount) error = %v", err)
+	}
+	if _, err := CreateTransactionRequest(args[:7]); code(err) != errcode.ArgCount {
+		t.Errorf("CreateTransactionRequest(7 args) error = %v", err)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	withID := WithRequestID("PostBid", "7f3c9a")
+	if withID != "PostBid?requestId=7f3c9a" {
+		t.Errorf("WithRequestID() = %q", withID)
+	}
+	if id, function := SplitRequestID(withID); id != "7f3c9a" || function != "PostBid" {
+		t.Errorf("SplitRequestID(%q) = %q, %q", withID, id, function)
+	}
+	if id, function := SplitRequestID("PostBid"); id != "" || function != "PostBid" {
+		t.Errorf("SplitRequestID(PostBid) = %q, %q", id, function)
+	}
+	if got := WithRequestID("PostBid", ""); got != "PostBid" {
+		t.Errorf("WithRequestID(PostBid, \"\") = %q", got)
+	}
+
+	args := []string{"1000", "BID", "1"}
+	if RequestDigest("PostBid", args) == RequestDigest("PostBid", []string{"1000", "BID", "2"}) {
+		t.Error("RequestDigest does not depend on the arguments")
+	}
+}
+
+func TestCreateBatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		ops       string
+		wantCode  errcode.Code
+		wantField string
+	}{
+		{"valid", `[{"function":"PostBid","args":["1000","BID","1","","200","1200"]}]`, "", ""},
+		{"not json", `PostBid`, errcode.InvalidArgument, "operations"},
+		{"empty", `[]`, errcode.InvalidArgument, "operations"},
+		{"nested", `[{"function":"PostBid","args":[]},{"function":"Batch","args":[]}]`, errcode.UnknownFunction, "operations[1].function"},
+		{"request id", `[{"function":"PostBid?requestId=1","args":["1000"]}]`, errcode.InvalidArgument, "operations[0].function"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateBatch([]string{"BATCH", tt.ops})
+			if code(err) != tt.wantCode || (err != nil && errcode.From(err).Field != tt.wantField) {
+				t.Errorf("CreateBatch() error = %v, want code %q field %q", err, tt.wantCode, tt.wantField)
+			}
+		})
+	}
+}
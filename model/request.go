@@ -0,0 +1,42 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// RequestIDSeparator joins an optional client request ID to the function
+// name of an invoke, e.g. PostTransaction?requestId=7f3c9a. The arguments
+// are left alone as any of them can be free text. A retry with the same ID
+// and arguments returns the result of the first invoke instead of running
+// it again.
+const RequestIDSeparator = "?requestId="
+
+// SplitRequestID returns the client request ID of an invoke of function,
+// empty if it has none, and the function name without it.
+func SplitRequestID(function string) (string, string) {
+	i := strings.Index(function, RequestIDSeparator)
+	if i < 0 {
+		return "", function
+	}
+	return function[i+len(RequestIDSeparator):], function[:i]
+}
+
+// WithRequestID returns function with the client request ID id joined to
+// it, function unchanged if id is empty.
+func WithRequestID(function string, id string) string {
+	if id == "" {
+		return function
+	}
+	return function + RequestIDSeparator + id
+}
+
+// RequestDigest returns the hex SHA-256 of an invoke of function with args,
+// which tells a retry from another request reusing its ID.
+func RequestDigest(function string, args []string) string {
+	b, _ := json.Marshal(append([]string{function}, args...))
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,59 @@
+package relay
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// Failure is a delivery that was given up.
+type Failure struct {
+	Time     time.Time
+	URL      string
+	Name     string
+	TxID     string
+	Seq      int `json:",omitempty"` // of an operation of a batch
+	Attempts int
+	Error    string
+	Payload  json.RawMessage // the event payload as it would have been delivered
+}
+
+// DeadLetter records deliveries that were given up.
+type DeadLetter interface {
+	Write(f Failure) error
+}
+
+// FileDeadLetter appends failures as JSON Lines to a file.
+type FileDeadLetter struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// OpenFileDeadLetter opens path for appending, creating it if needed.
+func OpenFileDeadLetter(path string) (*FileDeadLetter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileDeadLetter{f: f}, nil
+}
+
+// Write appends one failure and syncs it to disk.
+func (l *FileDeadLetter) Write(f Failure) error {
+	b, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	return l.f.Sync()
+}
+
+// Close closes the file.
+func (l *FileDeadLetter) Close() error {
+	return l.f.Close()
+}
@@ -0,0 +1,80 @@
+package relay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// FileSource replays events from a file holding one event payload per
+// line, e.g. captured from a peer or written by hand for local testing.
+// Blank lines and lines starting with # are skipped.
+type FileSource struct {
+	// Follow keeps waiting for lines appended to the file instead of
+	// returning ErrDone at its end.
+	Follow bool
+	// Poll is how often a followed file is checked for new lines.
+	Poll time.Duration
+
+	f    *os.File
+	r    *bufio.Reader
+	line int
+}
+
+// OpenFileSource opens the replay file at path.
+func OpenFileSource(path string) (*FileSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSource{Poll: time.Second, f: f, r: bufio.NewReader(f)}, nil
+}
+
+// Next returns the event on the next line of the file.
+func (s *FileSource) Next(ctx context.Context) (Event, error) {
+	var partial []byte
+	for {
+		b, err := s.r.ReadBytes('\n')
+		partial = append(partial, b...)
+		if err == io.EOF && s.Follow {
+			// Wait for the rest of the line to be written
+			select {
+			case <-ctx.Done():
+				return Event{}, ctx.Err()
+			case <-time.After(s.Poll):
+			}
+			continue
+		}
+		if err != nil && err != io.EOF {
+			return Event{}, err
+		}
+		if len(partial) == 0 && err == io.EOF {
+			return Event{}, ErrDone
+		}
+
+		s.line++
+		raw := bytes.TrimSpace(partial)
+		partial = nil
+		if len(raw) == 0 || raw[0] == '#' {
+			if err == io.EOF {
+				return Event{}, ErrDone
+			}
+			continue
+		}
+
+		ev, perr := ParseEvent(raw)
+		if perr != nil {
+			return Event{}, fmt.Errorf("relay: %s line %d: %s", s.f.Name(), s.line, perr)
+		}
+		return ev, nil
+	}
+}
+
+// Close closes the file.
+func (s *FileSource) Close() error {
+	return s.f.Close()
+}
@@ -0,0 +1,64 @@
+// Package relay forwards chaincode events to HTTP webhooks.
+//
+// Events are read from a Source, one at a time and in ledger order, and
+// delivered to every Webhook subscribed to their name. Each delivery is
+// signed with the secret of the webhook, retried with exponential backoff
+// while the receiver is unavailable and written to a DeadLetter log once it
+// is given up.
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/AkshayKulkarni03/hackathon/events"
+)
+
+// Event is a chaincode event as set by the chaincode.
+type Event struct {
+	events.Envelope
+	Raw []byte // the event payload, delivered unchanged
+}
+
+// ParseEvent reads the envelope of a raw chaincode event payload.
+func ParseEvent(raw []byte) (Event, error) {
+	env, _, err := events.Decode(raw)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{Envelope: env, Raw: raw}, nil
+}
+
+// Batch returns the events of the operations of a BatchApplied event, nil
+// for any other event.
+func (e Event) Batch() ([]Event, error) {
+	if e.Name != events.BatchApplied {
+		return nil, nil
+	}
+	var batch events.BatchEvent
+	if err := json.Unmarshal(e.Payload, &batch); err != nil {
+		return nil, err
+	}
+	evs := make([]Event, len(batch.Events))
+	for i, env := range batch.Events {
+		raw, err := json.Marshal(env)
+		if err != nil {
+			return nil, err
+		}
+		evs[i] = Event{Envelope: env, Raw: raw}
+	}
+	return evs, nil
+}
+
+// ErrDone is returned by Source.Next once a finite source is exhausted.
+var ErrDone = errors.New("relay: no more events")
+
+// Source is implemented by everything that produces chaincode events, such
+// as a subscription to the event hub of a peer or a replay file.
+type Source interface {
+	// Next blocks until the next event is available.
+	Next(ctx context.Context) (Event, error)
+	// Close releases the source.
+	Close() error
+}
@@ -0,0 +1,232 @@
+package relay
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AkshayKulkarni03/hackathon/events"
+)
+
+// memoryDeadLetter collects the failures written to it.
+type memoryDeadLetter struct {
+	mu       sync.Mutex
+	failures []Failure
+}
+
+func (l *memoryDeadLetter) Write(f Failure) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failures = append(l.failures, f)
+	return nil
+}
+
+// sliceSource returns its events, then ErrDone.
+type sliceSource []Event
+
+func (s *sliceSource) Next(ctx context.Context) (Event, error) {
+	if len(*s) == 0 {
+		return Event{}, ErrDone
+	}
+	ev := (*s)[0]
+	*s = (*s)[1:]
+	return ev, nil
+}
+
+func (s *sliceSource) Close() error { return nil }
+
+func event(t *testing.T, name string, payload interface{}) Event {
+	t.Helper()
+	raw, err := events.Encode(name, "tx1", "2016-10-18 10:00:00", "100", payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev, err := ParseEvent(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ev
+}
+
+// delivery is a request received by a test webhook.
+type delivery struct {
+	event, id, signature string
+	body                 []byte
+}
+
+// receiver starts a webhook answering the requests it receives with the
+// statuses, in turn, then with 200.
+func receiver(t *testing.T, statuses ...int) (*httptest.Server, func() []delivery) {
+	var mu sync.Mutex
+	var got []delivery
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		n := len(got)
+		got = append(got, delivery{r.Header.Get(HeaderEvent), r.Header.Get(HeaderDelivery), r.Header.Get(HeaderSignature), body})
+		mu.Unlock()
+		if n < len(statuses) {
+			w.WriteHeader(statuses[n])
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []delivery {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]delivery(nil), got...)
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantAttempts int
+		wantDead     bool
+	}{
+		{"first attempt", nil, 1, false},
+		{"retried 5xx", []int{http.StatusServiceUnavailable, http.StatusBadGateway}, 3, false},
+		{"retried 429", []int{http.StatusTooManyRequests}, 2, false},
+		{"not retried 4xx", []int{http.StatusBadRequest}, 1, true},
+		{"attempts exhausted", []int{500, 500, 500, 500}, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, got := receiver(t, tt.statuses...)
+			dl := &memoryDeadLetter{}
+			d := &Dispatcher{
+				Hooks:       []Webhook{{URL: srv.URL, Secret: "s3cr3t"}},
+				MaxAttempts: 3,
+				Backoff:     time.Millisecond,
+				MaxBackoff:  2 * time.Millisecond,
+				DeadLetter:  dl,
+			}
+			ev := event(t, events.BidPlaced, events.BidEvent{ContractId: "1000", BidNo: "1", BidderID: "200", Price: "1200"})
+			d.Dispatch(context.Background(), ev)
+
+			deliveries := got()
+			if len(deliveries) != tt.wantAttempts {
+				t.Fatalf("attempts = %d, want %d", len(deliveries), tt.wantAttempts)
+			}
+			for _, dv := range deliveries {
+				if dv.event != events.BidPlaced || dv.id != "tx1/BidPlaced" || string(dv.body) != string(ev.Raw) {
+					t.Errorf("delivery = %+v", dv)
+				}
+				if !VerifySignature("s3cr3t", dv.body, dv.signature) {
+					t.Errorf("bad signature %s", dv.signature)
+				}
+			}
+
+			if tt.wantDead != (len(dl.failures) == 1) || len(dl.failures) > 1 {
+				t.Fatalf("dead letters = %+v, want dead %v", dl.failures, tt.wantDead)
+			}
+			if tt.wantDead {
+				f := dl.failures[0]
+				if f.Attempts != tt.wantAttempts || f.Name != events.BidPlaced || f.TxID != "tx1" || string(f.Payload) != string(ev.Raw) {
+					t.Errorf("dead letter = %+v", f)
+				}
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	srv, _ := receiver(t, 500, 500, 500, 500)
+	var mu sync.Mutex
+	var times []time.Time
+	d := &Dispatcher{
+		Hooks:       []Webhook{{URL: srv.URL}},
+		MaxAttempts: 4,
+		Backoff:     20 * time.Millisecond,
+		MaxBackoff:  30 * time.Millisecond,
+		Logf: func(string, ...interface{}) {
+			mu.Lock()
+			times = append(times, time.Now())
+			mu.Unlock()
+		},
+	}
+	d.Dispatch(context.Background(), event(t, events.UserRegistered, events.UserEvent{UserID: "100"}))
+
+	if len(times) != 4 {
+		t.Fatalf("attempts = %d, want 4", len(times))
+	}
+	// Waits of 20ms, then 40ms capped to 30ms, twice
+	for i, min := range []time.Duration{20, 30, 30} {
+		if wait := times[i+1].Sub(times[i]); wait < min*time.Millisecond {
+			t.Errorf("wait %d = %s, want at least %dms", i+1, wait, min)
+		}
+	}
+}
+
+func TestBackoffCancelled(t *testing.T) {
+	srv, got := receiver(t, 500, 500)
+	dl := &memoryDeadLetter{}
+	d := &Dispatcher{Hooks: []Webhook{{URL: srv.URL}}, MaxAttempts: 5, Backoff: time.Hour, DeadLetter: dl}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	d.Dispatch(ctx, event(t, events.UserRegistered, events.UserEvent{UserID: "100"}))
+
+	if n := len(got()); n != 1 {
+		t.Errorf("attempts = %d, want 1", n)
+	}
+	if len(dl.failures) != 1 || dl.failures[0].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("dead letters = %+v", dl.failures)
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	srv, got := receiver(t)
+	d := &Dispatcher{Hooks: []Webhook{{URL: srv.URL, Events: []string{events.BidPlaced}}}}
+
+	bid := func(seq int, bidNo string) events.Envelope {
+		ev := event(t, events.BidPlaced, events.BidEvent{ContractId: "1000", BidNo: bidNo})
+		ev.Seq = seq
+		return ev.Envelope
+	}
+	batch := event(t, events.BatchApplied, events.BatchEvent{Events: []events.Envelope{
+		bid(1, "1"),
+		{Version: events.Version, Name: events.UserRegistered, TxID: "tx1", Seq: 2, Payload: []byte(`{"UserID":"300"}`)},
+		bid(3, "2"),
+	}})
+	src := sliceSource{batch}
+	if err := d.Run(context.Background(), &src); err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries := got()
+	want := []string{"tx1/BidPlaced/1", "tx1/BidPlaced/3"}
+	if len(deliveries) != len(want) {
+		t.Fatalf("deliveries = %+v, want %v", deliveries, want)
+	}
+	for i, dv := range deliveries {
+		if dv.id != want[i] {
+			t.Errorf("delivery %d id = %s, want %s", i, dv.id, want[i])
+		}
+		ev, err := ParseEvent(dv.body)
+		if err != nil || ev.Seq != i*2+1 {
+			t.Errorf("delivery %d = %s, %v", i, dv.body, err)
+		}
+	}
+}
+
+func TestWants(t *testing.T) {
+	tests := []struct {
+		events []string
+		name   string
+		want   bool
+	}{
+		{nil, events.BidPlaced, true},
+		{[]string{events.BidPlaced, events.BidderSelected}, events.BidderSelected, true},
+		{[]string{events.BidPlaced}, events.ContractClosed, false},
+	}
+	for _, tt := range tests {
+		if got := (Webhook{Events: tt.events}).Wants(tt.name); got != tt.want {
+			t.Errorf("Webhook%v.Wants(%s) = %v, want %v", tt.events, tt.name, got, tt.want)
+		}
+	}
+}
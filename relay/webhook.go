@@ -0,0 +1,206 @@
+package relay
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Headers set on every delivery.
+const (
+	HeaderEvent     = "X-Contract-Event"     // event name
+	HeaderDelivery  = "X-Contract-Delivery"  // TxID/name[/seq], identical on every retry, see DeliveryID
+	HeaderSignature = "X-Contract-Signature" // sha256=<hex HMAC-SHA256 of the body>
+)
+
+// Webhook is a receiver of events.
+type Webhook struct {
+	URL    string
+	Secret string   // key of the HMAC signature
+	Events []string // names delivered to the webhook, all events if empty
+}
+
+// Wants reports whether the webhook is subscribed to the event name.
+func (h Webhook) Wants(name string) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, n := range h.Events {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the signature header value of body under secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature lets receivers check the signature header of a delivery.
+func VerifySignature(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// DeliveryID identifies the delivery of ev: its TxID and name, followed by
+// its Seq for the events of the operations of a batch, which share the TxID.
+func DeliveryID(ev Event) string {
+	id := ev.TxID + "/" + ev.Name
+	if ev.Seq > 0 {
+		id += "/" + strconv.Itoa(ev.Seq)
+	}
+	return id
+}
+
+// Dispatcher delivers events to webhooks.
+type Dispatcher struct {
+	Hooks  []Webhook
+	Client *http.Client
+
+	// MaxAttempts is the number of deliveries tried before giving up.
+	MaxAttempts int
+	// Backoff is the wait before the first retry, doubled on every further
+	// retry up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// DeadLetter receives the deliveries given up. Optional.
+	DeadLetter DeadLetter
+	// Logf reports failed attempts. Optional.
+	Logf func(format string, args ...interface{})
+}
+
+// Run delivers the events of src until it is exhausted or ctx is done.
+// Events are dispatched one after the other so every webhook receives them
+// in ledger order. A BatchApplied event is followed by the events of its
+// operations, so webhooks need not know about batches.
+func (d *Dispatcher) Run(ctx context.Context, src Source) error {
+	for {
+		ev, err := src.Next(ctx)
+		if err == ErrDone {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		d.Dispatch(ctx, ev)
+
+		ops, err := ev.Batch()
+		if err != nil {
+			d.logf("relay: cannot read batch %s: %v", ev.TxID, err)
+		}
+		for _, op := range ops {
+			d.Dispatch(ctx, op)
+		}
+	}
+}
+
+// Dispatch delivers one event to every subscribed webhook in parallel and
+// returns once each delivery succeeded or was given up.
+func (d *Dispatcher) Dispatch(ctx context.Context, ev Event) {
+	var wg sync.WaitGroup
+	for _, h := range d.Hooks {
+		if !h.Wants(ev.Name) {
+			continue
+		}
+		wg.Add(1)
+		go func(h Webhook) {
+			defer wg.Done()
+			d.deliver(ctx, h, ev)
+		}(h)
+	}
+	wg.Wait()
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, h Webhook, ev Event) {
+	attempts := d.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	wait := d.Backoff
+
+	var err error
+	n := 0
+	for n < attempts {
+		n++
+		var retry bool
+		retry, err = d.post(ctx, h, ev)
+		if err == nil {
+			return
+		}
+		d.logf("relay: %s to %s attempt %d/%d: %s", DeliveryID(ev), h.URL, n, attempts, err)
+		if !retry || n == attempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			n = attempts
+		case <-time.After(wait):
+		}
+		if wait *= 2; d.MaxBackoff > 0 && wait > d.MaxBackoff {
+			wait = d.MaxBackoff
+		}
+	}
+
+	if d.DeadLetter == nil {
+		return
+	}
+	dl := Failure{Time: time.Now().UTC(), URL: h.URL, Name: ev.Name, TxID: ev.TxID, Seq: ev.Seq,
+		Attempts: n, Error: err.Error(), Payload: ev.Raw}
+	if werr := d.DeadLetter.Write(dl); werr != nil {
+		d.logf("relay: cannot write dead letter for %s: %s", DeliveryID(ev), werr)
+	}
+}
+
+// post makes one delivery. The bool tells whether a failure is worth
+// retrying: network errors, 408, 429 and 5xx are, other statuses are not.
+func (d *Dispatcher) post(ctx context.Context, h Webhook, ev Event) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(ev.Raw))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, ev.Name)
+	req.Header.Set(HeaderDelivery, DeliveryID(ev))
+	if h.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(h.Secret, ev.Raw))
+	}
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	err = fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+	return retry, err
+}
+
+func (d *Dispatcher) logf(format string, args ...interface{}) {
+	if d.Logf != nil {
+		d.Logf(format, args...)
+	}
+}
@@ -28,13 +28,23 @@ clear_all
 
 # copy chaincode in main folder
 mkdir -p $CC_GLOBAL
-yes | cp -rf $CC/chaincode.go $CC_GLOBAL/chaincode.go
+yes | cp -rf $CC/*.go $CC_GLOBAL/
 printf "Latest chaincode copied from local to global folder\n"
 cd $CC_GLOBAL
 printf "$(pwd)"
 govend -v
 cd $DIR
 
+# vendor the packages of this repository the chaincode imports
+CC_VENDOR="$CC_GLOBAL/vendor/github.com/AkshayKulkarni03/hackathon"
+for pkg in errcode events logging model; do
+    rm -rf $CC_VENDOR/$pkg
+    mkdir -p $CC_VENDOR/$pkg
+    cp -f $DIR/$pkg/*.go $CC_VENDOR/$pkg/
+    rm -f $CC_VENDOR/$pkg/*_test.go
+done
+printf "Repository packages vendored into the global folder\n"
+
 # run docker-compose
 docker-compose up -d 2>/dev/null
 printf "Starting docker containers...\n"